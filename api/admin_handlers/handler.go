@@ -0,0 +1,88 @@
+// Copyright 2021 stafiprotocol
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package admin_handlers
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stafihub/staking-election/config"
+	"github.com/stafihub/staking-election/dao/election"
+	"github.com/stafihub/staking-election/db"
+)
+
+const (
+	AuthTypeKey   = "adminAuthType"
+	AuthTypeToken = "token"
+	AuthTypeHmac  = "hmac"
+)
+
+// Updater triggers an immediate update of annual rate and selected validators
+type Updater interface {
+	// TriggerUpdate returns false if an update is already pending
+	TriggerUpdate() bool
+}
+
+type Handler struct {
+	db      *db.WrapDb
+	updater Updater
+	denoms  map[string]bool
+}
+
+func NewHandler(cfg *config.Config, db *db.WrapDb, updater Updater) *Handler {
+	denoms := make(map[string]bool)
+	for _, rTokenInfo := range cfg.RTokenInfo {
+		denoms[rTokenInfo.Denom] = true
+	}
+	return &Handler{
+		db:      db,
+		updater: updater,
+		denoms:  denoms,
+	}
+}
+
+const (
+	codeParamParseErr       = "80001"
+	codeSymbolErr           = "80002"
+	codeInternalErr         = "80006"
+	codePoolAddressErr      = "80007"
+	codeValidatorAddressErr = "80017"
+	codeListTypeErr         = "80021"
+)
+
+// audit records admin action, failure of saving is only logged
+func (h *Handler) audit(c *gin.Context, action string, params interface{}, actionErr error) {
+	paramsBts, err := json.Marshal(params)
+	if err != nil {
+		logrus.Warnf("marshal admin params err: %s", err)
+	}
+	audit := &dao_election.AdminAudit{
+		Action:   action,
+		Params:   string(paramsBts),
+		RemoteIp: c.ClientIP(),
+		AuthType: c.GetString(AuthTypeKey),
+		Success:  actionErr == nil,
+	}
+	if actionErr != nil {
+		audit.Message = actionErr.Error()
+		if len(audit.Message) > 512 {
+			audit.Message = audit.Message[:512]
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"action":   audit.Action,
+		"params":   audit.Params,
+		"remoteIp": audit.RemoteIp,
+		"authType": audit.AuthType,
+		"success":  audit.Success,
+		"message":  audit.Message,
+	}).Info("admin action")
+
+	err = dao_election.AddAdminAudit(h.db, audit)
+	if err != nil {
+		logrus.Errorf("dao_election.AddAdminAudit err: %s", err)
+	}
+}
@@ -0,0 +1,157 @@
+package admin_handlers
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stafihub/staking-election/dao/election"
+	"github.com/stafihub/staking-election/utils"
+	"gorm.io/gorm"
+)
+
+type RspPausedPools struct {
+	PoolList []PausedPool `json:"poolList"`
+}
+
+type PausedPool struct {
+	Denom       string `json:"denom"`
+	PoolAddress string `json:"poolAddress"`
+	Remark      string `json:"remark"`
+	PausedAt    int64  `json:"pausedAt"` // unix seconds
+}
+
+// @Summary get paused pools
+// @Description get pools whose election is paused
+// @Tags admin
+// @Produce json
+// @Success 200 {object} utils.Rsp{data=RspPausedPools}
+// @Router /admin/v1/pausedPools [get]
+func (h *Handler) HandleGetPausedPools(c *gin.Context) {
+	pools, err := dao_election.GetPausedPoolList(h.db)
+	if err != nil {
+		logrus.Errorf("dao_election.GetPausedPoolList err: %s", err)
+		utils.Err(c, codeInternalErr, err.Error())
+		return
+	}
+
+	rsp := RspPausedPools{
+		PoolList: make([]PausedPool, 0, len(pools)),
+	}
+	for _, pool := range pools {
+		rsp.PoolList = append(rsp.PoolList, PausedPool{
+			Denom:       pool.RTokenDenom,
+			PoolAddress: pool.PoolAddress,
+			Remark:      pool.Remark,
+			PausedAt:    int64(pool.UpdatedAt),
+		})
+	}
+
+	utils.Ok(c, "success", rsp)
+}
+
+type ReqPausePool struct {
+	Denom       string `json:"denom"`
+	PoolAddress string `json:"poolAddress"`
+	Remark      string `json:"remark"`
+}
+
+// @Summary pause pool
+// @Description pause election of pool, the election process skips it until it's resumed
+// @Tags admin
+// @Accept json
+// @Param param body ReqPausePool true "pool"
+// @Produce json
+// @Success 200 {object} utils.Rsp{}
+// @Router /admin/v1/pausePool [post]
+func (h *Handler) HandlePostPausePool(c *gin.Context) {
+	req := ReqPausePool{}
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		utils.Err(c, codeParamParseErr, err.Error())
+		return
+	}
+	if !h.denoms[req.Denom] {
+		utils.Err(c, codeSymbolErr, "denom not support")
+		return
+	}
+	if len(req.PoolAddress) == 0 || len(req.PoolAddress) > 80 {
+		utils.Err(c, codePoolAddressErr, "pool address err")
+		return
+	}
+	if len(req.Remark) > 256 {
+		utils.Err(c, codeParamParseErr, "remark too long")
+		return
+	}
+
+	err = h.pausePool(req)
+	h.audit(c, "pausePool", req, err)
+	if err != nil {
+		logrus.Errorf("pausePool err: %s", err)
+		utils.Err(c, codeInternalErr, err.Error())
+		return
+	}
+
+	utils.Ok(c, "success", struct{}{})
+}
+
+func (h *Handler) pausePool(req ReqPausePool) error {
+	pool, err := dao_election.GetPausedPool(h.db, req.Denom, req.PoolAddress)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return err
+	}
+	pool.RTokenDenom = req.Denom
+	pool.PoolAddress = req.PoolAddress
+	pool.Remark = req.Remark
+
+	return dao_election.UpOrInPausedPool(h.db, pool)
+}
+
+type ReqResumePool struct {
+	Denom       string `json:"denom"`
+	PoolAddress string `json:"poolAddress"`
+}
+
+// @Summary resume pool
+// @Description resume election of a paused pool
+// @Tags admin
+// @Accept json
+// @Param param body ReqResumePool true "pool"
+// @Produce json
+// @Success 200 {object} utils.Rsp{}
+// @Router /admin/v1/resumePool [post]
+func (h *Handler) HandlePostResumePool(c *gin.Context) {
+	req := ReqResumePool{}
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		utils.Err(c, codeParamParseErr, err.Error())
+		return
+	}
+	if !h.denoms[req.Denom] {
+		utils.Err(c, codeSymbolErr, "denom not support")
+		return
+	}
+
+	_, err = dao_election.GetPausedPool(h.db, req.Denom, req.PoolAddress)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			err = fmt.Errorf("pool %s is not paused", req.PoolAddress)
+			h.audit(c, "resumePool", req, err)
+			utils.Err(c, codePoolAddressErr, err.Error())
+			return
+		}
+		logrus.Errorf("dao_election.GetPausedPool err: %s", err)
+		utils.Err(c, codeInternalErr, err.Error())
+		return
+	}
+
+	err = dao_election.DeletePausedPool(h.db, req.Denom, req.PoolAddress)
+	h.audit(c, "resumePool", req, err)
+	if err != nil {
+		logrus.Errorf("dao_election.DeletePausedPool err: %s", err)
+		utils.Err(c, codeInternalErr, err.Error())
+		return
+	}
+
+	utils.Ok(c, "success", struct{}{})
+}
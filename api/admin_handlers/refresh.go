@@ -0,0 +1,23 @@
+package admin_handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stafihub/staking-election/utils"
+)
+
+type RspRefresh struct {
+	Triggered bool `json:"triggered"` // false if an update is already pending
+}
+
+// @Summary refresh
+// @Description trigger an immediate update of annual rate and selected validators
+// @Tags admin
+// @Produce json
+// @Success 200 {object} utils.Rsp{data=RspRefresh}
+// @Router /admin/v1/refresh [post]
+func (h *Handler) HandlePostRefresh(c *gin.Context) {
+	triggered := h.updater.TriggerUpdate()
+	h.audit(c, "refresh", RspRefresh{Triggered: triggered}, nil)
+
+	utils.Ok(c, "success", RspRefresh{Triggered: triggered})
+}
@@ -0,0 +1,179 @@
+package admin_handlers
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stafihub/staking-election/dao/election"
+	"github.com/stafihub/staking-election/utils"
+	"gorm.io/gorm"
+)
+
+type ReqValidatorList struct {
+	Denom string `form:"denom"`
+}
+
+type RspValidatorList struct {
+	EntryList []ValidatorListEntry `json:"entryList"`
+}
+
+type ValidatorListEntry struct {
+	Denom            string `json:"denom"`
+	ValidatorAddress string `json:"validatorAddress"`
+	ListType         string `json:"listType"`
+	Remark           string `json:"remark"`
+}
+
+// @Summary get validator list
+// @Description get allow/deny list of denom
+// @Tags admin
+// @Param denom query string true "rtoken denom"
+// @Produce json
+// @Success 200 {object} utils.Rsp{data=RspValidatorList}
+// @Router /admin/v1/validatorList [get]
+func (h *Handler) HandleGetValidatorList(c *gin.Context) {
+	req := ReqValidatorList{}
+	err := c.ShouldBindQuery(&req)
+	if err != nil {
+		utils.Err(c, codeParamParseErr, err.Error())
+		return
+	}
+	if !h.denoms[req.Denom] {
+		utils.Err(c, codeSymbolErr, "denom not support")
+		return
+	}
+
+	entries, err := dao_election.GetValidatorListEntriesByDenom(h.db, req.Denom)
+	if err != nil {
+		logrus.Errorf("dao_election.GetValidatorListEntriesByDenom err: %s", err)
+		utils.Err(c, codeInternalErr, err.Error())
+		return
+	}
+
+	rsp := RspValidatorList{
+		EntryList: make([]ValidatorListEntry, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		rsp.EntryList = append(rsp.EntryList, ValidatorListEntry{
+			Denom:            entry.RTokenDenom,
+			ValidatorAddress: entry.ValidatorAddress,
+			ListType:         entry.ListType,
+			Remark:           entry.Remark,
+		})
+	}
+
+	utils.Ok(c, "success", rsp)
+}
+
+type ReqAddValidatorListEntry struct {
+	Denom            string `json:"denom"`
+	ValidatorAddress string `json:"validatorAddress"`
+	ListType         string `json:"listType"` // allow/deny
+	Remark           string `json:"remark"`
+}
+
+// @Summary add validator list entry
+// @Description put a validator into allow or deny list of denom, it replaces the existing entry of the validator
+// @Tags admin
+// @Accept json
+// @Param param body ReqAddValidatorListEntry true "validator list entry"
+// @Produce json
+// @Success 200 {object} utils.Rsp{}
+// @Router /admin/v1/addValidatorListEntry [post]
+func (h *Handler) HandlePostAddValidatorListEntry(c *gin.Context) {
+	req := ReqAddValidatorListEntry{}
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		utils.Err(c, codeParamParseErr, err.Error())
+		return
+	}
+	if !h.denoms[req.Denom] {
+		utils.Err(c, codeSymbolErr, "denom not support")
+		return
+	}
+	if len(req.ValidatorAddress) == 0 || len(req.ValidatorAddress) > 80 {
+		utils.Err(c, codeValidatorAddressErr, "validator address err")
+		return
+	}
+	if req.ListType != dao_election.ListTypeAllow && req.ListType != dao_election.ListTypeDeny {
+		utils.Err(c, codeListTypeErr, "list type should be allow or deny")
+		return
+	}
+	if len(req.Remark) > 256 {
+		utils.Err(c, codeParamParseErr, "remark too long")
+		return
+	}
+
+	err = h.addValidatorListEntry(req)
+	h.audit(c, "addValidatorListEntry", req, err)
+	if err != nil {
+		logrus.Errorf("addValidatorListEntry err: %s", err)
+		utils.Err(c, codeInternalErr, err.Error())
+		return
+	}
+
+	utils.Ok(c, "success", struct{}{})
+}
+
+func (h *Handler) addValidatorListEntry(req ReqAddValidatorListEntry) error {
+	entry, err := dao_election.GetValidatorListEntry(h.db, req.Denom, req.ValidatorAddress)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return err
+	}
+	entry.RTokenDenom = req.Denom
+	entry.ValidatorAddress = req.ValidatorAddress
+	entry.ListType = req.ListType
+	entry.Remark = req.Remark
+
+	return dao_election.UpOrInValidatorListEntry(h.db, entry)
+}
+
+type ReqRemoveValidatorListEntry struct {
+	Denom            string `json:"denom"`
+	ValidatorAddress string `json:"validatorAddress"`
+}
+
+// @Summary remove validator list entry
+// @Description remove a validator from allow/deny list of denom
+// @Tags admin
+// @Accept json
+// @Param param body ReqRemoveValidatorListEntry true "validator list entry"
+// @Produce json
+// @Success 200 {object} utils.Rsp{}
+// @Router /admin/v1/removeValidatorListEntry [post]
+func (h *Handler) HandlePostRemoveValidatorListEntry(c *gin.Context) {
+	req := ReqRemoveValidatorListEntry{}
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		utils.Err(c, codeParamParseErr, err.Error())
+		return
+	}
+	if !h.denoms[req.Denom] {
+		utils.Err(c, codeSymbolErr, "denom not support")
+		return
+	}
+
+	_, err = dao_election.GetValidatorListEntry(h.db, req.Denom, req.ValidatorAddress)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			err = fmt.Errorf("validator %s not in list", req.ValidatorAddress)
+			h.audit(c, "removeValidatorListEntry", req, err)
+			utils.Err(c, codeValidatorAddressErr, err.Error())
+			return
+		}
+		logrus.Errorf("dao_election.GetValidatorListEntry err: %s", err)
+		utils.Err(c, codeInternalErr, err.Error())
+		return
+	}
+
+	err = dao_election.DeleteValidatorListEntry(h.db, req.Denom, req.ValidatorAddress)
+	h.audit(c, "removeValidatorListEntry", req, err)
+	if err != nil {
+		logrus.Errorf("dao_election.DeleteValidatorListEntry err: %s", err)
+		utils.Err(c, codeInternalErr, err.Error())
+		return
+	}
+
+	utils.Ok(c, "success", struct{}{})
+}
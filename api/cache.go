@@ -0,0 +1,166 @@
+// Copyright 2021 stafiprotocol
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	CacheGroupAnnualRate         = "annualRate"
+	CacheGroupSelectedValidators = "selectedValidators"
+
+	maxCacheEntriesPerGroup = 1000
+	codeSuccess             = "80000"
+)
+
+type cacheEntry struct {
+	contentType string
+	body        []byte
+	etag        string
+	expireAt    time.Time
+}
+
+// ResponseCache caches successful GET responses of a group by request uri until
+// the group is invalidated or ttl passes, ttl should be the data refresh interval.
+type ResponseCache struct {
+	mutex       sync.RWMutex
+	ttl         time.Duration
+	entries     map[string]map[string]*cacheEntry // group -> request uri -> entry
+	generations map[string]uint64                 // group -> times invalidated
+}
+
+func NewResponseCache(ttl time.Duration) *ResponseCache {
+	return &ResponseCache{
+		ttl:         ttl,
+		entries:     make(map[string]map[string]*cacheEntry),
+		generations: make(map[string]uint64),
+	}
+}
+
+// Invalidate drops cached responses of group, it's called after the data of group is written
+func (rc *ResponseCache) Invalidate(group string) {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+	delete(rc.entries, group)
+	rc.generations[group]++
+}
+
+// get returns the cached entry of uri, or the generation of group to pass to set on miss
+func (rc *ResponseCache) get(group, uri string, now time.Time) (*cacheEntry, uint64, bool) {
+	rc.mutex.RLock()
+	defer rc.mutex.RUnlock()
+	entry, exist := rc.entries[group][uri]
+	if !exist || !now.Before(entry.expireAt) {
+		return nil, rc.generations[group], false
+	}
+	return entry, 0, true
+}
+
+// set caches entry unless group was invalidated since generation was got, as the
+// response may be built from data read before the invalidation
+func (rc *ResponseCache) set(group, uri string, generation uint64, entry *cacheEntry) {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+	if rc.generations[group] != generation {
+		return
+	}
+	groupEntries, exist := rc.entries[group]
+	// too many query combinations, start over
+	if !exist || len(groupEntries) >= maxCacheEntriesPerGroup {
+		groupEntries = make(map[string]*cacheEntry)
+		rc.entries[group] = groupEntries
+	}
+	groupEntries[uri] = entry
+}
+
+// bufferWriter holds the response so that it can be cached before written
+type bufferWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *bufferWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// Middleware serves responses of group from cache with ETag and Cache-Control,
+// If-None-Match requests are answered with 304 when the ETag still matches
+func (rc *ResponseCache) Middleware(group string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+		uri := c.Request.URL.RequestURI()
+		now := time.Now()
+
+		entry, generation, hit := rc.get(group, uri, now)
+		if !hit {
+			writer := &bufferWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+			c.Writer = writer
+			c.Next()
+			c.Writer = writer.ResponseWriter
+
+			if writer.status != http.StatusOK || !isSuccessBody(writer.body.Bytes()) {
+				c.Writer.WriteHeader(writer.status)
+				c.Writer.Write(writer.body.Bytes())
+				return
+			}
+			sum := sha256.Sum256(writer.body.Bytes())
+			entry = &cacheEntry{
+				contentType: c.Writer.Header().Get("Content-Type"),
+				body:        writer.body.Bytes(),
+				etag:        fmt.Sprintf("\"%s\"", hex.EncodeToString(sum[:16])),
+				expireAt:    now.Add(rc.ttl),
+			}
+			rc.set(group, uri, generation, entry)
+		}
+
+		c.Header("ETag", entry.etag)
+		c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int64(entry.expireAt.Sub(now).Seconds())))
+		if etagMatch(c.GetHeader("If-None-Match"), entry.etag) {
+			c.AbortWithStatus(http.StatusNotModified)
+			return
+		}
+		c.Data(http.StatusOK, entry.contentType, entry.body)
+		c.Abort()
+	}
+}
+
+func etagMatch(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func isSuccessBody(body []byte) bool {
+	rsp := struct {
+		Status string `json:"status"`
+	}{}
+	return json.Unmarshal(body, &rsp) == nil && rsp.Status == codeSuccess
+}
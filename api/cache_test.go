@@ -0,0 +1,162 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cacheRouter serves /data with the body returned by handle behind the response cache
+func cacheRouter(rc *ResponseCache, handle func(c *gin.Context)) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/data", rc.Middleware(CacheGroupAnnualRate), handle)
+	return router
+}
+
+func doGet(router http.Handler, uri string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, uri, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestResponseCacheEtag(t *testing.T) {
+	rc := NewResponseCache(time.Minute)
+	calls := 0
+	router := cacheRouter(rc, func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusOK, gin.H{"status": codeSuccess, "data": calls})
+	})
+
+	first := doGet(router, "/data?denom=uratom", nil)
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || len(etag) == 0 {
+		t.Fatalf("first response %d etag %q", first.Code, etag)
+	}
+	if cc := first.Header().Get("Cache-Control"); cc != "public, max-age=59" && cc != "public, max-age=60" {
+		t.Errorf("Cache-Control %q", cc)
+	}
+
+	tests := []struct {
+		name        string
+		uri         string
+		ifNoneMatch string
+		wantCode    int
+		wantCalls   int
+	}{
+		{"hit without etag", "/data?denom=uratom", "", http.StatusOK, 1},
+		{"hit with matching etag", "/data?denom=uratom", etag, http.StatusNotModified, 1},
+		{"weak and listed etag", "/data?denom=uratom", `"other", W/` + etag, http.StatusNotModified, 1},
+		{"wildcard etag", "/data?denom=uratom", "*", http.StatusNotModified, 1},
+		{"stale etag", "/data?denom=uratom", `"stale"`, http.StatusOK, 1},
+		{"other query is cached apart", "/data?denom=uriris", "", http.StatusOK, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := map[string]string{}
+			if len(tt.ifNoneMatch) != 0 {
+				header["If-None-Match"] = tt.ifNoneMatch
+			}
+			w := doGet(router, tt.uri, header)
+			if w.Code != tt.wantCode {
+				t.Errorf("code %d, want %d", w.Code, tt.wantCode)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("handler called %d times, want %d", calls, tt.wantCalls)
+			}
+			if tt.wantCode == http.StatusNotModified && w.Body.Len() != 0 {
+				t.Errorf("304 with body %q", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestResponseCacheInvalidateAndTtl(t *testing.T) {
+	rc := NewResponseCache(100 * time.Millisecond)
+	calls := 0
+	router := cacheRouter(rc, func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusOK, gin.H{"status": codeSuccess, "data": calls})
+	})
+
+	doGet(router, "/data", nil)
+	doGet(router, "/data", nil)
+	if calls != 1 {
+		t.Fatalf("handler called %d times, want 1", calls)
+	}
+	rc.Invalidate(CacheGroupSelectedValidators)
+	doGet(router, "/data", nil)
+	if calls != 1 {
+		t.Fatalf("invalidating another group dropped cache, handler called %d times", calls)
+	}
+	rc.Invalidate(CacheGroupAnnualRate)
+	doGet(router, "/data", nil)
+	if calls != 2 {
+		t.Fatalf("handler called %d times after invalidate, want 2", calls)
+	}
+	time.Sleep(150 * time.Millisecond)
+	doGet(router, "/data", nil)
+	if calls != 3 {
+		t.Fatalf("handler called %d times after ttl, want 3", calls)
+	}
+}
+
+func TestResponseCacheSkipsFailedResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   gin.H
+	}{
+		{"http error", http.StatusInternalServerError, gin.H{"status": codeSuccess}},
+		{"error status in body", http.StatusOK, gin.H{"status": "80001"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := NewResponseCache(time.Minute)
+			calls := 0
+			router := cacheRouter(rc, func(c *gin.Context) {
+				calls++
+				c.JSON(tt.status, tt.body)
+			})
+			for i := 0; i < 2; i++ {
+				w := doGet(router, "/data", nil)
+				if w.Code != tt.status || len(w.Header().Get("ETag")) != 0 {
+					t.Fatalf("response %d etag %q", w.Code, w.Header().Get("ETag"))
+				}
+			}
+			if calls != 2 {
+				t.Errorf("handler called %d times, want 2", calls)
+			}
+		})
+	}
+}
+
+func TestResponseCacheInvalidateDuringRequest(t *testing.T) {
+	rc := NewResponseCache(time.Minute)
+	calls := 0
+	router := cacheRouter(rc, func(c *gin.Context) {
+		calls++
+		// data is written and the group invalidated after this request read the db
+		if calls == 1 {
+			rc.Invalidate(CacheGroupAnnualRate)
+		}
+		c.JSON(http.StatusOK, gin.H{"status": codeSuccess, "data": calls})
+	})
+
+	doGet(router, "/data", nil)
+	doGet(router, "/data", nil)
+	if calls != 2 {
+		t.Fatalf("response built before invalidate was cached, handler called %d times", calls)
+	}
+	doGet(router, "/data", nil)
+	if calls != 2 {
+		t.Fatalf("handler called %d times, want 2", calls)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
-	"github.com/stafihub/staking-election/dao/election"
 	"github.com/stafihub/staking-election/utils"
 )
 
@@ -15,6 +14,7 @@ type RspAnnualRateList struct {
 type AnnualRate struct {
 	RTokenDenom string  `json:"rTokenDenom"`
 	AnnualRate  float64 `json:"annualRate"`
+	PriceUsd    float64 `json:"priceUsd"` // 0 if price unavailable
 }
 
 // @Summary get rate info
@@ -24,30 +24,40 @@ type AnnualRate struct {
 // @Success 200 {object} utils.Rsp{data=RspAnnualRateList}
 // @Router /v1/annualRateList [get]
 func (h *Handler) HandleGetAverageAnnualRate(c *gin.Context) {
-
-	annualRateList, err := dao_election.GetAnnualRateList(h.db)
+	rsp, err := h.AnnualRateList()
 	if err != nil {
-		logrus.Error("dao_election.GetAnnualRateList err: %s", err)
 		utils.Err(c, codeInternalErr, err.Error())
 		return
 	}
 
+	utils.Ok(c, "success", rsp)
+}
+
+// AnnualRateList is shared by rest and grpc api
+func (h *Handler) AnnualRateList() (*RspAnnualRateList, error) {
+	annualRateList, err := h.annualRateRepo.GetAnnualRateList()
+	if err != nil {
+		logrus.Errorf("annualRateRepo.GetAnnualRateList err: %s", err)
+		return nil, err
+	}
+
 	rsp := RspAnnualRateList{
 		AnnualRateList: make([]AnnualRate, len(annualRateList)),
 	}
 	for i, rate := range annualRateList {
 		dec, err := decimal.NewFromString(rate.AnnualRate)
 		if err != nil {
-			logrus.Error("dao_election.GetAnnualRateList err: %s", err)
-			utils.Err(c, codeInternalErr, err.Error())
-			return
+			logrus.Errorf("dao_election.GetAnnualRateList err: %s", err)
+			return nil, err
 		}
 
 		rsp.AnnualRateList[i] = AnnualRate{
 			RTokenDenom: rate.RTokenDenom,
 			AnnualRate:  dec.InexactFloat64(),
 		}
+		if tokenPrice, err := h.priceAggregator.GetPrice(rate.RTokenDenom); err == nil {
+			rsp.AnnualRateList[i].PriceUsd = tokenPrice.Usd
+		}
 	}
-
-	utils.Ok(c, "success", rsp)
+	return &rsp, nil
 }
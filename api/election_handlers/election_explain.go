@@ -0,0 +1,79 @@
+package election_handlers
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stafihub/staking-election/dao/election"
+	"github.com/stafihub/staking-election/utils"
+	"gorm.io/gorm"
+)
+
+type ReqElectionExplain struct {
+	Denom string `form:"denom"`
+	Pool  string `form:"pool"`
+	Cycle uint64 `form:"cycle"` // latest cycle if not set
+}
+
+type RspElectionExplain struct {
+	RTokenDenom  string `json:"rTokenDenom"`
+	PoolAddress  string `json:"poolAddress"`
+	CycleVersion uint64 `json:"cycleVersion"`
+	CycleNumber  uint64 `json:"cycleNumber"`
+	EvaluatedAt  int64  `json:"evaluatedAt"` // unix seconds
+	dao_election.ElectionDetail
+}
+
+// @Summary explain election
+// @Description explain how the election process decided on a pool's cycle
+// @Tags v1
+// @Param denom query string true "rtoken denom"
+// @Param pool query string true "pool address"
+// @Param cycle query int false "cycle number, latest if not set"
+// @Produce json
+// @Success 200 {object} utils.Rsp{data=RspElectionExplain}
+// @Router /v1/electionExplain [get]
+func (h *Handler) HandleGetElectionExplain(c *gin.Context) {
+	req := ReqElectionExplain{}
+	err := c.ShouldBindQuery(&req)
+	if err != nil {
+		utils.Err(c, codeParamParseErr, err.Error())
+		return
+	}
+	if len(req.Denom) == 0 {
+		utils.Err(c, codeSymbolErr, "denom empty")
+		return
+	}
+	if len(req.Pool) == 0 {
+		utils.Err(c, codePoolAddressErr, "pool empty")
+		return
+	}
+
+	record, err := dao_election.GetLatestElectionRecord(h.db, req.Denom, req.Pool, req.Cycle)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.Err(c, codeElectionRecordNotExistErr, "election record not exist")
+			return
+		}
+		logrus.Errorf("dao_election.GetLatestElectionRecord err: %s", err)
+		utils.Err(c, codeInternalErr, err.Error())
+		return
+	}
+
+	rsp := RspElectionExplain{
+		RTokenDenom:  record.RTokenDenom,
+		PoolAddress:  record.PoolAddress,
+		CycleVersion: record.CycleVersion,
+		CycleNumber:  record.CycleNumber,
+		EvaluatedAt:  int64(record.UpdatedAt),
+	}
+	err = json.Unmarshal([]byte(record.Detail), &rsp.ElectionDetail)
+	if err != nil {
+		logrus.Errorf("unmarshal election detail err: %s", err)
+		utils.Err(c, codeInternalErr, err.Error())
+		return
+	}
+
+	utils.Ok(c, "success", rsp)
+}
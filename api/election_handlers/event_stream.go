@@ -0,0 +1,164 @@
+package election_handlers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+	"github.com/stafihub/staking-election/utils"
+)
+
+// each write of event streams should be done within streamWriteWait, it replaces the
+// server write timeout so streams can outlive it
+const streamWriteWait = 10 * time.Second
+
+type connContextKey struct{}
+
+// ConnContext keeps the connection of a request in its context, it is set as
+// http.Server.ConnContext so event streams can move their write deadline
+func ConnContext(ctx context.Context, c net.Conn) context.Context {
+	return context.WithValue(ctx, connContextKey{}, c)
+}
+
+// extendWriteDeadline gives the next write of a http/1 stream streamWriteWait
+func extendWriteDeadline(r *http.Request) {
+	conn, ok := r.Context().Value(connContextKey{}).(net.Conn)
+	if ok && r.ProtoMajor == 1 {
+		conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+	}
+}
+
+type ReqEventStream struct {
+	Denom string `form:"denom"` // comma separated denoms, all denoms if empty
+}
+
+func (req ReqEventStream) denoms() []string {
+	denoms := make([]string, 0)
+	for _, denom := range strings.Split(req.Denom, ",") {
+		denom = strings.TrimSpace(denom)
+		if len(denom) != 0 {
+			denoms = append(denoms, denom)
+		}
+	}
+	return denoms
+}
+
+// @Summary event stream
+// @Description server-sent events of annual rate and selected validators changes
+// @Tags v1
+// @Param denom query string false "comma separated rtoken denoms, all if empty"
+// @Produce text/event-stream
+// @Success 200 {object} event.Event
+// @Router /v1/events [get]
+func (h *Handler) HandleGetEventStream(c *gin.Context) {
+	req := ReqEventStream{}
+	err := c.ShouldBindQuery(&req)
+	if err != nil {
+		utils.Err(c, codeParamParseErr, err.Error())
+		return
+	}
+	subscriber, err := h.eventBroker.Subscribe(req.denoms())
+	if err != nil {
+		utils.Err(c, codeSubscribeErr, err.Error())
+		return
+	}
+	defer h.eventBroker.Unsubscribe(subscriber)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	extendWriteDeadline(c.Request)
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-ticker.C:
+			extendWriteDeadline(c.Request)
+			_, err = c.Writer.WriteString(": heartbeat\n\n")
+		case e, ok := <-subscriber.Events():
+			if !ok {
+				// dropped for being too slow, client will reconnect
+				return
+			}
+			extendWriteDeadline(c.Request)
+			c.SSEvent(e.Type, e)
+		}
+		if err != nil {
+			logrus.Debugf("event stream write err: %s", err)
+			return
+		}
+		c.Writer.Flush()
+	}
+}
+
+// @Summary event websocket
+// @Description websocket of annual rate and selected validators changes, each message is a json event
+// @Tags v1
+// @Param denom query string false "comma separated rtoken denoms, all if empty"
+// @Success 101 {object} event.Event
+// @Router /v1/eventsWs [get]
+func (h *Handler) HandleGetEventWs(c *gin.Context) {
+	req := ReqEventStream{}
+	err := c.ShouldBindQuery(&req)
+	if err != nil {
+		utils.Err(c, codeParamParseErr, err.Error())
+		return
+	}
+	subscriber, err := h.eventBroker.Subscribe(req.denoms())
+	if err != nil {
+		utils.Err(c, codeSubscribeErr, err.Error())
+		return
+	}
+	defer h.eventBroker.Unsubscribe(subscriber)
+
+	// the hijacked connection keeps the server write deadline, each write below sets its own
+	conn, err := h.wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logrus.Debugf("websocket upgrade err: %s", err)
+		return
+	}
+	defer conn.Close()
+
+	// read to handle control frames, client messages are ignored
+	readDone := make(chan struct{})
+	go func() {
+		defer close(readDone)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-readDone:
+			return
+		case <-ticker.C:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteWait))
+		case e, ok := <-subscriber.Events():
+			if !ok {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+			err = conn.WriteJSON(e)
+		}
+		if err != nil {
+			logrus.Debugf("websocket write err: %s", err)
+			return
+		}
+	}
+}
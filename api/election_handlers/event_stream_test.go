@@ -0,0 +1,85 @@
+package election_handlers
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stafihub/staking-election/config"
+	"github.com/stafihub/staking-election/dao/election"
+	"github.com/stafihub/staking-election/event"
+)
+
+func TestHandleGetEventWsOrigin(t *testing.T) {
+	cfg := &config.Config{Api: config.Api{Cors: config.Cors{AllowOrigins: []string{"https://*.stafihub.io"}}}}
+	handler := NewHandler(cfg, nil, dao_election.Repositories{}, nil, nil, event.NewBroker(16, 4))
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/test", handler.HandleGetEventWs)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/test"
+
+	tests := []struct {
+		name       string
+		origin     string
+		wantStatus int
+	}{
+		{"allowed origin", "https://app.stafihub.io", http.StatusSwitchingProtocols},
+		{"origin not allowed", "https://evil.io", http.StatusForbidden},
+		{"no origin", "", http.StatusSwitchingProtocols},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if len(tt.origin) != 0 {
+				header.Set("Origin", tt.origin)
+			}
+			conn, rsp, err := websocket.DefaultDialer.Dial(url, header)
+			if conn != nil {
+				conn.Close()
+			}
+			if rsp == nil {
+				t.Fatalf("dial err: %s", err)
+			}
+			if rsp.StatusCode != tt.wantStatus {
+				t.Errorf("http status %d, want %d", rsp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHandleGetEventStreamOutlivesWriteTimeout(t *testing.T) {
+	handler := &Handler{eventBroker: event.NewBroker(16, 4), heartbeat: 20 * time.Millisecond}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/test", handler.HandleGetEventStream)
+	server := httptest.NewUnstartedServer(router)
+	server.Config.WriteTimeout = 100 * time.Millisecond
+	server.Config.ConnContext = ConnContext
+	server.Start()
+	t.Cleanup(server.Close)
+
+	rsp, err := http.Get(server.URL + "/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rsp.Body.Close()
+	start := time.Now()
+	reader := bufio.NewReader(rsp.Body)
+	for heartbeats := 0; time.Since(start) < 5*server.Config.WriteTimeout; {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream ended after %s and %d heartbeats: %s", time.Since(start), heartbeats, err)
+		}
+		if strings.HasPrefix(line, ": heartbeat") {
+			heartbeats++
+		}
+	}
+}
@@ -4,15 +4,73 @@
 package election_handlers
 
 import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	cosmosClient "github.com/stafihub/cosmos-relay-sdk/client"
+	"github.com/stafihub/staking-election/config"
+	"github.com/stafihub/staking-election/dao/election"
 	"github.com/stafihub/staking-election/db"
+	"github.com/stafihub/staking-election/event"
+	"github.com/stafihub/staking-election/price"
+)
+
+const (
+	defaultDecimals         = 6
+	defaultHeartbeatSeconds = 15
 )
 
 type Handler struct {
-	db *db.WrapDb
+	db                    *db.WrapDb
+	annualRateRepo        dao_election.AnnualRateRepository
+	selectedValidatorRepo dao_election.SelectedValidatorRepository
+	priceAggregator       *price.Aggregator
+	decimalsMap           map[string]int64 // denom -> decimals of native token
+	slashDuBlockMap       map[string]int64 // denom -> blocks in which slashes are counted
+	defaultLogoUrl        string
+	cosmosClientMap       map[string]*cosmosClient.Client
+	eventBroker           *event.Broker
+	heartbeat             time.Duration
+	wsUpgrader            websocket.Upgrader
 }
 
-func NewHandler(db *db.WrapDb) *Handler {
-	return &Handler{db: db}
+func NewHandler(cfg *config.Config, db *db.WrapDb, repos dao_election.Repositories, priceAggregator *price.Aggregator, cosmosClientMap map[string]*cosmosClient.Client,
+	eventBroker *event.Broker) *Handler {
+	decimalsMap := make(map[string]int64)
+	slashDuBlockMap := make(map[string]int64)
+	for _, rTokenInfo := range cfg.RTokenInfo {
+		slashDuBlockMap[rTokenInfo.Denom] = *rTokenInfo.SlashDuBlock
+		decimals := rTokenInfo.Decimals
+		if decimals == 0 {
+			decimals = defaultDecimals
+		}
+		decimalsMap[rTokenInfo.Denom] = decimals
+	}
+	heartbeatSeconds := cfg.Api.Event.HeartbeatSeconds
+	if heartbeatSeconds <= 0 {
+		heartbeatSeconds = defaultHeartbeatSeconds
+	}
+	cors := cfg.Api.Cors
+	return &Handler{
+		db:                    db,
+		annualRateRepo:        repos.AnnualRate,
+		selectedValidatorRepo: repos.SelectedValidator,
+		priceAggregator:       priceAggregator,
+		decimalsMap:           decimalsMap,
+		slashDuBlockMap:       slashDuBlockMap,
+		defaultLogoUrl:        cfg.Api.Logo.DefaultUrl,
+		cosmosClientMap:       cosmosClientMap,
+		eventBroker:           eventBroker,
+		heartbeat:             time.Duration(heartbeatSeconds) * time.Second,
+		wsUpgrader: websocket.Upgrader{
+			// browsers always send origin, clients without it are not browsers
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				return len(origin) == 0 || cors.OriginAllowed(origin)
+			},
+		},
+	}
 }
 
 const (
@@ -32,4 +90,29 @@ const (
 	codeMaxLimitErr           = "80014"
 	codeSwapInfoNotExistErr   = "80015"
 	codeLimitInfoNotExistErr  = "80016"
+	codeValidatorAddressErr   = "80017"
+	codeChainQueryErr         = "80018"
+
+	codeElectionRecordNotExistErr = "80019"
+	codeSubscribeErr              = "80022"
 )
+
+// getLogoUrlMap returns validator address -> logo url of denom
+func (h *Handler) getLogoUrlMap(denom string) (map[string]string, error) {
+	logoList, err := dao_election.GetValidatorLogoListByDenom(h.db, denom)
+	if err != nil {
+		return nil, err
+	}
+	logoUrlMap := make(map[string]string)
+	for _, l := range logoList {
+		logoUrlMap[l.ValidatorAddress] = l.LogoUrl
+	}
+	return logoUrlMap, nil
+}
+
+func (h *Handler) getLogoUrl(logoUrlMap map[string]string, validatorAddress string) string {
+	if logoUrl, exist := logoUrlMap[validatorAddress]; exist && len(logoUrl) != 0 {
+		return logoUrl
+	}
+	return h.defaultLogoUrl
+}
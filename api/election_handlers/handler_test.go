@@ -0,0 +1,317 @@
+package election_handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stafihub/staking-election/dao/election"
+	"github.com/stafihub/staking-election/dao/migrate"
+	"github.com/stafihub/staking-election/db"
+	"github.com/stafihub/staking-election/price"
+)
+
+const defaultLogoUrl = "https://example.com/default.png"
+
+type testRsp struct {
+	Status  string          `json:"status"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// newTestHandler returns a handler with memory repositories, an in-memory sqlite db for
+// the rest, and prices of the coingecko ids served by a test server
+func newTestHandler(t *testing.T, prices string) (*Handler, dao_election.Repositories) {
+	t.Helper()
+	wrapDb, err := db.NewDB(&db.Config{Driver: db.DriverSqlite, DBName: db.SqliteMemory})
+	if err != nil {
+		t.Fatalf("NewDB err: %s", err)
+	}
+	t.Cleanup(func() {
+		if sqlDb, err := wrapDb.DB.DB(); err == nil {
+			sqlDb.Close()
+		}
+	})
+	if err := migrate.Up(wrapDb); err != nil {
+		t.Fatalf("migrate err: %s", err)
+	}
+
+	priceServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(prices))
+	}))
+	t.Cleanup(priceServer.Close)
+	aggregator := price.NewAggregator([]price.PriceProvider{
+		price.NewCoinGeckoProvider(priceServer.URL+"/?ids=%s", map[string]string{"uratom": "cosmos", "uriris": "iris-network"}),
+	}, []string{"uratom", "uriris"}, time.Minute, time.Hour)
+	aggregator.Refresh()
+
+	repos := dao_election.NewMemoryRepositories()
+	return &Handler{
+		db:                    wrapDb,
+		annualRateRepo:        repos.AnnualRate,
+		selectedValidatorRepo: repos.SelectedValidator,
+		priceAggregator:       aggregator,
+		defaultLogoUrl:        defaultLogoUrl,
+	}, repos
+}
+
+func serve(t *testing.T, handle gin.HandlerFunc, uri string) testRsp {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/test", handle)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, uri, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("http status %d", w.Code)
+	}
+	rsp := testRsp{}
+	if err := json.Unmarshal(w.Body.Bytes(), &rsp); err != nil {
+		t.Fatalf("unmarshal %s err: %s", w.Body.String(), err)
+	}
+	return rsp
+}
+
+func TestHandleGetAverageAnnualRate(t *testing.T) {
+	tests := []struct {
+		name       string
+		rates      [][2]string // denom, rate in insertion order
+		prices     string
+		wantStatus string
+		want       []AnnualRate
+	}{
+		{
+			name:       "empty",
+			prices:     `{}`,
+			wantStatus: "80000",
+			want:       []AnnualRate{},
+		},
+		{
+			name:       "insertion order with prices",
+			rates:      [][2]string{{"uriris", "0.2"}, {"uratom", "0.105"}},
+			prices:     `{"cosmos":{"usd":10.5},"iris-network":{"usd":0.03}}`,
+			wantStatus: "80000",
+			want: []AnnualRate{
+				{RTokenDenom: "uriris", AnnualRate: 0.2, PriceUsd: 0.03},
+				{RTokenDenom: "uratom", AnnualRate: 0.105, PriceUsd: 10.5},
+			},
+		},
+		{
+			name:       "zero price when unavailable",
+			rates:      [][2]string{{"uratom", "0.1"}, {"urhuahua", "0.3"}},
+			prices:     `{"cosmos":{"usd":10.5}}`,
+			wantStatus: "80000",
+			want: []AnnualRate{
+				{RTokenDenom: "uratom", AnnualRate: 0.1, PriceUsd: 10.5},
+				{RTokenDenom: "urhuahua", AnnualRate: 0.3},
+			},
+		},
+		{
+			name:       "invalid stored rate",
+			rates:      [][2]string{{"uratom", "not a number"}},
+			prices:     `{}`,
+			wantStatus: codeInternalErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, repos := newTestHandler(t, tt.prices)
+			for _, rate := range tt.rates {
+				err := repos.AnnualRate.UpOrInAnnualRate(&dao_election.AnnualRate{RTokenDenom: rate[0], AnnualRate: rate[1]})
+				if err != nil {
+					t.Fatalf("UpOrInAnnualRate err: %s", err)
+				}
+			}
+
+			rsp := serve(t, h.HandleGetAverageAnnualRate, "/test")
+			if rsp.Status != tt.wantStatus {
+				t.Fatalf("status %s (%s), want %s", rsp.Status, rsp.Message, tt.wantStatus)
+			}
+			if tt.wantStatus != "80000" {
+				return
+			}
+			data := RspAnnualRateList{}
+			if err := json.Unmarshal(rsp.Data, &data); err != nil {
+				t.Fatalf("unmarshal data err: %s", err)
+			}
+			if len(data.AnnualRateList) != len(tt.want) {
+				t.Fatalf("rates %+v, want %+v", data.AnnualRateList, tt.want)
+			}
+			for i := range tt.want {
+				if data.AnnualRateList[i] != tt.want[i] {
+					t.Errorf("rate %d %+v, want %+v", i, data.AnnualRateList[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHandleGetSelectedValidators(t *testing.T) {
+	h, repos := newTestHandler(t, `{}`)
+	// inserted out of order, responses are ordered by denom, pool and validator
+	for _, v := range [][3]string{
+		{"uriris", "poolC", "val4"},
+		{"uratom", "poolB", "val3"},
+		{"uratom", "poolA", "val2"},
+		{"uratom", "poolA", "val1"},
+	} {
+		err := repos.SelectedValidator.UpOrInSelectedValidator(&dao_election.SelectedValidator{
+			RTokenDenom: v[0], PoolAddress: v[1], ValidatorAddress: v[2], Moniker: "moniker-" + v[2],
+		})
+		if err != nil {
+			t.Fatalf("UpOrInSelectedValidator err: %s", err)
+		}
+	}
+	logoUrl := "https://example.com/val1.png"
+	err := dao_election.UpOrInValidatorLogo(h.db, &dao_election.ValidatorLogo{RTokenDenom: "uratom", ValidatorAddress: "val1", LogoUrl: logoUrl})
+	if err != nil {
+		t.Fatalf("UpOrInValidatorLogo err: %s", err)
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		wantStatus    string
+		wantGroups    []string // denom/pool:validators
+		wantTotal     int64
+		wantPageIndex int
+		wantPageSize  int
+	}{
+		{
+			name:       "all grouped by denom without paging params",
+			query:      "",
+			wantStatus: "80000",
+			wantGroups: []string{"uratom/:val1,val2,val3", "uriris/:val4"},
+			wantTotal:  4,
+		},
+		{
+			name:       "denom filter without paging params",
+			query:      "denom=uratom",
+			wantStatus: "80000",
+			wantGroups: []string{"uratom/:val1,val2,val3"},
+			wantTotal:  3,
+		},
+		{
+			name:          "page index with default page size",
+			query:         "pageIndex=1",
+			wantStatus:    "80000",
+			wantGroups:    []string{"uratom/poolA:val1,val2", "uratom/poolB:val3", "uriris/poolC:val4"},
+			wantTotal:     4,
+			wantPageIndex: 1,
+			wantPageSize:  10,
+		},
+		{
+			name:          "denom and pool filter",
+			query:         "denom=uratom&pool=poolB&pageSize=10",
+			wantStatus:    "80000",
+			wantGroups:    []string{"uratom/poolB:val3"},
+			wantTotal:     1,
+			wantPageIndex: 1,
+			wantPageSize:  10,
+		},
+		{
+			name:          "pool filter only",
+			query:         "pool=poolC&pageIndex=1",
+			wantStatus:    "80000",
+			wantGroups:    []string{"uriris/poolC:val4"},
+			wantTotal:     1,
+			wantPageIndex: 1,
+			wantPageSize:  10,
+		},
+		{
+			name:          "second page splits a pool",
+			query:         "pageIndex=2&pageSize=1",
+			wantStatus:    "80000",
+			wantGroups:    []string{"uratom/poolA:val2"},
+			wantTotal:     4,
+			wantPageIndex: 2,
+			wantPageSize:  1,
+		},
+		{
+			name:          "last page",
+			query:         "pageIndex=2&pageSize=3",
+			wantStatus:    "80000",
+			wantGroups:    []string{"uriris/poolC:val4"},
+			wantTotal:     4,
+			wantPageIndex: 2,
+			wantPageSize:  3,
+		},
+		{
+			name:          "page out of range",
+			query:         "pageIndex=5&pageSize=3",
+			wantStatus:    "80000",
+			wantGroups:    []string{},
+			wantTotal:     4,
+			wantPageIndex: 5,
+			wantPageSize:  3,
+		},
+		{
+			name:          "page size is capped",
+			query:         "pageIndex=0&pageSize=1000",
+			wantStatus:    "80000",
+			wantGroups:    []string{"uratom/poolA:val1,val2", "uratom/poolB:val3", "uriris/poolC:val4"},
+			wantTotal:     4,
+			wantPageIndex: 1,
+			wantPageSize:  50,
+		},
+		{
+			name:       "unknown denom",
+			query:      "denom=urhuahua",
+			wantStatus: "80000",
+			wantGroups: []string{},
+			wantTotal:  0,
+		},
+		{
+			name:       "invalid page index",
+			query:      "pageIndex=x",
+			wantStatus: codeParamParseErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rsp := serve(t, h.HandleGetSelectedValidators, "/test?"+tt.query)
+			if rsp.Status != tt.wantStatus {
+				t.Fatalf("status %s (%s), want %s", rsp.Status, rsp.Message, tt.wantStatus)
+			}
+			if tt.wantStatus != "80000" {
+				return
+			}
+			data := RspSelectedValidators{}
+			if err := json.Unmarshal(rsp.Data, &data); err != nil {
+				t.Fatalf("unmarshal data err: %s", err)
+			}
+			if data.TotalCount != tt.wantTotal || data.PageIndex != tt.wantPageIndex || data.PageSize != tt.wantPageSize {
+				t.Errorf("total %d pageIndex %d pageSize %d, want %d %d %d",
+					data.TotalCount, data.PageIndex, data.PageSize, tt.wantTotal, tt.wantPageIndex, tt.wantPageSize)
+			}
+
+			groups := make([]string, 0)
+			for _, group := range data.SelectedValidators {
+				vals := make([]string, 0)
+				for _, val := range group.ValidatorList {
+					vals = append(vals, val.ValidatorAddress)
+					if val.Moniker != "moniker-"+val.ValidatorAddress {
+						t.Errorf("moniker of %s %s", val.ValidatorAddress, val.Moniker)
+					}
+					wantLogo := defaultLogoUrl
+					if group.RTokenDenom == "uratom" && val.ValidatorAddress == "val1" {
+						wantLogo = logoUrl
+					}
+					if val.LogoUrl != wantLogo {
+						t.Errorf("logo of %s %s, want %s", val.ValidatorAddress, val.LogoUrl, wantLogo)
+					}
+				}
+				groups = append(groups, group.RTokenDenom+"/"+group.PoolAddress+":"+strings.Join(vals, ","))
+			}
+			if strings.Join(groups, " ") != strings.Join(tt.wantGroups, " ") {
+				t.Errorf("groups %v, want %v", groups, tt.wantGroups)
+			}
+		})
+	}
+}
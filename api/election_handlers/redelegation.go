@@ -0,0 +1,89 @@
+package election_handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stafihub/staking-election/dao/election"
+	"github.com/stafihub/staking-election/utils"
+)
+
+type ReqRedelegationHistory struct {
+	Denom     string `form:"denom"`
+	Pool      string `form:"pool"`
+	PageIndex int    `form:"pageIndex"`
+	PageSize  int    `form:"pageSize"`
+}
+
+type RspRedelegationHistory struct {
+	RedelegationList []Redelegation `json:"redelegationList"`
+	TotalCount       int64          `json:"totalCount"`
+	PageIndex        int            `json:"pageIndex"`
+	PageSize         int            `json:"pageSize"`
+}
+
+type Redelegation struct {
+	RTokenDenom  string `json:"rTokenDenom"`
+	PoolAddress  string `json:"poolAddress"`
+	OldValidator string `json:"oldValidator"`
+	NewValidator string `json:"newValidator"`
+	CycleVersion uint64 `json:"cycleVersion"`
+	CycleNumber  uint64 `json:"cycleNumber"`
+	Height       int64  `json:"height"`
+	TxHash       string `json:"txHash"`
+}
+
+// @Summary get redelegation history
+// @Description get executed rvalidator updates of pools
+// @Tags v1
+// @Param denom query string false "rtoken denom"
+// @Param pool query string false "pool address"
+// @Param pageIndex query int false "page index, start from 1"
+// @Param pageSize query int false "page size"
+// @Produce json
+// @Success 200 {object} utils.Rsp{data=RspRedelegationHistory}
+// @Router /v1/redelegationHistory [get]
+func (h *Handler) HandleGetRedelegationHistory(c *gin.Context) {
+	req := ReqRedelegationHistory{}
+	err := c.ShouldBindQuery(&req)
+	if err != nil {
+		utils.Err(c, codeParamParseErr, err.Error())
+		return
+	}
+	if req.PageIndex <= 0 {
+		req.PageIndex = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = utils.DefaultPageSize
+	}
+	if req.PageSize > utils.MaxPageSize {
+		req.PageSize = utils.MaxPageSize
+	}
+
+	redelegations, totalCount, err := dao_election.GetRedelegationList(h.db, req.Denom, req.Pool, req.PageIndex, req.PageSize)
+	if err != nil {
+		logrus.Errorf("dao_election.GetRedelegationList err: %s", err)
+		utils.Err(c, codeInternalErr, err.Error())
+		return
+	}
+
+	rsp := RspRedelegationHistory{
+		RedelegationList: make([]Redelegation, 0, len(redelegations)),
+		TotalCount:       totalCount,
+		PageIndex:        req.PageIndex,
+		PageSize:         req.PageSize,
+	}
+	for _, r := range redelegations {
+		rsp.RedelegationList = append(rsp.RedelegationList, Redelegation{
+			RTokenDenom:  r.RTokenDenom,
+			PoolAddress:  r.PoolAddress,
+			OldValidator: r.OldValidator,
+			NewValidator: r.NewValidator,
+			CycleVersion: r.CycleVersion,
+			CycleNumber:  r.CycleNumber,
+			Height:       r.Height,
+			TxHash:       r.TxHash,
+		})
+	}
+
+	utils.Ok(c, "success", rsp)
+}
@@ -0,0 +1,93 @@
+package election_handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stafihub/staking-election/dao/election"
+	"github.com/stafihub/staking-election/utils"
+	"gorm.io/gorm"
+)
+
+type RspRTokenStatList struct {
+	RTokenStatList []RTokenStat `json:"rTokenStatList"`
+}
+
+type RTokenStat struct {
+	RTokenDenom    string  `json:"rTokenDenom"`
+	AnnualRate     float64 `json:"annualRate"`
+	TotalBonded    string  `json:"totalBonded"`    // native token amount with decimals
+	PriceUsd       float64 `json:"priceUsd"`       // 0 if price unavailable
+	PriceSource    string  `json:"priceSource"`    // empty if price unavailable
+	PriceUpdatedAt int64   `json:"priceUpdatedAt"` // unix seconds
+	TvlUsd         float64 `json:"tvlUsd"`
+	AnnualYieldUsd float64 `json:"annualYieldUsd"` // expected staking reward of tvl in one year
+}
+
+// @Summary get rtoken stat list
+// @Description get price, tvl and usd yield of rtokens
+// @Tags v1
+// @Produce json
+// @Success 200 {object} utils.Rsp{data=RspRTokenStatList}
+// @Router /v1/rTokenStatList [get]
+func (h *Handler) HandleGetRTokenStatList(c *gin.Context) {
+	rTokenStatList, err := dao_election.GetRTokenStatList(h.db)
+	if err != nil {
+		logrus.Errorf("dao_election.GetRTokenStatList err: %s", err)
+		utils.Err(c, codeInternalErr, err.Error())
+		return
+	}
+
+	rsp := RspRTokenStatList{
+		RTokenStatList: make([]RTokenStat, 0, len(rTokenStatList)),
+	}
+	for _, stat := range rTokenStatList {
+		totalBonded, err := decimal.NewFromString(stat.TotalBonded)
+		if err != nil {
+			logrus.Errorf("decimal.NewFromString err: %s", err)
+			utils.Err(c, codeInternalErr, err.Error())
+			return
+		}
+		decimals, exist := h.decimalsMap[stat.RTokenDenom]
+		if !exist {
+			decimals = defaultDecimals
+		}
+		totalBonded = totalBonded.Shift(-int32(decimals))
+
+		annualRate := decimal.Zero
+		rate, err := h.annualRateRepo.GetAnnualRate(stat.RTokenDenom)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			logrus.Errorf("annualRateRepo.GetAnnualRate err: %s", err)
+			utils.Err(c, codeInternalErr, err.Error())
+			return
+		}
+		if err == nil {
+			annualRate, err = decimal.NewFromString(rate.AnnualRate)
+			if err != nil {
+				logrus.Errorf("decimal.NewFromString err: %s", err)
+				utils.Err(c, codeInternalErr, err.Error())
+				return
+			}
+		}
+
+		rTokenStat := RTokenStat{
+			RTokenDenom: stat.RTokenDenom,
+			AnnualRate:  annualRate.InexactFloat64(),
+			TotalBonded: totalBonded.String(),
+		}
+		tokenPrice, err := h.priceAggregator.GetPrice(stat.RTokenDenom)
+		if err != nil {
+			logrus.Debugf("priceAggregator.GetPrice err: %s", err)
+		} else {
+			tvlUsd := totalBonded.Mul(decimal.NewFromFloat(tokenPrice.Usd))
+			rTokenStat.PriceUsd = tokenPrice.Usd
+			rTokenStat.PriceSource = tokenPrice.Source
+			rTokenStat.PriceUpdatedAt = tokenPrice.UpdatedAt.Unix()
+			rTokenStat.TvlUsd = tvlUsd.InexactFloat64()
+			rTokenStat.AnnualYieldUsd = tvlUsd.Mul(annualRate).InexactFloat64()
+		}
+		rsp.RTokenStatList = append(rsp.RTokenStatList, rTokenStat)
+	}
+
+	utils.Ok(c, "success", rsp)
+}
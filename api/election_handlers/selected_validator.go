@@ -3,16 +3,26 @@ package election_handlers
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
-	"github.com/stafihub/staking-election/dao/election"
 	"github.com/stafihub/staking-election/utils"
 )
 
+type ReqSelectedValidators struct {
+	Denom     string `form:"denom"`
+	Pool      string `form:"pool"`
+	PageIndex int    `form:"pageIndex"`
+	PageSize  int    `form:"pageSize"`
+}
+
 type RspSelectedValidators struct {
 	SelectedValidators []SelectedValidator `json:"selectedValidators"`
+	TotalCount         int64               `json:"totalCount"`
+	PageIndex          int                 `json:"pageIndex"`
+	PageSize           int                 `json:"pageSize"`
 }
 
 type SelectedValidator struct {
 	RTokenDenom   string            `json:"rTokenDenom"`
+	PoolAddress   string            `json:"poolAddress,omitempty"` // empty if not paged
 	ValidatorList []ValidatorDetail `json:"validatorList"`
 }
 type ValidatorDetail struct {
@@ -24,42 +34,92 @@ type ValidatorDetail struct {
 // @Summary get selected validators
 // @Description get selected validators
 // @Tags v1
+// @Param denom query string false "rtoken denom"
+// @Param pool query string false "pool address"
+// @Param pageIndex query int false "page index, start from 1, all validators grouped by denom if neither pageIndex nor pageSize is set"
+// @Param pageSize query int false "page size"
 // @Produce json
 // @Success 200 {object} utils.Rsp{data=RspSelectedValidators}
 // @Router /v1/selectedValidators [get]
 func (h *Handler) HandleGetSelectedValidators(c *gin.Context) {
+	req := ReqSelectedValidators{}
+	err := c.ShouldBindQuery(&req)
+	if err != nil {
+		utils.Err(c, codeParamParseErr, err.Error())
+		return
+	}
 
-	selectedValidators, err := dao_election.GetAllSelectedValidators(h.db)
+	rsp, err := h.SelectedValidators(req)
 	if err != nil {
-		logrus.Error("dao_election.GetAllSelectedValidators err: %s", err)
 		utils.Err(c, codeInternalErr, err.Error())
 		return
 	}
 
-	selectedValMap := make(map[string][]*dao_election.SelectedValidator)
-	for _, val := range selectedValidators {
-		selectedValMap[val.RTokenDenom] = append(selectedValMap[val.RTokenDenom], val)
+	utils.Ok(c, "success", rsp)
+}
+
+// SelectedValidators is shared by rest and grpc api, without paging params all validators are
+// returned grouped by denom as before paging was added, paged ones are grouped by denom and pool
+func (h *Handler) SelectedValidators(req ReqSelectedValidators) (*RspSelectedValidators, error) {
+	paged := req.PageIndex != 0 || req.PageSize != 0
+	if paged {
+		if req.PageIndex <= 0 {
+			req.PageIndex = 1
+		}
+		if req.PageSize <= 0 {
+			req.PageSize = utils.DefaultPageSize
+		}
+		if req.PageSize > utils.MaxPageSize {
+			req.PageSize = utils.MaxPageSize
+		}
+	}
+
+	selectedValidators, totalCount, err := h.selectedValidatorRepo.GetSelectedValidatorList(req.Denom, req.Pool, req.PageIndex, req.PageSize)
+	if err != nil {
+		logrus.Errorf("selectedValidatorRepo.GetSelectedValidatorList err: %s", err)
+		return nil, err
 	}
 
 	rsp := RspSelectedValidators{
 		SelectedValidators: make([]SelectedValidator, 0),
+		TotalCount:         totalCount,
+		PageIndex:          req.PageIndex,
+		PageSize:           req.PageSize,
 	}
 
-	for key, vals := range selectedValMap {
+	logoUrlMaps := make(map[string]map[string]string) // denom -> logo url map
+	// validators are ordered by denom and pool, so the same group is contiguous
+	for _, val := range selectedValidators {
+		poolAddress := ""
+		if paged {
+			poolAddress = val.PoolAddress
+		}
+		logoUrlMap, exist := logoUrlMaps[val.RTokenDenom]
+		if !exist {
+			logoUrlMap, err = h.getLogoUrlMap(val.RTokenDenom)
+			if err != nil {
+				logrus.Errorf("getLogoUrlMap err: %s", err)
+				return nil, err
+			}
+			logoUrlMaps[val.RTokenDenom] = logoUrlMap
+		}
 
-		valList := make([]ValidatorDetail, 0)
-		for _, val := range vals {
-			valList = append(valList, ValidatorDetail{
-				ValidatorAddress: val.ValidatorAddress,
-				Moniker:          val.Moniker,
-				LogoUrl:          "https://raw.githubusercontent.com/cosmostation/cosmostation_token_resource/master/moniker/cosmoshub/" + val.ValidatorAddress + ".png",
+		last := len(rsp.SelectedValidators) - 1
+		if last < 0 || rsp.SelectedValidators[last].RTokenDenom != val.RTokenDenom ||
+			rsp.SelectedValidators[last].PoolAddress != poolAddress {
+			rsp.SelectedValidators = append(rsp.SelectedValidators, SelectedValidator{
+				RTokenDenom:   val.RTokenDenom,
+				PoolAddress:   poolAddress,
+				ValidatorList: make([]ValidatorDetail, 0),
 			})
+			last++
 		}
-		rsp.SelectedValidators = append(rsp.SelectedValidators, SelectedValidator{
-			RTokenDenom:   key,
-			ValidatorList: valList,
+
+		rsp.SelectedValidators[last].ValidatorList = append(rsp.SelectedValidators[last].ValidatorList, ValidatorDetail{
+			ValidatorAddress: val.ValidatorAddress,
+			Moniker:          val.Moniker,
+			LogoUrl:          h.getLogoUrl(logoUrlMap, val.ValidatorAddress),
 		})
 	}
-
-	utils.Ok(c, "success", rsp)
+	return &rsp, nil
 }
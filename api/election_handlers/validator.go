@@ -0,0 +1,211 @@
+package election_handlers
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stafihub/staking-election/dao/election"
+	"github.com/stafihub/staking-election/utils"
+)
+
+const (
+	sortByAnnualRate  = "annualRate"
+	sortByCommission  = "commission"
+	sortByTokenAmount = "tokenAmount"
+	sortByMoniker     = "moniker"
+
+	orderAsc  = "asc"
+	orderDesc = "desc"
+)
+
+type ReqValidators struct {
+	Denom         string `form:"denom"`
+	SortBy        string `form:"sortBy"`
+	Order         string `form:"order"`
+	MinAnnualRate string `form:"minAnnualRate"`
+	MaxCommission string `form:"maxCommission"`
+	Keyword       string `form:"keyword"`
+}
+
+type RspValidators struct {
+	RTokenDenom   string      `json:"rTokenDenom"`
+	Height        int64       `json:"height"`
+	ValidatorList []Validator `json:"validatorList"`
+}
+
+type Validator struct {
+	ValidatorAddress string  `json:"validatorAddress"`
+	Moniker          string  `json:"moniker"`
+	AnnualRate       float64 `json:"annualRate"`
+	Commission       float64 `json:"commission"`
+	TokenAmount      string  `json:"tokenAmount"`
+	LogoUrl          string  `json:"logoUrl"`
+}
+
+type validatorWithDec struct {
+	val         *dao_election.Validator
+	annualRate  decimal.Decimal
+	commission  decimal.Decimal
+	tokenAmount decimal.Decimal
+}
+
+// @Summary get validators
+// @Description get validators of denom with metrics
+// @Tags v1
+// @Param denom query string true "rtoken denom"
+// @Param sortBy query string false "annualRate|commission|tokenAmount|moniker"
+// @Param order query string false "asc|desc"
+// @Param minAnnualRate query string false "min annual rate"
+// @Param maxCommission query string false "max commission"
+// @Param keyword query string false "moniker or address keyword"
+// @Produce json
+// @Success 200 {object} utils.Rsp{data=RspValidators}
+// @Router /v1/validators [get]
+func (h *Handler) HandleGetValidators(c *gin.Context) {
+	req := ReqValidators{}
+	err := c.ShouldBindQuery(&req)
+	if err != nil {
+		utils.Err(c, codeParamParseErr, err.Error())
+		return
+	}
+	if len(req.Denom) == 0 {
+		utils.Err(c, codeSymbolErr, "denom empty")
+		return
+	}
+	if len(req.SortBy) == 0 {
+		req.SortBy = sortByAnnualRate
+	}
+	if len(req.Order) == 0 {
+		req.Order = orderDesc
+	}
+	if req.Order != orderAsc && req.Order != orderDesc {
+		utils.Err(c, codeParamParseErr, "order must be asc or desc")
+		return
+	}
+
+	var minAnnualRate, maxCommission *decimal.Decimal
+	if len(req.MinAnnualRate) != 0 {
+		dec, err := decimal.NewFromString(req.MinAnnualRate)
+		if err != nil {
+			utils.Err(c, codeParamParseErr, "minAnnualRate format err")
+			return
+		}
+		minAnnualRate = &dec
+	}
+	if len(req.MaxCommission) != 0 {
+		dec, err := decimal.NewFromString(req.MaxCommission)
+		if err != nil {
+			utils.Err(c, codeParamParseErr, "maxCommission format err")
+			return
+		}
+		maxCommission = &dec
+	}
+
+	validatorList, err := dao_election.GetValidatorListByDenom(h.db, req.Denom)
+	if err != nil {
+		logrus.Errorf("dao_election.GetValidatorListByDenom err: %s", err)
+		utils.Err(c, codeInternalErr, err.Error())
+		return
+	}
+
+	logoUrlMap, err := h.getLogoUrlMap(req.Denom)
+	if err != nil {
+		logrus.Errorf("getLogoUrlMap err: %s", err)
+		utils.Err(c, codeInternalErr, err.Error())
+		return
+	}
+
+	keyword := strings.ToLower(req.Keyword)
+	rsp := RspValidators{
+		RTokenDenom:   req.Denom,
+		ValidatorList: make([]Validator, 0),
+	}
+	vals := make([]validatorWithDec, 0, len(validatorList))
+	for _, val := range validatorList {
+		valDec, err := newValidatorWithDec(val)
+		if err != nil {
+			logrus.Errorf("newValidatorWithDec err: %s", err)
+			utils.Err(c, codeInternalErr, err.Error())
+			return
+		}
+		if val.Height > rsp.Height {
+			rsp.Height = val.Height
+		}
+
+		if minAnnualRate != nil && valDec.annualRate.LessThan(*minAnnualRate) {
+			continue
+		}
+		if maxCommission != nil && valDec.commission.GreaterThan(*maxCommission) {
+			continue
+		}
+		if len(keyword) != 0 &&
+			!strings.Contains(strings.ToLower(val.Moniker), keyword) &&
+			!strings.Contains(strings.ToLower(val.ValidatorAddress), keyword) {
+			continue
+		}
+		vals = append(vals, valDec)
+	}
+
+	var less func(i, j int) bool
+	switch req.SortBy {
+	case sortByAnnualRate:
+		less = func(i, j int) bool { return vals[i].annualRate.LessThan(vals[j].annualRate) }
+	case sortByCommission:
+		less = func(i, j int) bool { return vals[i].commission.LessThan(vals[j].commission) }
+	case sortByTokenAmount:
+		less = func(i, j int) bool { return vals[i].tokenAmount.LessThan(vals[j].tokenAmount) }
+	case sortByMoniker:
+		less = func(i, j int) bool {
+			return strings.ToLower(vals[i].val.Moniker) < strings.ToLower(vals[j].val.Moniker)
+		}
+	default:
+		utils.Err(c, codeParamParseErr, "sortBy not support")
+		return
+	}
+	// keep order deterministic when the sort keys are equal
+	sort.SliceStable(vals, func(i, j int) bool {
+		return vals[i].val.ValidatorAddress < vals[j].val.ValidatorAddress
+	})
+	if req.Order == orderDesc {
+		sort.SliceStable(vals, func(i, j int) bool { return less(j, i) })
+	} else {
+		sort.SliceStable(vals, less)
+	}
+
+	for _, val := range vals {
+		rsp.ValidatorList = append(rsp.ValidatorList, Validator{
+			ValidatorAddress: val.val.ValidatorAddress,
+			Moniker:          val.val.Moniker,
+			AnnualRate:       val.annualRate.InexactFloat64(),
+			Commission:       val.commission.InexactFloat64(),
+			TokenAmount:      val.val.TokenAmount,
+			LogoUrl:          h.getLogoUrl(logoUrlMap, val.val.ValidatorAddress),
+		})
+	}
+
+	utils.Ok(c, "success", rsp)
+}
+
+func newValidatorWithDec(val *dao_election.Validator) (validatorWithDec, error) {
+	annualRate, err := decimal.NewFromString(val.AnnualRate)
+	if err != nil {
+		return validatorWithDec{}, err
+	}
+	commission, err := decimal.NewFromString(val.Commission)
+	if err != nil {
+		return validatorWithDec{}, err
+	}
+	tokenAmount, err := decimal.NewFromString(val.TokenAmount)
+	if err != nil {
+		return validatorWithDec{}, err
+	}
+	return validatorWithDec{
+		val:         val,
+		annualRate:  annualRate,
+		commission:  commission,
+		tokenAmount: tokenAmount,
+	}, nil
+}
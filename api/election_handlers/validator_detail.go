@@ -0,0 +1,170 @@
+package election_handlers
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stafihub/rtoken-relay-core/common/core"
+	"github.com/stafihub/staking-election/dao/election"
+	"github.com/stafihub/staking-election/utils"
+	"gorm.io/gorm"
+)
+
+type ReqValidator struct {
+	Denom   string `form:"denom"`
+	Address string `form:"address"`
+}
+
+type RspValidator struct {
+	RTokenDenom         string   `json:"rTokenDenom"`
+	ValidatorAddress    string   `json:"validatorAddress"`
+	Moniker             string   `json:"moniker"`
+	Identity            string   `json:"identity"`
+	Website             string   `json:"website"`
+	LogoUrl             string   `json:"logoUrl"`
+	Status              string   `json:"status"`
+	Commission          float64  `json:"commission"`
+	AnnualRate          float64  `json:"annualRate"` // 0 if validator is not in the latest snapshot
+	TokenAmount         string   `json:"tokenAmount"`
+	Jailed              bool     `json:"jailed"`
+	Tombstoned          bool     `json:"tombstoned"`
+	MissedBlocksCounter int64    `json:"missedBlocksCounter"`
+	Height              int64    `json:"height"`
+	SlashFromHeight     int64    `json:"slashFromHeight"`
+	RecentSlashList     []Slash  `json:"recentSlashList"`
+	PoolList            []string `json:"poolList"` // our pools delegating to this validator
+}
+
+type Slash struct {
+	ValidatorPeriod uint64  `json:"validatorPeriod"`
+	Fraction        float64 `json:"fraction"`
+}
+
+// @Summary get validator detail
+// @Description get validator detail with live health from chain
+// @Tags v1
+// @Param denom query string true "rtoken denom"
+// @Param address query string true "validator address"
+// @Produce json
+// @Success 200 {object} utils.Rsp{data=RspValidator}
+// @Router /v1/validator [get]
+func (h *Handler) HandleGetValidator(c *gin.Context) {
+	req := ReqValidator{}
+	err := c.ShouldBindQuery(&req)
+	if err != nil {
+		utils.Err(c, codeParamParseErr, err.Error())
+		return
+	}
+	client, exist := h.cosmosClientMap[req.Denom]
+	if !exist {
+		utils.Err(c, codeSymbolErr, "denom not support")
+		return
+	}
+
+	done := core.UseSdkConfigContext(client.GetAccountPrefix())
+	valAddress, err := sdk.ValAddressFromBech32(req.Address)
+	done()
+	if err != nil {
+		utils.Err(c, codeValidatorAddressErr, err.Error())
+		return
+	}
+
+	height, err := client.GetCurrentBlockHeight()
+	if err != nil {
+		logrus.Errorf("GetCurrentBlockHeight err: %s", err)
+		utils.Err(c, codeChainQueryErr, err.Error())
+		return
+	}
+	validatorRes, err := client.QueryValidator(req.Address, height)
+	if err != nil {
+		logrus.Debugf("QueryValidator err: %s", err)
+		utils.Err(c, codeValidatorAddressErr, err.Error())
+		return
+	}
+	validator := validatorRes.Validator
+
+	consAddress, err := utils.GetValidatorConsAddress(client, validator)
+	if err != nil {
+		logrus.Errorf("GetValidatorConsAddress err: %s", err)
+		utils.Err(c, codeInternalErr, err.Error())
+		return
+	}
+	signInfo, err := client.QuerySigningInfo(consAddress, height)
+	if err != nil {
+		logrus.Errorf("QuerySigningInfo err: %s", err)
+		utils.Err(c, codeChainQueryErr, err.Error())
+		return
+	}
+
+	slashFromHeight := height - h.slashDuBlockMap[req.Denom]
+	if slashFromHeight < 1 {
+		slashFromHeight = 1
+	}
+	slashRes, err := client.QueryValidatorSlashes(valAddress, slashFromHeight, height)
+	if err != nil {
+		logrus.Errorf("QueryValidatorSlashes err: %s", err)
+		utils.Err(c, codeChainQueryErr, err.Error())
+		return
+	}
+
+	annualRate := decimal.Zero
+	snapshot, err := dao_election.GetValidator(h.db, req.Denom, req.Address)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		logrus.Errorf("dao_election.GetValidator err: %s", err)
+		utils.Err(c, codeInternalErr, err.Error())
+		return
+	}
+	if err == nil {
+		annualRate, err = decimal.NewFromString(snapshot.AnnualRate)
+		if err != nil {
+			logrus.Errorf("decimal.NewFromString err: %s", err)
+			utils.Err(c, codeInternalErr, err.Error())
+			return
+		}
+	}
+
+	selectedList, err := h.selectedValidatorRepo.GetSelectedValidatorListByValidator(req.Denom, req.Address)
+	if err != nil {
+		logrus.Errorf("selectedValidatorRepo.GetSelectedValidatorListByValidator err: %s", err)
+		utils.Err(c, codeInternalErr, err.Error())
+		return
+	}
+	logoUrlMap, err := h.getLogoUrlMap(req.Denom)
+	if err != nil {
+		logrus.Errorf("getLogoUrlMap err: %s", err)
+		utils.Err(c, codeInternalErr, err.Error())
+		return
+	}
+
+	rsp := RspValidator{
+		RTokenDenom:         req.Denom,
+		ValidatorAddress:    req.Address,
+		Moniker:             validator.GetMoniker(),
+		Identity:            validator.Description.Identity,
+		Website:             validator.Description.Website,
+		LogoUrl:             h.getLogoUrl(logoUrlMap, req.Address),
+		Status:              validator.Status.String(),
+		Commission:          validator.Commission.Rate.MustFloat64(),
+		AnnualRate:          annualRate.InexactFloat64(),
+		TokenAmount:         validator.Tokens.String(),
+		Jailed:              validator.Jailed,
+		Tombstoned:          signInfo.ValSigningInfo.Tombstoned,
+		MissedBlocksCounter: signInfo.ValSigningInfo.MissedBlocksCounter,
+		Height:              height,
+		SlashFromHeight:     slashFromHeight,
+		RecentSlashList:     make([]Slash, 0),
+		PoolList:            make([]string, 0),
+	}
+	for _, slash := range slashRes.Slashes {
+		rsp.RecentSlashList = append(rsp.RecentSlashList, Slash{
+			ValidatorPeriod: slash.ValidatorPeriod,
+			Fraction:        slash.Fraction.MustFloat64(),
+		})
+	}
+	for _, selected := range selectedList {
+		rsp.PoolList = append(rsp.PoolList, selected.PoolAddress)
+	}
+
+	utils.Ok(c, "success", rsp)
+}
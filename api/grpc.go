@@ -0,0 +1,25 @@
+// Copyright 2021 stafiprotocol
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package api
+
+import (
+	cosmosClient "github.com/stafihub/cosmos-relay-sdk/client"
+	"github.com/stafihub/staking-election/api/election_handlers"
+	"github.com/stafihub/staking-election/api/grpc_handlers"
+	"github.com/stafihub/staking-election/api/grpc_types"
+	"github.com/stafihub/staking-election/config"
+	"github.com/stafihub/staking-election/dao/election"
+	"github.com/stafihub/staking-election/db"
+	"github.com/stafihub/staking-election/price"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+func InitGrpcServer(cfg *config.Config, db *db.WrapDb, repos dao_election.Repositories, priceAggregator *price.Aggregator, cosmosClientMap map[string]*cosmosClient.Client) *grpc.Server {
+	grpcServer := grpc.NewServer()
+	restHandler := election_handlers.NewHandler(cfg, db, repos, priceAggregator, cosmosClientMap, nil)
+	grpc_types.RegisterQueryServer(grpcServer, grpc_handlers.NewHandler(restHandler))
+	reflection.Register(grpcServer)
+	return grpcServer
+}
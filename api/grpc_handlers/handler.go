@@ -0,0 +1,79 @@
+// Copyright 2021 stafiprotocol
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package grpc_handlers
+
+import (
+	"context"
+
+	"github.com/stafihub/staking-election/api/election_handlers"
+	"github.com/stafihub/staking-election/api/grpc_types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Handler serves the grpc api with the same data as the rest api
+type Handler struct {
+	grpc_types.UnimplementedQueryServer
+	restHandler *election_handlers.Handler
+}
+
+func NewHandler(restHandler *election_handlers.Handler) *Handler {
+	return &Handler{
+		restHandler: restHandler,
+	}
+}
+
+func (h *Handler) AnnualRateList(ctx context.Context, req *grpc_types.QueryAnnualRateListRequest) (*grpc_types.QueryAnnualRateListResponse, error) {
+	rsp, err := h.restHandler.AnnualRateList()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	grpcRsp := &grpc_types.QueryAnnualRateListResponse{
+		AnnualRateList: make([]*grpc_types.AnnualRate, 0, len(rsp.AnnualRateList)),
+	}
+	for _, rate := range rsp.AnnualRateList {
+		grpcRsp.AnnualRateList = append(grpcRsp.AnnualRateList, &grpc_types.AnnualRate{
+			RTokenDenom: rate.RTokenDenom,
+			AnnualRate:  rate.AnnualRate,
+			PriceUsd:    rate.PriceUsd,
+		})
+	}
+	return grpcRsp, nil
+}
+
+func (h *Handler) SelectedValidators(ctx context.Context, req *grpc_types.QuerySelectedValidatorsRequest) (*grpc_types.QuerySelectedValidatorsResponse, error) {
+	rsp, err := h.restHandler.SelectedValidators(election_handlers.ReqSelectedValidators{
+		Denom:     req.Denom,
+		Pool:      req.Pool,
+		PageIndex: int(req.PageIndex),
+		PageSize:  int(req.PageSize),
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	grpcRsp := &grpc_types.QuerySelectedValidatorsResponse{
+		SelectedValidators: make([]*grpc_types.SelectedValidator, 0, len(rsp.SelectedValidators)),
+		TotalCount:         int32(rsp.TotalCount),
+		PageIndex:          int32(rsp.PageIndex),
+		PageSize:           int32(rsp.PageSize),
+	}
+	for _, selected := range rsp.SelectedValidators {
+		validatorList := make([]*grpc_types.ValidatorDetail, 0, len(selected.ValidatorList))
+		for _, val := range selected.ValidatorList {
+			validatorList = append(validatorList, &grpc_types.ValidatorDetail{
+				ValidatorAddress: val.ValidatorAddress,
+				Moniker:          val.Moniker,
+				LogoUrl:          val.LogoUrl,
+			})
+		}
+		grpcRsp.SelectedValidators = append(grpcRsp.SelectedValidators, &grpc_types.SelectedValidator{
+			RTokenDenom:   selected.RTokenDenom,
+			PoolAddress:   selected.PoolAddress,
+			ValidatorList: validatorList,
+		})
+	}
+	return grpcRsp, nil
+}
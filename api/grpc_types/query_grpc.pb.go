@@ -0,0 +1,141 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: stakingelection/v1/query.proto
+
+package grpc_types
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// QueryClient is the client API for Query service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type QueryClient interface {
+	AnnualRateList(ctx context.Context, in *QueryAnnualRateListRequest, opts ...grpc.CallOption) (*QueryAnnualRateListResponse, error)
+	SelectedValidators(ctx context.Context, in *QuerySelectedValidatorsRequest, opts ...grpc.CallOption) (*QuerySelectedValidatorsResponse, error)
+}
+
+type queryClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewQueryClient(cc grpc.ClientConnInterface) QueryClient {
+	return &queryClient{cc}
+}
+
+func (c *queryClient) AnnualRateList(ctx context.Context, in *QueryAnnualRateListRequest, opts ...grpc.CallOption) (*QueryAnnualRateListResponse, error) {
+	out := new(QueryAnnualRateListResponse)
+	err := c.cc.Invoke(ctx, "/stakingelection.v1.Query/AnnualRateList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryClient) SelectedValidators(ctx context.Context, in *QuerySelectedValidatorsRequest, opts ...grpc.CallOption) (*QuerySelectedValidatorsResponse, error) {
+	out := new(QuerySelectedValidatorsResponse)
+	err := c.cc.Invoke(ctx, "/stakingelection.v1.Query/SelectedValidators", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// QueryServer is the server API for Query service.
+// All implementations must embed UnimplementedQueryServer
+// for forward compatibility
+type QueryServer interface {
+	AnnualRateList(context.Context, *QueryAnnualRateListRequest) (*QueryAnnualRateListResponse, error)
+	SelectedValidators(context.Context, *QuerySelectedValidatorsRequest) (*QuerySelectedValidatorsResponse, error)
+	mustEmbedUnimplementedQueryServer()
+}
+
+// UnimplementedQueryServer must be embedded to have forward compatible implementations.
+type UnimplementedQueryServer struct {
+}
+
+func (UnimplementedQueryServer) AnnualRateList(context.Context, *QueryAnnualRateListRequest) (*QueryAnnualRateListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnnualRateList not implemented")
+}
+func (UnimplementedQueryServer) SelectedValidators(context.Context, *QuerySelectedValidatorsRequest) (*QuerySelectedValidatorsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SelectedValidators not implemented")
+}
+func (UnimplementedQueryServer) mustEmbedUnimplementedQueryServer() {}
+
+// UnsafeQueryServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to QueryServer will
+// result in compilation errors.
+type UnsafeQueryServer interface {
+	mustEmbedUnimplementedQueryServer()
+}
+
+func RegisterQueryServer(s grpc.ServiceRegistrar, srv QueryServer) {
+	s.RegisterService(&Query_ServiceDesc, srv)
+}
+
+func _Query_AnnualRateList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryAnnualRateListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServer).AnnualRateList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/stakingelection.v1.Query/AnnualRateList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServer).AnnualRateList(ctx, req.(*QueryAnnualRateListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Query_SelectedValidators_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QuerySelectedValidatorsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServer).SelectedValidators(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/stakingelection.v1.Query/SelectedValidators",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServer).SelectedValidators(ctx, req.(*QuerySelectedValidatorsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Query_ServiceDesc is the grpc.ServiceDesc for Query service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Query_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "stakingelection.v1.Query",
+	HandlerType: (*QueryServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AnnualRateList",
+			Handler:    _Query_AnnualRateList_Handler,
+		},
+		{
+			MethodName: "SelectedValidators",
+			Handler:    _Query_SelectedValidators_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "stakingelection/v1/query.proto",
+}
@@ -4,70 +4,337 @@
 package api
 
 import (
-	"github.com/gin-gonic/gin"
-	"golang.org/x/time/rate"
+	"bytes"
+	"container/list"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io"
 	"net/http"
+	"path"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stafihub/staking-election/api/admin_handlers"
+	"github.com/stafihub/staking-election/config"
+	"golang.org/x/time/rate"
 )
 
-func Cors() gin.HandlerFunc {
+const (
+	defaultVisitorTtl  = 10 * time.Minute
+	defaultMaxVisitors = 10000
+)
+
+var (
+	defaultCorsMethods       = []string{"POST", "GET", "OPTIONS"}
+	defaultCorsHeaders       = []string{"Content-Type", "AccessToken", "X-CSRF-Token", "Authorization", "Token"}
+	defaultCorsExposeHeaders = []string{"Content-Length", "Access-Control-Allow-Origin", "Access-Control-Allow-Headers", "Content-Type", "ETag"}
+)
+
+// Cors allows origins matching cfg.AllowOrigins, which may contain wildcard patterns
+// such as https://*.stafihub.io, all origins are allowed if it's empty or has "*".
+func Cors(cfg config.Cors) (gin.HandlerFunc, error) {
+	for _, origin := range cfg.AllowOrigins {
+		if _, err := path.Match(strings.ToLower(strings.TrimSuffix(origin, "/")), ""); err != nil {
+			return nil, fmt.Errorf("cors origin pattern %s err: %s", origin, err)
+		}
+	}
+	allowAll := cfg.AllowAllOrigins()
+	if allowAll && cfg.AllowCredentials {
+		return nil, fmt.Errorf("cors credentials can't be allowed for all origins")
+	}
+
+	allowMethods := cfg.AllowMethods
+	if len(allowMethods) == 0 {
+		allowMethods = defaultCorsMethods
+	}
+	allowHeaders := cfg.AllowHeaders
+	if len(allowHeaders) == 0 {
+		allowHeaders = defaultCorsHeaders
+	}
+	exposeHeaders := cfg.ExposeHeaders
+	if len(exposeHeaders) == 0 {
+		exposeHeaders = defaultCorsExposeHeaders
+	}
+	methods := strings.Join(allowMethods, ", ")
+	headers := strings.Join(allowHeaders, ", ")
+	expose := strings.Join(exposeHeaders, ", ")
+	maxAge := strconv.FormatInt(cfg.MaxAgeSeconds, 10)
+
 	return func(c *gin.Context) {
-		method := c.Request.Method
+		// response depends on origin, caches must not share it between origins
+		c.Writer.Header().Add("Vary", "Origin")
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions
+		if len(origin) == 0 {
+			if preflight {
+				c.AbortWithStatus(http.StatusNoContent)
+				return
+			}
+			c.Next()
+			return
+		}
+		if !cfg.OriginAllowed(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
 
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Headers", "Content-Type,AccessToken,X-CSRF-Token, Authorization, Token")
-		c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
-		c.Header("Access-Control-Expose-Headers", "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers, Content-Type")
-		c.Header("Access-Control-Allow-Credentials", "true")
+		if allowAll {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
 
-		if method == "OPTIONS" {
+		if preflight {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+			c.Header("Access-Control-Allow-Methods", methods)
+			c.Header("Access-Control-Allow-Headers", headers)
+			if cfg.MaxAgeSeconds > 0 {
+				c.Header("Access-Control-Max-Age", maxAge)
+			}
 			c.AbortWithStatus(http.StatusNoContent)
+			return
 		}
+		c.Header("Access-Control-Expose-Headers", expose)
 		c.Next()
-	}
+	}, nil
+}
+
+type visitor struct {
+	ip       string
+	limiter  *rate.Limiter
+	lastSeen time.Time
 }
 
-// Create a map to hold the rate limiters for each visitor and a mutex.
-var visitors = make(map[string]*rate.Limiter)
-var mu sync.Mutex
+// visitors holds the rate limiter of each ip, the least recently used ones are
+// evicted when there are more than maxSize or they are unused for ttl.
+type visitors struct {
+	mu         sync.Mutex
+	lru        *list.List // front is the most recently used
+	elements   map[string]*list.Element
+	maxSize    int
+	ttl        time.Duration
+	newLimiter func() *rate.Limiter
+}
+
+func newVisitors(maxSize int, ttl time.Duration, newLimiter func() *rate.Limiter) *visitors {
+	return &visitors{
+		lru:        list.New(),
+		elements:   make(map[string]*list.Element),
+		maxSize:    maxSize,
+		ttl:        ttl,
+		newLimiter: newLimiter,
+	}
+}
 
 // Retrieve and return the rate limiter for the current visitor if it
 // already exists. Otherwise create a new rate limiter and add it to
-// the visitors map, using the IP address as the key.
-func getVisitor(ip string) *rate.Limiter {
-	mu.Lock()
-	defer mu.Unlock()
+// the visitors, using the IP address as the key.
+func (v *visitors) getVisitor(ip string, now time.Time) *rate.Limiter {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.evict(now)
 
-	limiter, exists := visitors[ip]
-	if !exists {
-		limiter = rate.NewLimiter(rate.Every(time.Minute), 5)
-		visitors[ip] = limiter
+	if element, exist := v.elements[ip]; exist {
+		vis := element.Value.(*visitor)
+		vis.lastSeen = now
+		v.lru.MoveToFront(element)
+		return vis.limiter
 	}
 
-	return limiter
+	vis := &visitor{
+		ip:       ip,
+		limiter:  v.newLimiter(),
+		lastSeen: now,
+	}
+	v.elements[ip] = v.lru.PushFront(vis)
+	for v.lru.Len() > v.maxSize {
+		v.remove(v.lru.Back())
+	}
+	return vis.limiter
 }
 
-func IpRateLimiter() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		ip := c.ClientIP()
+// evict visitors unused for ttl, they are at the back of lru
+func (v *visitors) evict(now time.Time) {
+	for element := v.lru.Back(); element != nil; element = v.lru.Back() {
+		if now.Sub(element.Value.(*visitor).lastSeen) <= v.ttl {
+			return
+		}
+		v.remove(element)
+	}
+}
+
+func (v *visitors) remove(element *list.Element) {
+	v.lru.Remove(element)
+	delete(v.elements, element.Value.(*visitor).ip)
+}
 
+func newLimiter(ratePerMinute int64, burst int) *rate.Limiter {
+	if burst <= 0 {
+		burst = 1
+	}
+	return rate.NewLimiter(rate.Every(time.Minute/time.Duration(ratePerMinute)), burst)
+}
+
+func IpRateLimiter(cfg config.RateLimit) gin.HandlerFunc {
+	ttl := time.Duration(cfg.VisitorTtlSeconds) * time.Second
+	if ttl <= 0 {
+		ttl = defaultVisitorTtl
+	}
+	maxVisitors := cfg.MaxVisitors
+	if maxVisitors <= 0 {
+		maxVisitors = defaultMaxVisitors
+	}
+	v := newVisitors(maxVisitors, ttl, func() *rate.Limiter {
+		return newLimiter(cfg.IpRatePerMinute, cfg.IpBurst)
+	})
+
+	return func(c *gin.Context) {
 		// Call the getVisitor function to retreive the rate limiter for
 		// the current user.
-		limiter := getVisitor(ip)
+		limiter := v.getVisitor(c.ClientIP(), time.Now())
 		if !limiter.Allow() {
 			c.AbortWithStatus(http.StatusTooManyRequests)
+			return
 		}
 		c.Next()
 	}
 }
 
-func MethodRateLimiter() gin.HandlerFunc {
-	globalRate := rate.NewLimiter(rate.Every(time.Minute), 10)
+// RouteRateLimiter limits requests of all ips to each configured route
+func RouteRateLimiter(routeLimitList []config.RouteLimit) gin.HandlerFunc {
+	limiters := make(map[string]*rate.Limiter)
+	for _, routeLimit := range routeLimitList {
+		if routeLimit.RatePerMinute <= 0 {
+			continue
+		}
+		limiters[routeLimit.Path] = newLimiter(routeLimit.RatePerMinute, routeLimit.Burst)
+	}
 	return func(c *gin.Context) {
-		if !globalRate.Allow() {
+		if limiter, exist := limiters[c.FullPath()]; exist && !limiter.Allow() {
 			c.AbortWithStatus(http.StatusTooManyRequests)
+			return
 		}
 		c.Next()
 	}
 }
+
+const (
+	adminTimestampHeader     = "X-Admin-Timestamp"
+	adminSignatureHeader     = "X-Admin-Signature"
+	defaultAdminMaxSkew      = 300
+	maxAdminSignedBodySize   = 1 << 20
+	codeAdminUnauthorizedErr = "80020"
+)
+
+// usedSignatures remembers accepted signatures until their timestamp is out of the skew
+// range, so a signed request can't be replayed
+type usedSignatures struct {
+	mu         sync.Mutex
+	timestamps map[string]int64 // signature -> signed timestamp
+}
+
+// use returns false if signature was used, expired ones are dropped first
+func (u *usedSignatures) use(signature string, timestamp, now, maxSkew int64) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for sig, ts := range u.timestamps {
+		if now-ts > maxSkew {
+			delete(u.timestamps, sig)
+		}
+	}
+	if _, exist := u.timestamps[signature]; exist {
+		return false
+	}
+	u.timestamps[signature] = timestamp
+	return true
+}
+
+// AdminAuth accepts requests carrying the configured bearer token, or signed with
+// hex(hmac-sha256(hmacSecret, timestamp + "\n" + method + "\n" + requestURI + "\n" + body)),
+// each signature is accepted once
+func AdminAuth(cfg config.Admin) gin.HandlerFunc {
+	maxSkew := cfg.HmacMaxSkewSeconds
+	if maxSkew <= 0 {
+		maxSkew = defaultAdminMaxSkew
+	}
+	used := &usedSignatures{timestamps: make(map[string]int64)}
+	return func(c *gin.Context) {
+		if len(cfg.Token) != 0 {
+			authorization := c.GetHeader("Authorization")
+			if strings.HasPrefix(authorization, "Bearer ") &&
+				subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(authorization, "Bearer ")), []byte(cfg.Token)) == 1 {
+				c.Set(admin_handlers.AuthTypeKey, admin_handlers.AuthTypeToken)
+				c.Next()
+				return
+			}
+		}
+
+		if len(cfg.HmacSecret) != 0 && len(c.GetHeader(adminSignatureHeader)) != 0 {
+			err := checkAdminSignature(c, cfg.HmacSecret, maxSkew, used)
+			if err == nil {
+				c.Set(admin_handlers.AuthTypeKey, admin_handlers.AuthTypeHmac)
+				c.Next()
+				return
+			}
+			logrus.Warnf("admin signature check failed, ip: %s, err: %s", c.ClientIP(), err)
+		}
+
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"status":  codeAdminUnauthorizedErr,
+			"message": "unauthorized",
+			"data":    struct{}{},
+		})
+	}
+}
+
+func checkAdminSignature(c *gin.Context, secret string, maxSkew int64, used *usedSignatures) error {
+	timestampStr := c.GetHeader(adminTimestampHeader)
+	timestamp, err := strconv.ParseInt(timestampStr, 10, 64)
+	if err != nil {
+		return fmt.Errorf("timestamp %s parse err: %s", timestampStr, err)
+	}
+	now := time.Now().Unix()
+	skew := now - timestamp
+	if skew > maxSkew || skew < -maxSkew {
+		return fmt.Errorf("timestamp %d out of range", timestamp)
+	}
+	signature, err := hex.DecodeString(c.GetHeader(adminSignatureHeader))
+	if err != nil {
+		return fmt.Errorf("signature decode err: %s", err)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxAdminSignedBodySize))
+	if err != nil {
+		return fmt.Errorf("read body err: %s", err)
+	}
+	// body is consumed, restore it for handlers
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(fmt.Sprintf("%s\n%s\n%s\n", timestampStr, c.Request.Method, c.Request.URL.RequestURI())))
+	mac.Write(body)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	if !used.use(hex.EncodeToString(signature), timestamp, now, maxSkew) {
+		return fmt.Errorf("signature is replayed")
+	}
+	return nil
+}
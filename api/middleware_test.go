@@ -0,0 +1,430 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stafihub/staking-election/api/admin_handlers"
+	"github.com/stafihub/staking-election/config"
+	"golang.org/x/time/rate"
+)
+
+func sign(secret string, timestamp int64, method, uri, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(fmt.Sprintf("%d\n%s\n%s\n%s", timestamp, method, uri, body)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// adminRouter echoes the auth type and body seen by the handler
+func adminRouter(cfg config.Admin) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/admin/pause", AdminAuth(cfg), func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, "%s:%s", c.GetString(admin_handlers.AuthTypeKey), body)
+	})
+	return router
+}
+
+func doAdmin(router http.Handler, uri, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, uri, strings.NewReader(body))
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdminAuth(t *testing.T) {
+	secret := "hmac-secret"
+	body := `{"denom":"uratom"}`
+	uri := "/admin/pause?denom=uratom"
+	now := time.Now().Unix()
+	signed := func(timestamp int64, signature string) map[string]string {
+		return map[string]string{
+			adminTimestampHeader: strconv.FormatInt(timestamp, 10),
+			adminSignatureHeader: signature,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		cfg      config.Admin
+		uri      string
+		header   map[string]string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "token",
+			cfg:      config.Admin{Token: "token"},
+			header:   map[string]string{"Authorization": "Bearer token"},
+			wantCode: http.StatusOK,
+			wantBody: "token:" + body,
+		},
+		{
+			name:     "wrong token",
+			cfg:      config.Admin{Token: "token"},
+			header:   map[string]string{"Authorization": "Bearer other"},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "token without bearer",
+			cfg:      config.Admin{Token: "token"},
+			header:   map[string]string{"Authorization": "token"},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "no credentials",
+			cfg:      config.Admin{Token: "token", HmacSecret: secret},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "hmac, body is kept for handler",
+			cfg:      config.Admin{HmacSecret: secret},
+			header:   signed(now, sign(secret, now, http.MethodPost, uri, body)),
+			wantCode: http.StatusOK,
+			wantBody: "hmac:" + body,
+		},
+		{
+			name:     "hmac within skew",
+			cfg:      config.Admin{HmacSecret: secret, HmacMaxSkewSeconds: 60},
+			header:   signed(now-50, sign(secret, now-50, http.MethodPost, uri, body)),
+			wantCode: http.StatusOK,
+			wantBody: "hmac:" + body,
+		},
+		{
+			name:     "hmac too old",
+			cfg:      config.Admin{HmacSecret: secret, HmacMaxSkewSeconds: 60},
+			header:   signed(now-70, sign(secret, now-70, http.MethodPost, uri, body)),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "hmac too far in future",
+			cfg:      config.Admin{HmacSecret: secret},
+			header:   signed(now+400, sign(secret, now+400, http.MethodPost, uri, body)),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "hmac of other secret",
+			cfg:      config.Admin{HmacSecret: secret},
+			header:   signed(now, sign("other", now, http.MethodPost, uri, body)),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "hmac of other uri",
+			cfg:      config.Admin{HmacSecret: secret},
+			uri:      "/admin/pause?denom=uriris",
+			header:   signed(now, sign(secret, now, http.MethodPost, uri, body)),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "hmac not hex",
+			cfg:      config.Admin{HmacSecret: secret},
+			header:   signed(now, "zz"),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "hmac without secret configured",
+			cfg:      config.Admin{Token: "token"},
+			header:   signed(now, sign("", now, http.MethodPost, uri, body)),
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestUri := uri
+			if len(tt.uri) != 0 {
+				requestUri = tt.uri
+			}
+			w := doAdmin(adminRouter(tt.cfg), requestUri, body, tt.header)
+			if w.Code != tt.wantCode {
+				t.Fatalf("code %d, want %d", w.Code, tt.wantCode)
+			}
+			if tt.wantCode == http.StatusOK && w.Body.String() != tt.wantBody {
+				t.Errorf("body %q, want %q", w.Body.String(), tt.wantBody)
+			}
+			if tt.wantCode == http.StatusUnauthorized && !strings.Contains(w.Body.String(), codeAdminUnauthorizedErr) {
+				t.Errorf("body %q has no status %s", w.Body.String(), codeAdminUnauthorizedErr)
+			}
+		})
+	}
+}
+
+func TestAdminAuthReplay(t *testing.T) {
+	secret := "hmac-secret"
+	router := adminRouter(config.Admin{HmacSecret: secret})
+	now := time.Now().Unix()
+	header := map[string]string{
+		adminTimestampHeader: strconv.FormatInt(now, 10),
+		adminSignatureHeader: sign(secret, now, http.MethodPost, "/admin/pause", "{}"),
+	}
+
+	if w := doAdmin(router, "/admin/pause", "{}", header); w.Code != http.StatusOK {
+		t.Fatalf("first request code %d", w.Code)
+	}
+	if w := doAdmin(router, "/admin/pause", "{}", header); w.Code != http.StatusUnauthorized {
+		t.Fatalf("replayed request code %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	// a new signature of the same request is accepted
+	header[adminTimestampHeader] = strconv.FormatInt(now-1, 10)
+	header[adminSignatureHeader] = sign(secret, now-1, http.MethodPost, "/admin/pause", "{}")
+	if w := doAdmin(router, "/admin/pause", "{}", header); w.Code != http.StatusOK {
+		t.Fatalf("newly signed request code %d", w.Code)
+	}
+}
+
+func TestUsedSignaturesExpire(t *testing.T) {
+	used := &usedSignatures{timestamps: make(map[string]int64)}
+	if !used.use("a", 100, 100, 10) {
+		t.Fatal("first use rejected")
+	}
+	if used.use("a", 100, 110, 10) {
+		t.Fatal("reuse within skew accepted")
+	}
+	// expired signatures are dropped, the skew check rejects them before use
+	used.use("b", 111, 111, 10)
+	if _, exist := used.timestamps["a"]; exist {
+		t.Error("expired signature is kept")
+	}
+	if len(used.timestamps) != 1 {
+		t.Errorf("%d signatures kept, want 1", len(used.timestamps))
+	}
+}
+
+func corsRouter(t *testing.T, cfg config.Cors) *gin.Engine {
+	t.Helper()
+	cors, err := Cors(cfg)
+	if err != nil {
+		t.Fatalf("Cors err: %s", err)
+	}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(cors)
+	handle := func(c *gin.Context) { c.String(http.StatusOK, "handled") }
+	router.GET("/data", handle)
+	router.OPTIONS("/data", handle)
+	return router
+}
+
+func TestCors(t *testing.T) {
+	patterns := config.Cors{
+		AllowOrigins:     []string{"https://*.stafihub.io", "https://App.Example.com/"},
+		AllowMethods:     []string{"GET"},
+		AllowHeaders:     []string{"Content-Type"},
+		ExposeHeaders:    []string{"ETag"},
+		AllowCredentials: true,
+		MaxAgeSeconds:    600,
+	}
+	tests := []struct {
+		name        string
+		cfg         config.Cors
+		method      string
+		origin      string
+		wantCode    int
+		wantOrigin  string // Access-Control-Allow-Origin, empty if not allowed
+		wantHandled bool
+	}{
+		{"all origins by default", config.Cors{}, http.MethodGet, "https://any.io", http.StatusOK, "*", true},
+		{"all origins by star", config.Cors{AllowOrigins: []string{"https://a.io", "*"}}, http.MethodGet, "https://any.io", http.StatusOK, "*", true},
+		{"no origin", patterns, http.MethodGet, "", http.StatusOK, "", true},
+		{"wildcard subdomain", patterns, http.MethodGet, "https://app.stafihub.io", http.StatusOK, "https://app.stafihub.io", true},
+		{"exact origin ignoring case and trailing slash", patterns, http.MethodGet, "https://app.example.COM", http.StatusOK, "https://app.example.COM", true},
+		{"other scheme", patterns, http.MethodGet, "http://app.stafihub.io", http.StatusOK, "", true},
+		{"suffix of allowed domain", patterns, http.MethodGet, "https://app.stafihub.io.evil.com", http.StatusOK, "", true},
+		{"bare domain does not match wildcard", patterns, http.MethodGet, "https://stafihub.io", http.StatusOK, "", true},
+		{"preflight allowed", patterns, http.MethodOptions, "https://app.stafihub.io", http.StatusNoContent, "https://app.stafihub.io", false},
+		{"preflight not allowed", patterns, http.MethodOptions, "https://evil.com", http.StatusForbidden, "", false},
+		{"preflight without origin", patterns, http.MethodOptions, "", http.StatusNoContent, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/data", nil)
+			if len(tt.origin) != 0 {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.method == http.MethodOptions {
+				req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			}
+			w := httptest.NewRecorder()
+			corsRouter(t, tt.cfg).ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("code %d, want %d", w.Code, tt.wantCode)
+			}
+			header := w.Header()
+			if got := header.Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("allow origin %q, want %q", got, tt.wantOrigin)
+			}
+			if handled := w.Body.String() == "handled"; handled != tt.wantHandled {
+				t.Errorf("handled %t, want %t", handled, tt.wantHandled)
+			}
+			if !strings.Contains(strings.Join(header.Values("Vary"), ","), "Origin") {
+				t.Errorf("vary %v has no Origin", header.Values("Vary"))
+			}
+			if len(tt.wantOrigin) == 0 {
+				return
+			}
+
+			wantCredentials := ""
+			if tt.cfg.AllowCredentials {
+				wantCredentials = "true"
+			}
+			if got := header.Get("Access-Control-Allow-Credentials"); got != wantCredentials {
+				t.Errorf("allow credentials %q, want %q", got, wantCredentials)
+			}
+			if tt.method != http.MethodOptions {
+				if len(header.Get("Access-Control-Expose-Headers")) == 0 {
+					t.Error("no expose headers")
+				}
+				return
+			}
+			if got := header.Get("Access-Control-Allow-Methods"); got != "GET" {
+				t.Errorf("allow methods %q", got)
+			}
+			if got := header.Get("Access-Control-Allow-Headers"); got != "Content-Type" {
+				t.Errorf("allow headers %q", got)
+			}
+			if got := header.Get("Access-Control-Max-Age"); got != "600" {
+				t.Errorf("max age %q", got)
+			}
+		})
+	}
+}
+
+func TestCorsConfigErr(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Cors
+	}{
+		{"invalid pattern", config.Cors{AllowOrigins: []string{"https://[.io"}}},
+		{"credentials for all origins", config.Cors{AllowCredentials: true}},
+		{"credentials for star", config.Cors{AllowOrigins: []string{"https://a.io", "*"}, AllowCredentials: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Cors(tt.cfg); err == nil {
+				t.Error("Cors want err")
+			}
+		})
+	}
+}
+
+func TestVisitors(t *testing.T) {
+	created := 0
+	v := newVisitors(2, time.Minute, func() *rate.Limiter {
+		created++
+		return rate.NewLimiter(rate.Every(time.Second), 1)
+	})
+	now := time.Unix(1000, 0)
+
+	a := v.getVisitor("a", now)
+	if v.getVisitor("a", now.Add(time.Second)) != a {
+		t.Fatal("limiter of a is not reused")
+	}
+	v.getVisitor("b", now.Add(2*time.Second))
+	// a is used more recently than b, so b is evicted beyond maxSize
+	v.getVisitor("a", now.Add(3*time.Second))
+	v.getVisitor("c", now.Add(4*time.Second))
+	if _, exist := v.elements["b"]; exist {
+		t.Error("least recently used b is kept")
+	}
+	if v.getVisitor("a", now.Add(5*time.Second)) != a {
+		t.Error("recently used a is evicted")
+	}
+	if created != 3 {
+		t.Errorf("%d limiters created, want 3", created)
+	}
+
+	// c is unused for more than ttl, a is used within ttl
+	v.getVisitor("a", now.Add(30*time.Second))
+	v.getVisitor("d", now.Add(65*time.Second))
+	if _, exist := v.elements["c"]; exist {
+		t.Error("expired c is kept")
+	}
+	if len(v.elements) != 2 || v.lru.Len() != 2 {
+		t.Errorf("%d elements %d lru, want 2", len(v.elements), v.lru.Len())
+	}
+	if v.getVisitor("a", now.Add(91*time.Second)) == a {
+		t.Error("expired a is reused")
+	}
+}
+
+func TestNewLimiter(t *testing.T) {
+	limiter := newLimiter(60, 0)
+	if limiter.Burst() != 1 {
+		t.Errorf("burst %d, want 1", limiter.Burst())
+	}
+	if limiter.Limit() != rate.Limit(1) {
+		t.Errorf("limit %v, want 1 per second", limiter.Limit())
+	}
+}
+
+func limitedCodes(router http.Handler, uri, ip string, n int) []int {
+	codes := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		req := httptest.NewRequest(http.MethodGet, uri, nil)
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		codes = append(codes, w.Code)
+	}
+	return codes
+}
+
+func TestIpRateLimiter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(IpRateLimiter(config.RateLimit{IpRatePerMinute: 1, IpBurst: 2}))
+	router.GET("/data", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	want := fmt.Sprint([]int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests})
+	if got := fmt.Sprint(limitedCodes(router, "/data", "10.0.0.1", 3)); got != want {
+		t.Errorf("codes of 10.0.0.1 %s, want %s", got, want)
+	}
+	// each ip has its own limiter
+	if got := fmt.Sprint(limitedCodes(router, "/data", "10.0.0.2", 3)); got != want {
+		t.Errorf("codes of 10.0.0.2 %s, want %s", got, want)
+	}
+}
+
+func TestRouteRateLimiter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RouteRateLimiter([]config.RouteLimit{
+		{Path: "/validator/:address", RatePerMinute: 1, Burst: 1},
+		{Path: "/free", RatePerMinute: 0},
+	}))
+	handle := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/validator/:address", handle)
+	router.GET("/free", handle)
+	router.GET("/other", handle)
+
+	// the limit is shared by all ips and params of the route
+	if got := limitedCodes(router, "/validator/a", "10.0.0.1", 1); got[0] != http.StatusOK {
+		t.Errorf("first code %d", got[0])
+	}
+	if got := limitedCodes(router, "/validator/b", "10.0.0.2", 1); got[0] != http.StatusTooManyRequests {
+		t.Errorf("second code %d, want %d", got[0], http.StatusTooManyRequests)
+	}
+	for _, uri := range []string{"/free", "/other"} {
+		for _, code := range limitedCodes(router, uri, "10.0.0.1", 3) {
+			if code != http.StatusOK {
+				t.Errorf("code of %s %d, want %d", uri, code, http.StatusOK)
+			}
+		}
+	}
+}
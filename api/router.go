@@ -4,27 +4,73 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	cosmosClient "github.com/stafihub/cosmos-relay-sdk/client"
+	"github.com/stafihub/staking-election/api/admin_handlers"
 	"github.com/stafihub/staking-election/api/election_handlers"
+	"github.com/stafihub/staking-election/config"
+	"github.com/stafihub/staking-election/dao/election"
 	"github.com/stafihub/staking-election/db"
+	"github.com/stafihub/staking-election/event"
+	"github.com/stafihub/staking-election/price"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"github.com/swaggo/gin-swagger/swaggerFiles"
 )
 
-func InitRouters(db *db.WrapDb) http.Handler {
+func InitRouters(cfg *config.Config, db *db.WrapDb, repos dao_election.Repositories, priceAggregator *price.Aggregator, cosmosClientMap map[string]*cosmosClient.Client,
+	updater admin_handlers.Updater, eventBroker *event.Broker, responseCache *ResponseCache) (http.Handler, error) {
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.Default()
 	router.MaxMultipartMemory = 8 << 20 // 8 MiB
+	// only trust forwarded headers from configured proxies, so ClientIP can't be spoofed, nil trusts no proxy
+	if err := router.SetTrustedProxies(cfg.Api.RateLimit.TrustedProxies); err != nil {
+		return nil, err
+	}
 	router.Static("/static", "./static")
-	router.Use(Cors())
+	cors, err := Cors(cfg.Api.Cors)
+	if err != nil {
+		return nil, err
+	}
+	router.Use(cors)
+	if cfg.Api.RateLimit.Enable {
+		if cfg.Api.RateLimit.IpRatePerMinute > 0 {
+			router.Use(IpRateLimiter(cfg.Api.RateLimit))
+		}
+		router.Use(RouteRateLimiter(cfg.Api.RateLimit.RouteLimitList))
+	}
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	rateHandler := election_handlers.NewHandler(db)
-	router.GET("/stakingElection/api/v1/annualRateList", rateHandler.HandleGetAverageAnnualRate)
-	router.GET("/stakingElection/api/v1/selectedValidators", rateHandler.HandleGetSelectedValidators)
+	rateHandler := election_handlers.NewHandler(cfg, db, repos, priceAggregator, cosmosClientMap, eventBroker)
+	router.GET("/stakingElection/api/v1/annualRateList", responseCache.Middleware(CacheGroupAnnualRate), rateHandler.HandleGetAverageAnnualRate)
+	router.GET("/stakingElection/api/v1/selectedValidators", responseCache.Middleware(CacheGroupSelectedValidators), rateHandler.HandleGetSelectedValidators)
+	router.GET("/stakingElection/api/v1/validators", rateHandler.HandleGetValidators)
+	router.GET("/stakingElection/api/v1/rTokenStatList", rateHandler.HandleGetRTokenStatList)
+	router.GET("/stakingElection/api/v1/validator", rateHandler.HandleGetValidator)
+	router.GET("/stakingElection/api/v1/redelegationHistory", rateHandler.HandleGetRedelegationHistory)
+	router.GET("/stakingElection/api/v1/electionExplain", rateHandler.HandleGetElectionExplain)
+	if cfg.Api.Event.Enable {
+		router.GET("/stakingElection/api/v1/events", rateHandler.HandleGetEventStream)
+		router.GET("/stakingElection/api/v1/eventsWs", rateHandler.HandleGetEventWs)
+	}
 
-	return router
+	if cfg.Api.Admin.Enable {
+		if len(cfg.Api.Admin.Token) == 0 && len(cfg.Api.Admin.HmacSecret) == 0 {
+			return nil, fmt.Errorf("admin api is enabled but neither token nor hmacSecret is set")
+		}
+		adminHandler := admin_handlers.NewHandler(cfg, db, updater)
+		admin := router.Group("/stakingElection/admin/v1", AdminAuth(cfg.Api.Admin))
+		admin.POST("/refresh", adminHandler.HandlePostRefresh)
+		admin.GET("/validatorList", adminHandler.HandleGetValidatorList)
+		admin.POST("/addValidatorListEntry", adminHandler.HandlePostAddValidatorListEntry)
+		admin.POST("/removeValidatorListEntry", adminHandler.HandlePostRemoveValidatorListEntry)
+		admin.GET("/pausedPools", adminHandler.HandleGetPausedPools)
+		admin.POST("/pausePool", adminHandler.HandlePostPausePool)
+		admin.POST("/resumePool", adminHandler.HandlePostResumePool)
+	}
+
+	return router, nil
 }
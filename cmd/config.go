@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stafihub/staking-election/config"
+	"github.com/stafihub/staking-election/retention"
+)
+
+const (
+	flagMode     = "mode"
+	flagRedacted = "redacted"
+	flagOutput   = "output"
+)
+
+func configCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Config tools",
+	}
+	cmd.AddCommand(
+		configValidateCmd(),
+		configShowCmd(),
+		configMigrateCmd(),
+	)
+	return cmd
+}
+
+func configValidateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Args:  cobra.ExactArgs(0),
+		Short: "Check config file for start-election or start-api",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configPath, err := cmd.Flags().GetString(flagConfig)
+			if err != nil {
+				return err
+			}
+			modeStr, err := cmd.Flags().GetString(flagMode)
+			if err != nil {
+				return err
+			}
+			mode, err := config.ParseMode(modeStr)
+			if err != nil {
+				return err
+			}
+			conf, err := config.Load(configPath)
+			if err != nil {
+				return err
+			}
+			err = validateConfig(conf, mode)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("config %s is valid for %s\n", configPath, mode)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagConfig, defaultConfigPath, "Config file path")
+	cmd.Flags().String(flagMode, string(config.ModeElection), "Process the config is used by (election|api)")
+	return cmd
+}
+
+func configShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Args:  cobra.ExactArgs(0),
+		Short: "Show config with environment overrides applied",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configPath, err := cmd.Flags().GetString(flagConfig)
+			if err != nil {
+				return err
+			}
+			redacted, err := cmd.Flags().GetBool(flagRedacted)
+			if err != nil {
+				return err
+			}
+			conf, err := config.Load(configPath)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("# precedence: env %s_<KEY>%s > env %s_<KEY> > %s\n", config.EnvPrefix, config.EnvFileSuffix, config.EnvPrefix, configPath)
+			for _, override := range conf.Overrides() {
+				fmt.Printf("# %s from %s\n", override.Key, override.Source)
+			}
+			if redacted {
+				conf = conf.Redacted()
+			}
+			return config.Encode(os.Stdout, conf)
+		},
+	}
+	cmd.Flags().String(flagConfig, defaultConfigPath, "Config file path")
+	cmd.Flags().Bool(flagRedacted, true, "Mask secrets such as db pwd and admin token")
+	return cmd
+}
+
+func configMigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Args:  cobra.ExactArgs(0),
+		Short: "Convert a flat format config to [election] and [api] sections with [defaults]",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configPath, err := cmd.Flags().GetString(flagConfig)
+			if err != nil {
+				return err
+			}
+			output, err := cmd.Flags().GetString(flagOutput)
+			if err != nil {
+				return err
+			}
+			conf, err := config.Migrate(configPath)
+			if err != nil {
+				return err
+			}
+			if len(output) == 0 {
+				return config.Encode(os.Stdout, conf)
+			}
+
+			// never overwrite, the source file may be the output
+			f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			err = config.Encode(f, conf)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("migrated config is written to %s\n", output)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagConfig, defaultConfigPath, "Config file path")
+	cmd.Flags().String(flagOutput, "", "Write migrated config to this new file instead of stdout")
+	return cmd
+}
+
+// validateConfig checks conf and the parts of it parsed by other packages
+func validateConfig(conf *config.Config, mode config.Mode) error {
+	verr := &config.ValidationError{}
+	err := conf.Validate(mode)
+	if err != nil {
+		if !errors.As(err, &verr) {
+			return err
+		}
+	}
+	if mode == config.ModeApi {
+		if _, err := retention.RulesFromConfig(conf.Api.Retention); err != nil {
+			verr.Add("%s", err)
+		}
+	}
+	return verr.OrNil()
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stafihub/staking-election/config"
+	"github.com/stafihub/staking-election/retention"
+)
+
+const flagDryRun = "dry-run"
+
+func dbCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Maintain db data",
+	}
+	cmd.AddCommand(dbPruneCmd())
+	return cmd
+}
+
+func dbPruneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Args:  cobra.ExactArgs(0),
+		Short: "Prune history tables by retention rules of config",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configPath, err := cmd.Flags().GetString(flagConfig)
+			if err != nil {
+				return err
+			}
+			dryRun, err := cmd.Flags().GetBool(flagDryRun)
+			if err != nil {
+				return err
+			}
+			conf, err := config.Load(configPath)
+			if err != nil {
+				return err
+			}
+			// rules apply even if scheduled pruning is disabled
+			rules, err := retention.RulesFromConfig(conf.Api.Retention)
+			if err != nil {
+				return err
+			}
+			if len(rules) == 0 {
+				fmt.Println("no retention rules configured")
+				return nil
+			}
+
+			wrapDb, err := openDb(conf.Db)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				sqlDb, err := wrapDb.DB.DB()
+				if err != nil {
+					logrus.Errorf("db.DB() err: %s", err)
+					return
+				}
+				sqlDb.Close()
+			}()
+
+			results, err := retention.Prune(wrapDb, rules, time.Now(), dryRun)
+			for _, result := range results {
+				fmt.Println(result)
+			}
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				fmt.Println("dry run, nothing deleted")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().String(flagConfig, defaultConfigPath, "Config file path")
+	cmd.Flags().Bool(flagDryRun, false, "Only print rows which would be deleted")
+	return cmd
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stafihub/staking-election/config"
+	"github.com/stafihub/staking-election/dao/migrate"
+	"github.com/stafihub/staking-election/db"
+)
+
+func migrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Manage db schema version",
+	}
+
+	cmd.AddCommand(
+		migrateSubCmd("status", "Show applied and pending migrations", cobra.ExactArgs(0), func(wrapDb *db.WrapDb, args []string) error {
+			status, err := migrate.Status(wrapDb)
+			if err != nil {
+				return err
+			}
+			for _, s := range status {
+				appliedAt := "pending"
+				if s.Applied {
+					appliedAt = time.Unix(s.AppliedAt, 0).UTC().Format(time.RFC3339)
+				}
+				fmt.Printf("%4d  %-30s  %s\n", s.Version, s.Name, appliedAt)
+			}
+			return nil
+		}),
+		migrateSubCmd("up", "Apply all pending migrations", cobra.ExactArgs(0), func(wrapDb *db.WrapDb, args []string) error {
+			return migrate.Up(wrapDb)
+		}),
+		migrateSubCmd("down", "Revert the latest applied migration", cobra.ExactArgs(0), func(wrapDb *db.WrapDb, args []string) error {
+			return migrate.Down(wrapDb)
+		}),
+		migrateSubCmd("to [version]", "Migrate up or down to version, 0 reverts all", cobra.ExactArgs(1), func(wrapDb *db.WrapDb, args []string) error {
+			version, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("version %s parse err: %s", args[0], err)
+			}
+			return migrate.To(wrapDb, version)
+		}),
+	)
+	return cmd
+}
+
+func migrateSubCmd(use, short string, args cobra.PositionalArgs, run func(wrapDb *db.WrapDb, args []string) error) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use,
+		Args:  args,
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configPath, err := cmd.Flags().GetString(flagConfig)
+			if err != nil {
+				return err
+			}
+			conf, err := config.Load(configPath)
+			if err != nil {
+				return err
+			}
+
+			wrapDb, err := openDb(conf.Db)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				sqlDb, err := wrapDb.DB.DB()
+				if err != nil {
+					logrus.Errorf("db.DB() err: %s", err)
+					return
+				}
+				sqlDb.Close()
+			}()
+
+			err = run(wrapDb, args)
+			if err != nil {
+				return err
+			}
+			version, err := migrate.CurrentVersion(wrapDb)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("schema version: %d, latest: %d\n", version, migrate.LatestVersion())
+			return nil
+		},
+	}
+	cmd.Flags().String(flagConfig, defaultConfigPath, "Config file path")
+	return cmd
+}
+
+func openDb(cfg config.Db) (*db.WrapDb, error) {
+	return db.NewDB(&db.Config{
+		Host:    cfg.Host,
+		Port:    cfg.Port,
+		User:    cfg.User,
+		Pass:    cfg.Pwd,
+		DBName:  cfg.Name,
+		Driver:  cfg.Driver,
+		SslMode: cfg.SslMode,
+	})
+}
+
+// prepareSchema migrates an in-memory sqlite db which is empty on every start,
+// other dbs must be migrated with the migrate command before starting
+func prepareSchema(cfg config.Db, wrapDb *db.WrapDb) error {
+	if cfg.Driver == db.DriverSqlite && cfg.Name == db.SqliteMemory {
+		return migrate.Up(wrapDb)
+	}
+	return migrate.CheckVersion(wrapDb)
+}
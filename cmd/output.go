@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+const (
+	outputText = "text"
+	outputJson = "json"
+	outputCsv  = "csv"
+)
+
+// validatorRecord is a row of select-vals output
+type validatorRecord struct {
+	Address      string `json:"address"`
+	Moniker      string `json:"moniker"`
+	AnnualRate   string `json:"annualRate"`
+	Commission   string `json:"commission"`
+	Tokens       string `json:"tokens"`
+	Slashes      uint64 `json:"slashes"`
+	MissedBlocks int64  `json:"missedBlocks"`
+	SkipReason   string `json:"skipReason"` // empty if the validator is eligible
+}
+
+var validatorRecordHeader = []string{"address", "moniker", "annualRate", "commission", "tokens", "slashes", "missedBlocks", "skipReason"}
+
+func (r validatorRecord) csvRow() []string {
+	return []string{r.Address, r.Moniker, r.AnnualRate, r.Commission, r.Tokens,
+		strconv.FormatUint(r.Slashes, 10), strconv.FormatInt(r.MissedBlocks, 10), r.SkipReason}
+}
+
+// validatorReport is the whole output of select-vals, validator-report has its own validatorFullReport
+type validatorReport struct {
+	Height            int64             `json:"height"`
+	TotalValidators   int               `json:"totalValidators"`
+	AverageAnnualRate string            `json:"averageAnnualRate"`
+	Validators        []validatorRecord `json:"validators"`
+}
+
+func checkOutputFormat(format string) error {
+	switch format {
+	case outputText, outputJson, outputCsv:
+		return nil
+	default:
+		return fmt.Errorf("output %s not support, should be %s, %s or %s", format, outputText, outputJson, outputCsv)
+	}
+}
+
+// progressWriter is where progress lines go, stderr for json and csv so stdout can be piped
+func progressWriter(format string) io.Writer {
+	if format == outputText {
+		return os.Stdout
+	}
+	return os.Stderr
+}
+
+func writeValidatorReport(w io.Writer, format string, report validatorReport) error {
+	switch format {
+	case outputJson:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case outputCsv:
+		csvWriter := csv.NewWriter(w)
+		if err := csvWriter.Write(validatorRecordHeader); err != nil {
+			return err
+		}
+		for _, record := range report.Validators {
+			if err := csvWriter.Write(record.csvRow()); err != nil {
+				return err
+			}
+		}
+		csvWriter.Flush()
+		return csvWriter.Error()
+	default:
+		fmt.Fprintln(w, "total validators: ", report.TotalValidators)
+		fmt.Fprintln(w, "average annual rate: ", report.AverageAnnualRate)
+		fmt.Fprintln(w, "\nvalidators: ")
+		for _, r := range report.Validators {
+			if len(r.SkipReason) != 0 {
+				fmt.Fprintf(w, "valAddress: %s moniker: %s will skip: %s\n", r.Address, r.Moniker, r.SkipReason)
+				continue
+			}
+			fmt.Fprintf(w, "valAddress: %s moniker: %s annualRate: %s commission: %s tokenAmount: %s slashes: %d missedBlocks: %d\n",
+				r.Address, r.Moniker, r.AnnualRate, r.Commission, r.Tokens, r.Slashes, r.MissedBlocks)
+		}
+		return nil
+	}
+}
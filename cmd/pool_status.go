@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	cosmosClient "github.com/stafihub/cosmos-relay-sdk/client"
+	stafihubClient "github.com/stafihub/stafi-hub-relay-sdk/client"
+	"github.com/stafihub/staking-election/config"
+	"github.com/stafihub/staking-election/db"
+	"github.com/stafihub/staking-election/task"
+)
+
+const flagPool = "pool"
+
+func poolStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pool-status",
+		Args:  cobra.ExactArgs(0),
+		Short: "Show status of a pool and what the election would decide now, nothing is submitted",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, err := cmd.Flags().GetString(flagOutput)
+			if err != nil {
+				return err
+			}
+			if output != outputText && output != outputJson {
+				return fmt.Errorf("output %s not support, should be %s or %s", output, outputText, outputJson)
+			}
+			denom, err := cmd.Flags().GetString(flagDenom)
+			if err != nil {
+				return err
+			}
+			poolAddrStr, err := cmd.Flags().GetString(flagPool)
+			if err != nil {
+				return err
+			}
+			if len(denom) == 0 || len(poolAddrStr) == 0 {
+				return fmt.Errorf("--%s and --%s are required", flagDenom, flagPool)
+			}
+			configPath, err := cmd.Flags().GetString(flagConfig)
+			if err != nil {
+				return err
+			}
+
+			conf, err := config.Load(configPath)
+			if err != nil {
+				return err
+			}
+			rtokenInfo, err := findRTokenInfo(conf, denom)
+			if err != nil {
+				return err
+			}
+			if len(conf.StafiHubEndpointList) == 0 {
+				return fmt.Errorf("stafiHubEndpointList is empty")
+			}
+
+			client, err := stafihubClient.NewClient(nil, "", "", conf.StafiHubEndpointList)
+			if err != nil {
+				return fmt.Errorf("hubClient.NewClient err: %s", err)
+			}
+			bondedPoolsRes, err := client.QueryPools(denom)
+			if err != nil {
+				return err
+			}
+			bonded := false
+			for _, addr := range bondedPoolsRes.Addrs {
+				bonded = bonded || addr == poolAddrStr
+			}
+			if !bonded {
+				return fmt.Errorf("pool %s is not a bonded pool of %s", poolAddrStr, denom)
+			}
+			addressPrefixRes, err := client.QueryAddressPrefix(denom)
+			if err != nil {
+				return err
+			}
+			c, err := cosmosClient.NewClient(nil, "", "", addressPrefixRes.AccAddressPrefix, rtokenInfo.EndpointList)
+			if err != nil {
+				return err
+			}
+
+			// db is optional, paused pools and allow/deny lists are read from it if configured
+			var electionDb *db.WrapDb
+			if len(conf.Db.Host) != 0 || conf.Db.Driver == db.DriverSqlite {
+				electionDb, err = openDb(conf.Db)
+				if err != nil {
+					return err
+				}
+				defer func() {
+					if sqlDb, err := electionDb.DB.DB(); err == nil {
+						sqlDb.Close()
+					}
+				}()
+			}
+
+			status, err := task.NewTask(conf, client, electionDb).PoolStatus(c, denom, poolAddrStr)
+			if err != nil {
+				return err
+			}
+			return writePoolStatus(cmd.OutOrStdout(), output, status)
+		},
+	}
+
+	cmd.Flags().String(flagDenom, "", "Denom of the rToken")
+	cmd.Flags().String(flagPool, "", "Pool address")
+	cmd.Flags().String(flagConfig, defaultConfigPath, "Config file path")
+	cmd.Flags().String(flagOutput, outputText, "Output format (text|json)")
+
+	return cmd
+}
+
+func writePoolStatus(w io.Writer, format string, s *task.PoolStatus) error {
+	if format == outputJson {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(s)
+	}
+
+	fmt.Fprintf(w, "denom:               %s\n", s.Denom)
+	fmt.Fprintf(w, "pool:                %s\n", s.PoolAddress)
+	fmt.Fprintf(w, "block time:          %s\n", formatUnix(s.BlockTime))
+	fmt.Fprintf(w, "cycle:               version %d, %d seconds, current number %d, target height %d\n",
+		s.CycleVersion, s.CycleSeconds, s.CurrentCycleNumber, s.TargetHeight)
+	fmt.Fprintf(w, "latest voted cycle:  version %d number %d\n", s.LatestVotedCycle.Version, s.LatestVotedCycle.Number)
+	fmt.Fprintf(w, "latest dealed cycle: version %d number %d\n", s.LatestDealedCycle.Version, s.LatestDealedCycle.Number)
+	if s.ShuffleSeconds == 0 {
+		fmt.Fprintf(w, "shuffle:             disabled\n")
+	} else {
+		fmt.Fprintf(w, "shuffle:             every %d seconds, after %s, needed now: %t\n", s.ShuffleSeconds, formatUnix(s.ShuffleAfter), s.NeedShuffle)
+	}
+
+	fmt.Fprintf(w, "\nvalidators on target height:\n")
+	for _, val := range s.Validators {
+		amount := val.Amount
+		if !val.Delegated {
+			amount = "not delegated"
+		}
+		fmt.Fprintf(w, "  %s inRValidatorList: %-5t amount: %s\n", val.Address, val.InRValidatorList, amount)
+	}
+	fmt.Fprintf(w, "\npending redelegations: %d\n", len(s.PendingRedelegations))
+	for _, r := range s.PendingRedelegations {
+		fmt.Fprintf(w, "  %s -> %s amount: %s completes at %s\n", r.SrcValidator, r.DstValidator, r.Amount, r.CompletionTime.UTC().Format(time.RFC3339))
+	}
+
+	fmt.Fprintf(w, "\ndecision:            %s\n", s.Decision)
+	switch s.Decision {
+	case task.PoolDecisionPaused:
+		fmt.Fprintf(w, "  pool is paused, election is skipped\n")
+	case task.PoolDecisionWait:
+		fmt.Fprintf(w, "  %s\n", s.WaitReason)
+	default:
+		if len(s.Election.OldValidator) != 0 {
+			fmt.Fprintf(w, "  old validator: %s\n  new validator: %s\n", s.Election.OldValidator, s.Election.NewValidator)
+		}
+		for _, val := range s.Election.RValidators {
+			fmt.Fprintf(w, "  rValidator %s passed: %-5t selected: %t\n", val.ValidatorAddress, val.Passed, val.Selected)
+		}
+	}
+	fmt.Fprintf(w, "note: a running election process skips a cycle it has checked, which is not known here\n")
+	return nil
+}
+
+func formatUnix(seconds int64) string {
+	return time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+}
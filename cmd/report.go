@@ -0,0 +1,398 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	stakingTypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/spf13/cobra"
+	client "github.com/stafihub/cosmos-relay-sdk/client"
+	"github.com/stafihub/rtoken-relay-core/common/core"
+	"github.com/stafihub/staking-election/config"
+	"github.com/stafihub/staking-election/dao/election"
+	"github.com/stafihub/staking-election/task"
+	"github.com/stafihub/staking-election/utils"
+)
+
+const (
+	flagDenom       = "denom"
+	flagSlashWindow = "slash-window"
+)
+
+type validatorFullReport struct {
+	Height            int64              `json:"height"`
+	Address           string             `json:"address"`
+	Moniker           string             `json:"moniker"`
+	Identity          string             `json:"identity"`
+	Website           string             `json:"website"`
+	Status            string             `json:"status"`
+	Jailed            bool               `json:"jailed"`
+	Tokens            string             `json:"tokens"`
+	VotingPowerRank   int                `json:"votingPowerRank"` // by tokens among bonded validators, 0 if not bonded
+	RankedValidators  int                `json:"rankedValidators"`
+	Commission        commissionReport   `json:"commission"`
+	SigningInfo       signingInfoReport  `json:"signingInfo"`
+	Slashes           slashesReport      `json:"slashes"`
+	AnnualRates       []annualRateReport `json:"annualRates"` // one per sampled height
+	AverageAnnualRate string             `json:"averageAnnualRate"`
+	Eligibility       *eligibilityReport `json:"eligibility,omitempty"` // set if --denom is given
+}
+
+type commissionReport struct {
+	Rate          string    `json:"rate"`
+	MaxRate       string    `json:"maxRate"`       // rate can never exceed it
+	MaxChangeRate string    `json:"maxChangeRate"` // max change of rate in a day
+	UpdateTime    time.Time `json:"updateTime"`
+}
+
+type signingInfoReport struct {
+	StartHeight  int64     `json:"startHeight"`
+	IndexOffset  int64     `json:"indexOffset"`
+	JailedUntil  time.Time `json:"jailedUntil"`
+	Tombstoned   bool      `json:"tombstoned"`
+	MissedBlocks int64     `json:"missedBlocks"`
+}
+
+type slashesReport struct {
+	FromHeight int64    `json:"fromHeight"`
+	ToHeight   int64    `json:"toHeight"`
+	Total      uint64   `json:"total"`
+	Fractions  []string `json:"fractions"`
+}
+
+type annualRateReport struct {
+	Height     int64  `json:"height"`
+	AnnualRate string `json:"annualRate"` // empty if the validator has no rewards on height
+}
+
+type eligibilityReport struct {
+	Denom  string                     `json:"denom"`
+	Passed bool                       `json:"passed"`
+	Rules  []dao_election.RuleOutcome `json:"rules"`
+}
+
+func validatorReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "validator-report [val-address]",
+		Aliases: []string{"show-val"},
+		Args:    cobra.ExactArgs(1),
+		Short:   "Show full report of a validator, with eligibility of a rToken if --denom is given",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, err := cmd.Flags().GetString(flagOutput)
+			if err != nil {
+				return err
+			}
+			if err := checkOutputFormat(output); err != nil {
+				return err
+			}
+			progress := progressWriter(output)
+
+			node, err := cmd.Flags().GetString(flagNode)
+			if err != nil {
+				return err
+			}
+			prefix, err := cmd.Flags().GetString(flagPrefix)
+			if err != nil {
+				return err
+			}
+			denom, err := cmd.Flags().GetString(flagDenom)
+			if err != nil {
+				return err
+			}
+			slashWindow, err := cmd.Flags().GetInt64(flagSlashWindow)
+			if err != nil {
+				return err
+			}
+
+			sampling := utils.DefaultSampling
+			if slashWindow <= 0 {
+				slashWindow = utils.SlashDuBlock
+			}
+			var rtokenInfo *config.RTokenInfo
+			if len(denom) != 0 {
+				configPath, err := cmd.Flags().GetString(flagConfig)
+				if err != nil {
+					return err
+				}
+				rtokenInfo, err = loadRTokenInfo(configPath, denom)
+				if err != nil {
+					return err
+				}
+				sampling = rtokenInfo.Sampling()
+				if !cmd.Flags().Changed(flagSlashWindow) {
+					slashWindow = *rtokenInfo.SlashDuBlock
+				}
+				if !cmd.Flags().Changed(flagNode) {
+					node = rtokenInfo.EndpointList[0]
+				}
+			}
+			fmt.Fprintln(progress, "node rpc: ", node)
+
+			c, err := client.NewClient(nil, "", "", prefix, []string{node})
+			if err != nil {
+				return err
+			}
+			curBLockHeight, err := c.GetCurrentBlockHeight()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(progress, "currentBlockHeight: ", curBLockHeight)
+
+			report, err := buildValidatorReport(c, progress, args[0], curBLockHeight, slashWindow, sampling)
+			if err != nil {
+				return err
+			}
+			if rtokenInfo != nil {
+				report.Eligibility = reportEligibility(report, *rtokenInfo)
+			}
+
+			return writeValidatorFullReport(cmd.OutOrStdout(), output, report)
+		},
+	}
+
+	cmd.Flags().String(flagNode, "http://localhost:26657", "Node rpc endpoint, the first endpoint of the rToken if --denom is given")
+	cmd.Flags().String(flagPrefix, "cosmos", "Account prefix (comos|stafi|iaa)")
+	cmd.Flags().String(flagDenom, "", "Check eligibility rules and use sampling parameters of this rToken in config")
+	cmd.Flags().String(flagConfig, defaultConfigPath, "Config file path, used with --denom")
+	cmd.Flags().Int64(flagSlashWindow, 0, "Count slashes within this many blocks, slashDuBlock of the rToken or 10000 if not set")
+	cmd.Flags().String(flagOutput, outputText, "Output format (text|json|csv)")
+
+	return cmd
+}
+
+// reportEligibility checks the rules of rtokenInfo the election applies to both candidates
+// and rValidators, so it never passes a validator the election would remove
+func reportEligibility(report validatorFullReport, rtokenInfo config.RTokenInfo) *eligibilityReport {
+	evaluation := dao_election.ValidatorEvaluation{
+		ValidatorAddress: report.Address,
+		Commission:       report.Commission.Rate,
+		SlashAmount:      report.Slashes.Total,
+		MissedBlocks:     report.SigningInfo.MissedBlocks,
+		Jailed:           report.Jailed,
+		Tombstoned:       report.SigningInfo.Tombstoned,
+	}
+	rules := task.ReportRules(evaluation, rtokenInfo)
+	passed := true
+	for _, rule := range rules {
+		passed = passed && rule.Passed
+	}
+	return &eligibilityReport{Denom: rtokenInfo.Denom, Passed: passed, Rules: rules}
+}
+
+// loadRTokenInfo returns the rToken of denom in config, with the params eligibility rules need
+func loadRTokenInfo(configPath, denom string) (*config.RTokenInfo, error) {
+	conf, err := config.Load(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return findRTokenInfo(conf, denom)
+}
+
+func findRTokenInfo(conf *config.Config, denom string) (*config.RTokenInfo, error) {
+	for _, rtokenInfo := range conf.RTokenInfo {
+		if rtokenInfo.Denom != denom {
+			continue
+		}
+		if rtokenInfo.MaxCommission == nil || rtokenInfo.MaxMissedBlocks == nil {
+			return nil, fmt.Errorf("maxCommission and maxMissedBlocks of %s should be set in it or [defaults]", denom)
+		}
+		if len(rtokenInfo.EndpointList) == 0 {
+			return nil, fmt.Errorf("endpointList of %s is empty", denom)
+		}
+		return &rtokenInfo, nil
+	}
+	return nil, fmt.Errorf("rtoken info of denom %s not exist in config", denom)
+}
+
+func buildValidatorReport(c *client.Client, progress io.Writer, valAddrStr string, height, slashWindow int64, sampling utils.Sampling) (validatorFullReport, error) {
+	report := validatorFullReport{Height: height, Address: valAddrStr}
+
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	valAddress, err := sdk.ValAddressFromBech32(valAddrStr)
+	done()
+	if err != nil {
+		return report, err
+	}
+
+	validatorRes, err := c.QueryValidator(valAddrStr, height)
+	if err != nil {
+		return report, err
+	}
+	validator := validatorRes.Validator
+	report.Moniker = validator.Description.Moniker
+	report.Identity = validator.Description.Identity
+	report.Website = validator.Description.Website
+	report.Status = validator.Status.String()
+	report.Jailed = validator.Jailed
+	report.Tokens = validator.Tokens.String()
+	report.Commission = commissionReport{
+		Rate:          validator.Commission.Rate.String(),
+		MaxRate:       validator.Commission.MaxRate.String(),
+		MaxChangeRate: validator.Commission.MaxChangeRate.String(),
+		UpdateTime:    validator.Commission.UpdateTime,
+	}
+
+	consAddrStr, err := utils.GetValidatorConsAddress(c, validator)
+	if err != nil {
+		return report, err
+	}
+	signInfo, err := c.QuerySigningInfo(consAddrStr, height)
+	if err != nil {
+		return report, err
+	}
+	report.SigningInfo = signingInfoReport{
+		StartHeight:  signInfo.ValSigningInfo.StartHeight,
+		IndexOffset:  signInfo.ValSigningInfo.IndexOffset,
+		JailedUntil:  signInfo.ValSigningInfo.JailedUntil,
+		Tombstoned:   signInfo.ValSigningInfo.Tombstoned,
+		MissedBlocks: signInfo.ValSigningInfo.MissedBlocksCounter,
+	}
+
+	bondedRes, err := c.QueryValidators(height)
+	if err != nil {
+		return report, err
+	}
+	report.RankedValidators = len(bondedRes.Validators)
+	report.VotingPowerRank = votingPowerRank(bondedRes.Validators, valAddrStr)
+
+	slashFromHeight := height - slashWindow
+	if slashFromHeight < 1 {
+		slashFromHeight = 1
+	}
+	slashRes, err := c.QueryValidatorSlashes(valAddress, slashFromHeight, height)
+	if err != nil {
+		return report, err
+	}
+	report.Slashes = slashesReport{
+		FromHeight: slashFromHeight,
+		ToHeight:   height,
+		Total:      slashRes.Pagination.Total,
+		Fractions:  make([]string, 0, len(slashRes.Slashes)),
+	}
+	for _, slash := range slashRes.Slashes {
+		report.Slashes.Fractions = append(report.Slashes.Fractions, slash.Fraction.String())
+	}
+
+	// annual rate is averaged over sampled heights the same way as selection does
+	total := sdk.ZeroDec()
+	report.AnnualRates = make([]annualRateReport, 0, sampling.StepNumber)
+	for i := int64(0); i < sampling.StepNumber; i++ {
+		sampleHeight := height - i*sampling.StepSize
+		fmt.Fprintf(progress, "wait to get annual rates on height %d...\n", sampleHeight)
+		valMap, err := utils.GetValidatorAnnualRateOnHeight(c, sampleHeight, sampling.StepSize)
+		if err != nil {
+			return report, err
+		}
+		rate := annualRateReport{Height: sampleHeight}
+		if val, exist := valMap[valAddrStr]; exist {
+			rate.AnnualRate = val.AnnualRate.String()
+			total = total.Add(val.AnnualRate)
+		}
+		report.AnnualRates = append(report.AnnualRates, rate)
+	}
+	if sampling.StepNumber > 0 {
+		report.AverageAnnualRate = total.Quo(sdk.NewDec(sampling.StepNumber)).String()
+	}
+	return report, nil
+}
+
+// votingPowerRank is the rank of valAddrStr by tokens among validators, start from 1, 0 if it's not in them
+func votingPowerRank(validators []stakingTypes.Validator, valAddrStr string) int {
+	valSlice := append(make([]stakingTypes.Validator, 0, len(validators)), validators...)
+	sort.SliceStable(valSlice, func(i, j int) bool {
+		return valSlice[i].Tokens.GT(valSlice[j].Tokens)
+	})
+	for i, val := range valSlice {
+		if val.OperatorAddress == valAddrStr {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+var validatorFullReportHeader = []string{"height", "address", "moniker", "identity", "website", "status", "jailed", "tombstoned",
+	"tokens", "votingPowerRank", "rankedValidators", "commissionRate", "commissionMaxRate", "commissionMaxChangeRate",
+	"commissionUpdateTime", "startHeight", "indexOffset", "missedBlocks", "jailedUntil", "slashes", "slashFromHeight",
+	"slashToHeight", "annualRates", "averageAnnualRate", "eligibilityDenom", "eligible", "failedRules"}
+
+// csvRow flattens the report, annualRates are height:rate separated by space, eligibility
+// columns are empty if --denom is not given
+func (r validatorFullReport) csvRow() []string {
+	annualRates := make([]string, 0, len(r.AnnualRates))
+	for _, rate := range r.AnnualRates {
+		annualRates = append(annualRates, fmt.Sprintf("%d:%s", rate.Height, rate.AnnualRate))
+	}
+	var denom, eligible string
+	failedRules := make([]string, 0)
+	if r.Eligibility != nil {
+		denom = r.Eligibility.Denom
+		eligible = strconv.FormatBool(r.Eligibility.Passed)
+		for _, rule := range r.Eligibility.Rules {
+			if !rule.Passed {
+				failedRules = append(failedRules, rule.Rule)
+			}
+		}
+	}
+	return []string{strconv.FormatInt(r.Height, 10), r.Address, r.Moniker, r.Identity, r.Website, r.Status,
+		strconv.FormatBool(r.Jailed), strconv.FormatBool(r.SigningInfo.Tombstoned), r.Tokens,
+		strconv.Itoa(r.VotingPowerRank), strconv.Itoa(r.RankedValidators), r.Commission.Rate, r.Commission.MaxRate,
+		r.Commission.MaxChangeRate, r.Commission.UpdateTime.UTC().Format(time.RFC3339),
+		strconv.FormatInt(r.SigningInfo.StartHeight, 10), strconv.FormatInt(r.SigningInfo.IndexOffset, 10),
+		strconv.FormatInt(r.SigningInfo.MissedBlocks, 10), r.SigningInfo.JailedUntil.UTC().Format(time.RFC3339),
+		strconv.FormatUint(r.Slashes.Total, 10), strconv.FormatInt(r.Slashes.FromHeight, 10), strconv.FormatInt(r.Slashes.ToHeight, 10),
+		strings.Join(annualRates, " "), r.AverageAnnualRate, denom, eligible, strings.Join(failedRules, " ")}
+}
+
+func writeValidatorFullReport(w io.Writer, format string, r validatorFullReport) error {
+	switch format {
+	case outputJson:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	case outputCsv:
+		csvWriter := csv.NewWriter(w)
+		if err := csvWriter.Write(validatorFullReportHeader); err != nil {
+			return err
+		}
+		if err := csvWriter.Write(r.csvRow()); err != nil {
+			return err
+		}
+		csvWriter.Flush()
+		return csvWriter.Error()
+	}
+
+	fmt.Fprintf(w, "validator:          %s\n", r.Address)
+	fmt.Fprintf(w, "moniker:            %s\n", r.Moniker)
+	fmt.Fprintf(w, "identity:           %s\n", r.Identity)
+	fmt.Fprintf(w, "website:            %s\n", r.Website)
+	fmt.Fprintf(w, "status:             %s jailed: %t tombstoned: %t\n", r.Status, r.Jailed, r.SigningInfo.Tombstoned)
+	fmt.Fprintf(w, "tokens:             %s\n", r.Tokens)
+	fmt.Fprintf(w, "voting power rank:  %d of %d\n", r.VotingPowerRank, r.RankedValidators)
+	fmt.Fprintf(w, "commission:         rate %s, max rate %s, max change rate %s, updated %s\n",
+		r.Commission.Rate, r.Commission.MaxRate, r.Commission.MaxChangeRate, r.Commission.UpdateTime.UTC().Format(time.RFC3339))
+	fmt.Fprintf(w, "signing info:       start height %d, index offset %d, missed blocks %d, jailed until %s\n",
+		r.SigningInfo.StartHeight, r.SigningInfo.IndexOffset, r.SigningInfo.MissedBlocks, r.SigningInfo.JailedUntil.UTC().Format(time.RFC3339))
+	fmt.Fprintf(w, "slashes:            %d in blocks [%d, %d], fractions %v\n", r.Slashes.Total, r.Slashes.FromHeight, r.Slashes.ToHeight, r.Slashes.Fractions)
+	fmt.Fprintf(w, "average annual rate: %s\n", r.AverageAnnualRate)
+	for _, rate := range r.AnnualRates {
+		annualRate := rate.AnnualRate
+		if len(annualRate) == 0 {
+			annualRate = "no rewards"
+		}
+		fmt.Fprintf(w, "  height %d: %s\n", rate.Height, annualRate)
+	}
+	if r.Eligibility != nil {
+		fmt.Fprintf(w, "eligible for %s:    %t\n", r.Eligibility.Denom, r.Eligibility.Passed)
+		for _, rule := range r.Eligibility.Rules {
+			fmt.Fprintf(w, "  %-16s passed: %-5t %s\n", rule.Rule, rule.Passed, rule.Detail)
+		}
+	}
+	return nil
+}
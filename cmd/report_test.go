@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stafihub/staking-election/config"
+	"github.com/stafihub/staking-election/dao/election"
+)
+
+func testFullReport() validatorFullReport {
+	return validatorFullReport{
+		Height:           1000,
+		Address:          "cosmosvaloper1abc",
+		Moniker:          "val, one",
+		Status:           "BOND_STATUS_BONDED",
+		Tokens:           "5000",
+		VotingPowerRank:  3,
+		RankedValidators: 100,
+		Commission:       commissionReport{Rate: "0.05", MaxRate: "0.2", MaxChangeRate: "0.01", UpdateTime: time.Unix(0, 0)},
+		SigningInfo:      signingInfoReport{MissedBlocks: 12, JailedUntil: time.Unix(0, 0)},
+		Slashes:          slashesReport{FromHeight: 900, ToHeight: 1000, Total: 1},
+		AnnualRates:      []annualRateReport{{Height: 1000, AnnualRate: "0.1"}, {Height: 990}},
+		Eligibility: &eligibilityReport{Denom: "uratom", Rules: []dao_election.RuleOutcome{
+			{Rule: "maxSlashAmount", Passed: false}, {Rule: "notJailed", Passed: true}, {Rule: "maxCommission", Passed: false},
+		}},
+	}
+}
+
+func TestWriteValidatorFullReportCsv(t *testing.T) {
+	report := testFullReport()
+	buf := &bytes.Buffer{}
+	if err := writeValidatorFullReport(buf, outputCsv, report); err != nil {
+		t.Fatalf("writeValidatorFullReport err: %s", err)
+	}
+	records, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv err: %s", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("%d csv records, want header and one row", len(records))
+	}
+
+	row := make(map[string]string)
+	for i, column := range records[0] {
+		row[column] = records[1][i]
+	}
+	want := map[string]string{
+		"address":         "cosmosvaloper1abc",
+		"moniker":         "val, one",
+		"votingPowerRank": "3",
+		"commissionRate":  "0.05",
+		"missedBlocks":    "12",
+		"slashes":         "1",
+		"annualRates":     "1000:0.1 990:",
+		"eligible":        "false",
+		"failedRules":     "maxSlashAmount maxCommission",
+	}
+	for column, value := range want {
+		if row[column] != value {
+			t.Errorf("%s %q, want %q", column, row[column], value)
+		}
+	}
+
+	// eligibility columns are empty without --denom
+	report.Eligibility = nil
+	if row := report.csvRow(); row[len(row)-3] != "" || row[len(row)-2] != "" || row[len(row)-1] != "" {
+		t.Errorf("eligibility columns %q without denom", row[len(row)-3:])
+	}
+}
+
+func TestReportEligibility(t *testing.T) {
+	slashDuBlock, maxSlashAmount, maxMissedBlocks := int64(100), uint64(1), int64(20)
+	rtokenInfo := config.RTokenInfo{Denom: "uratom", RTokenParams: config.RTokenParams{
+		MaxCommission:   &config.Dec{Dec: sdk.MustNewDecFromStr("0.1")},
+		MaxMissedBlocks: &maxMissedBlocks,
+		SlashDuBlock:    &slashDuBlock,
+		MaxSlashAmount:  &maxSlashAmount,
+	}}
+
+	report := testFullReport()
+	eligibility := reportEligibility(report, rtokenInfo)
+	if !eligibility.Passed || eligibility.Denom != "uratom" {
+		t.Errorf("eligibility %+v, want passed", eligibility)
+	}
+
+	// the election removes rValidators of too high commission
+	report.Commission.Rate = "0.2"
+	eligibility = reportEligibility(report, rtokenInfo)
+	failed := make([]string, 0)
+	for _, rule := range eligibility.Rules {
+		if !rule.Passed {
+			failed = append(failed, rule.Rule)
+		}
+	}
+	if eligibility.Passed || len(failed) != 1 || failed[0] != "maxCommission" {
+		t.Errorf("eligibility passed %t failed %v, want maxCommission failed", eligibility.Passed, failed)
+	}
+}
@@ -28,8 +28,12 @@ func NewRootCmd() *cobra.Command {
 	rootCmd.AddCommand(
 		startElectionCmd(),
 		startApiCmd(),
+		configCmd(),
+		migrateCmd(),
+		dbCmd(),
 		selectValidatorsCmd(),
-		showValidatorsCmd(),
+		validatorReportCmd(),
+		poolStatusCmd(),
 		versionCmd(),
 		keyCmd(),
 	)
@@ -10,7 +10,7 @@ import (
 	"github.com/spf13/cobra"
 	stafihubClient "github.com/stafihub/stafi-hub-relay-sdk/client"
 	"github.com/stafihub/staking-election/config"
-	"github.com/stafihub/staking-election/dao/migrate"
+	"github.com/stafihub/staking-election/dao/election"
 	"github.com/stafihub/staking-election/db"
 	"github.com/stafihub/staking-election/log"
 	"github.com/stafihub/staking-election/server"
@@ -53,23 +53,56 @@ func startElectionCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			err = validateConfig(conf, config.ModeElection)
+			if err != nil {
+				return err
+			}
 			fmt.Printf("\nconfig info: \nelectorAccount: %s\ngasPrice: %s\nkeystorePath: %s\nrTokenInfo: %+v\nstafihubEndpointList: %v\n\n",
-				conf.ElectorAccount, conf.GasPrice, conf.KeystorePath, conf.RTokenInfo, conf.StafiHubEndpointList)
+				conf.Election.ElectorAccount, conf.Election.GasPrice, conf.Election.KeystorePath, conf.RTokenInfo, conf.StafiHubEndpointList)
 
 			//interrupt signal
 			ctx := utils.ShutdownListener()
 
-			fmt.Printf("Will open stafihub wallet from <%s>. \nPlease ", conf.KeystorePath)
-			key, err := keyring.New(types.KeyringServiceName(), keyring.BackendFile, conf.KeystorePath, os.Stdin)
+			fmt.Printf("Will open stafihub wallet from <%s>. \nPlease ", conf.Election.KeystorePath)
+			key, err := keyring.New(types.KeyringServiceName(), keyring.BackendFile, conf.Election.KeystorePath, os.Stdin)
 			if err != nil {
 				return err
 			}
-			client, err := stafihubClient.NewClient(key, conf.ElectorAccount, conf.GasPrice, conf.StafiHubEndpointList)
+			client, err := stafihubClient.NewClient(key, conf.Election.ElectorAccount, conf.Election.GasPrice, conf.StafiHubEndpointList)
 			if err != nil {
 				return fmt.Errorf("hubClient.NewClient err: %s", err)
 			}
 
-			t := task.NewTask(conf, client)
+			// db is optional for election, it's used to record election details
+			var electionDb *db.WrapDb
+			if len(conf.Db.Host) != 0 || conf.Db.Driver == db.DriverSqlite {
+				electionDb, err = openDb(conf.Db)
+				if err != nil {
+					logrus.Errorf("db err: %s", err)
+					return err
+				}
+				logrus.Infof("db connect success")
+
+				defer func() {
+					sqlDb, err := electionDb.DB.DB()
+					if err != nil {
+						logrus.Errorf("db.DB() err: %s", err)
+						return
+					}
+					logrus.Infof("shutting down the db ...")
+					sqlDb.Close()
+				}()
+
+				err = prepareSchema(conf.Db, electionDb)
+				if err != nil {
+					logrus.Errorf("db schema check err: %s", err)
+					return err
+				}
+			} else {
+				logrus.Warnf("db is not set, paused pools and validator allow/deny lists of the admin api are ignored")
+			}
+
+			t := task.NewTask(conf, client, electionDb)
 			err = t.Start()
 			if err != nil {
 				logrus.Errorf("task start err: %s", err)
@@ -117,20 +150,18 @@ func startApiCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			err = validateConfig(conf, config.ModeApi)
+			if err != nil {
+				return err
+			}
 			fmt.Printf("\nconfig info: \nlistenAddr: %s\nrTokenInfo: %+v\nstafihubEndpointList: %v\n\n",
-				conf.ListenAddr, conf.RTokenInfo, conf.StafiHubEndpointList)
+				conf.Api.ListenAddr, conf.RTokenInfo, conf.StafiHubEndpointList)
 
 			//interrupt signal
 			ctx := utils.ShutdownListener()
 
 			//init db
-			db, err := db.NewDB(&db.Config{
-				Host:   conf.Db.Host,
-				Port:   conf.Db.Port,
-				User:   conf.Db.User,
-				Pass:   conf.Db.Pwd,
-				DBName: conf.Db.Name,
-			})
+			db, err := openDb(conf.Db)
 			if err != nil {
 				logrus.Errorf("db err: %s", err)
 				return err
@@ -147,9 +178,9 @@ func startApiCmd() *cobra.Command {
 				sqlDb.Close()
 			}()
 
-			err = migrate.AutoMigrate(db)
+			err = prepareSchema(conf.Db, db)
 			if err != nil {
-				logrus.Errorf("dao autoMigrate err: %s", err)
+				logrus.Errorf("db schema check err: %s", err)
 				return err
 			}
 			client, err := stafihubClient.NewClient(nil, "", "", conf.StafiHubEndpointList)
@@ -157,7 +188,7 @@ func startApiCmd() *cobra.Command {
 				return fmt.Errorf("hubClient.NewClient err: %s", err)
 			}
 			//server
-			server, err := server.NewServer(conf, client, db)
+			server, err := server.NewServer(conf, client, db, dao_election.NewGormRepositories(db))
 			if err != nil {
 				logrus.Errorf("new server err: %s", err)
 				return err
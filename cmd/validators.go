@@ -2,13 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
-	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	client "github.com/stafihub/cosmos-relay-sdk/client"
-	"github.com/stafihub/rtoken-relay-core/common/core"
 	"github.com/stafihub/staking-election/utils"
 )
 
@@ -33,22 +32,30 @@ func selectValidatorsCmd() *cobra.Command {
 				return err
 			}
 			logrus.SetLevel(logLevel)
+			output, err := cmd.Flags().GetString(flagOutput)
+			if err != nil {
+				return err
+			}
+			if err := checkOutputFormat(output); err != nil {
+				return err
+			}
+			progress := progressWriter(output)
 			node, err := cmd.Flags().GetString(flagNode)
 			if err != nil {
 				return err
 			}
 
-			fmt.Println("node rpc: ", node)
+			fmt.Fprintln(progress, "node rpc: ", node)
 			prefix, err := cmd.Flags().GetString(flagPrefix)
 			if err != nil {
 				return err
 			}
-			fmt.Println("prefix: ", prefix)
+			fmt.Fprintln(progress, "prefix: ", prefix)
 			number, err := cmd.Flags().GetInt64(flagNumber)
 			if err != nil {
 				return err
 			}
-			fmt.Println("number: ", number)
+			fmt.Fprintln(progress, "number: ", number)
 			maxMissedBlocks, err := cmd.Flags().GetInt64(flagMaxMissedBlocks)
 			if err != nil {
 				return err
@@ -63,76 +70,40 @@ func selectValidatorsCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			fmt.Println("currentBlockHeight: ", curBLockHeight)
+			fmt.Fprintln(progress, "currentBlockHeight: ", curBLockHeight)
 
-			fmt.Println("wait to get allValidator...")
-			allValidator, err := utils.GetValidatorAnnualRate(c, curBLockHeight)
+			fmt.Fprintln(progress, "wait to get allValidator...")
+			allValidator, err := utils.GetValidatorAnnualRate(c, curBLockHeight, utils.DefaultSampling)
 			if err != nil {
 				return err
 			}
 
-			fmt.Println("wait to get averageAnnualRate...")
+			fmt.Fprintln(progress, "wait to get averageAnnualRate...")
 			averageAnnualRate, err := utils.GetAverageAnnualRate(c, curBLockHeight, allValidator)
 			if err != nil {
 				return err
 			}
-			fmt.Println("wait to get selectedValidator...")
+			fmt.Fprintln(progress, "wait to get selectedValidator...")
 			valSlice, err := utils.GetSelectedValidator(c, curBLockHeight, number, allValidator)
 			if err != nil {
 				return err
 			}
-			fmt.Println("total validators: ", len(allValidator))
-			fmt.Println("average annual rate: ", averageAnnualRate.String())
-			fmt.Println("\nselected validators: ")
-			for _, val := range valSlice {
-				valAddress, err := sdk.ValAddressFromBech32(val.OperatorAddress)
-				if err != nil {
-					return err
-				}
-				slashRes, err := c.QueryValidatorSlashes(valAddress, curBLockHeight-utils.SlashDuBlock, curBLockHeight)
-				if err != nil {
-					return err
-				}
-				// (0). should remove slashed validators
-				if slashRes.Pagination.Total > utils.MaxSlashAmount {
-					fmt.Printf("slash total: %d, will skip \n", slashRes.Pagination.Total)
-					continue
-				}
 
-				// (1). should skip missed blocks excessively validator
-				validatorRes, err := c.QueryValidator(val.OperatorAddress, curBLockHeight)
-				if err != nil {
-					return err
-				}
-
-				done := core.UseSdkConfigContext(c.GetAccountPrefix())
-				consPubkeyJson, err := c.Ctx().Codec.MarshalJSON(validatorRes.Validator.ConsensusPubkey)
-				if err != nil {
-					done()
-					return err
-				}
-				var pk cryptotypes.PubKey
-				if err := c.Ctx().Codec.UnmarshalInterfaceJSON(consPubkeyJson, &pk); err != nil {
-					done()
-					return err
-				}
-				consAddr := sdk.ConsAddress(pk.Address())
-				consAddrStr := consAddr.String()
-				done()
-
-				signInfo, err := c.QuerySigningInfo(consAddrStr, curBLockHeight)
+			report := validatorReport{
+				Height:            curBLockHeight,
+				TotalValidators:   len(allValidator),
+				AverageAnnualRate: averageAnnualRate.String(),
+				Validators:        make([]validatorRecord, 0, len(valSlice)),
+			}
+			for _, val := range valSlice {
+				record, err := evaluateValidator(c, val, curBLockHeight, maxMissedBlocks)
 				if err != nil {
 					return err
 				}
-				if validatorRes.Validator.Jailed || signInfo.ValSigningInfo.Tombstoned || signInfo.ValSigningInfo.MissedBlocksCounter > maxMissedBlocks {
-					fmt.Printf("validator jailed %v, tombstoned %v, missed %d, will skip \n", validatorRes.Validator.Jailed, signInfo.ValSigningInfo.Tombstoned, signInfo.ValSigningInfo.MissedBlocksCounter)
-					continue
-				}
-
-				fmt.Printf("valAddress: %s annualRate: %s commission: %s tokenAmount: %s\n", val.OperatorAddress, val.AnnualRate, val.Commission, val.TokenAmount)
+				report.Validators = append(report.Validators, record)
 			}
 
-			return nil
+			return writeValidatorReport(cmd.OutOrStdout(), output, report)
 		},
 	}
 
@@ -140,65 +111,63 @@ func selectValidatorsCmd() *cobra.Command {
 	cmd.Flags().Int64(flagNumber, 5, "Validators number limit")
 	cmd.Flags().String(flagPrefix, "cosmos", "Account prefix (comos|stafi|iaa)")
 	cmd.Flags().Int64(flagMaxMissedBlocks, 100, "max missed blocks")
+	cmd.Flags().String(flagOutput, outputText, "Output format (text|json|csv)")
 	cmd.Flags().String(flagLogLevel, logrus.InfoLevel.String(), "The logging level (trace|debug|info|warn|error|fatal|panic)")
 
 	return cmd
 }
 
-func showValidatorsCmd() *cobra.Command {
-	cmd := &cobra.Command{
-		Use:     "show-val [val-address]",
-		Aliases: []string{"v"},
-		Args:    cobra.ExactArgs(1),
-		Short:   "Show validator info",
-		RunE: func(cmd *cobra.Command, args []string) error {
-
-			node, err := cmd.Flags().GetString(flagNode)
-			if err != nil {
-				return err
-			}
-			fmt.Println("node rpc: ", node)
-			prefix, err := cmd.Flags().GetString(flagPrefix)
-			if err != nil {
-				return err
-			}
-			fmt.Println("prefix: ", prefix)
-
-			valAddr := args[0]
-
-			c, err := client.NewClient(nil, "", "", prefix, []string{node})
-			if err != nil {
-				return err
-			}
-
-			curBLockHeight, err := c.GetCurrentBlockHeight()
-			if err != nil {
-				return err
-			}
-			fmt.Println("currentBlockHeight: ", curBLockHeight)
-
-			fmt.Println("wait to get allValidator...")
-			allValidator, err := utils.GetValidatorAnnualRate(c, curBLockHeight)
-			if err != nil {
-				return err
-			}
-			fmt.Println("wait to get averageAnnualRate...")
-			averageAnnualRate, err := utils.GetAverageAnnualRate(c, curBLockHeight, allValidator)
-			if err != nil {
-				return err
-			}
-
-			val := allValidator[valAddr]
-			fmt.Println("total validators: ", len(allValidator))
-			fmt.Println("average annual rate: ", averageAnnualRate.String())
-			fmt.Printf("valAddress: %s annualRate: %s commission: %s tokenAmount: %s\n", val.OperatorAddress, val.AnnualRate, val.Commission, val.TokenAmount)
-
-			return nil
-		},
+// evaluateValidator queries slashes and signing info of val, skipReason is set if it's
+// slashed, jailed, tombstoned or missed blocks excessively
+func evaluateValidator(c *client.Client, val *utils.Validator, height, maxMissedBlocks int64) (validatorRecord, error) {
+	record := validatorRecord{
+		Address:    val.OperatorAddress,
+		Moniker:    val.Moniker,
+		AnnualRate: val.AnnualRate.String(),
+		Commission: val.Commission.String(),
+		Tokens:     val.TokenAmount.String(),
 	}
+	valAddress, err := sdk.ValAddressFromBech32(val.OperatorAddress)
+	if err != nil {
+		return record, err
+	}
+	slashFromHeight := height - utils.SlashDuBlock
+	if slashFromHeight < 1 {
+		slashFromHeight = 1
+	}
+	slashRes, err := c.QueryValidatorSlashes(valAddress, slashFromHeight, height)
+	if err != nil {
+		return record, err
+	}
+	record.Slashes = slashRes.Pagination.Total
 
-	cmd.Flags().String(flagNode, "http://localhost:26657", "Node rpc endpoint")
-	cmd.Flags().String(flagPrefix, "cosmos", "Account prefix (comos|stafi|iaa)")
+	validatorRes, err := c.QueryValidator(val.OperatorAddress, height)
+	if err != nil {
+		return record, err
+	}
+	consAddrStr, err := utils.GetValidatorConsAddress(c, validatorRes.Validator)
+	if err != nil {
+		return record, err
+	}
+	signInfo, err := c.QuerySigningInfo(consAddrStr, height)
+	if err != nil {
+		return record, err
+	}
+	record.MissedBlocks = signInfo.ValSigningInfo.MissedBlocksCounter
 
-	return cmd
+	reasons := make([]string, 0)
+	if record.Slashes > utils.MaxSlashAmount {
+		reasons = append(reasons, fmt.Sprintf("slash total %d", record.Slashes))
+	}
+	if validatorRes.Validator.Jailed {
+		reasons = append(reasons, "jailed")
+	}
+	if signInfo.ValSigningInfo.Tombstoned {
+		reasons = append(reasons, "tombstoned")
+	}
+	if record.MissedBlocks > maxMissedBlocks {
+		reasons = append(reasons, fmt.Sprintf("missed %d blocks", record.MissedBlocks))
+	}
+	record.SkipReason = strings.Join(reasons, "; ")
+	return record, nil
 }
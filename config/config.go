@@ -6,34 +6,169 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
+	"strconv"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stafihub/staking-election/utils"
 )
 
+// Config is shared by start-election and start-api, sections of the other process are ignored
 type Config struct {
-	KeystorePath         string
-	ElectorAccount       string
 	StafiHubEndpointList []string
-	GasPrice             string
-	ListenAddr           string
+	Defaults             RTokenParams // inherited by rTokenInfo entries which don't set them
 	RTokenInfo           []RTokenInfo
 
-	Db Db
+	Db       Db
+	Election Election
+	Api      Api
+
+	overrides []Override // keys set from the environment
+}
+
+type Election struct {
+	KeystorePath   string
+	ElectorAccount string
+	GasPrice       string
+}
+
+type Api struct {
+	ListenAddr     string
+	GrpcListenAddr string `toml:",omitempty"` // grpc api is served if it's set
+	// max seconds to drain in-flight requests and finish the current update on stop, 15 if not set
+	ShutdownTimeoutSeconds int64 `toml:",omitempty"`
+
+	Price     Price
+	Logo      Logo
+	RateLimit RateLimit
+	Admin     Admin
+	Event     Event
+	Tls       Tls
+	Cors      Cors
+	Retention Retention
 }
 
 type Db struct {
-	Host string
-	Port string
-	User string
-	Pwd  string
-	Name string
+	Driver  string `toml:",omitempty"` // mysql(default), postgres or sqlite
+	SslMode string `toml:",omitempty"` // postgres sslmode, disable if not set
+	Host    string
+	Port    string
+	User    string
+	Pwd     string `secret:"true"`
+	Name    string
+}
+
+type Price struct {
+	CoinGeckoUrl    string // url with one %s for coin ids
+	CoinMarketUrl   string // url with one %s for symbols
+	CacheSeconds    int64
+	MaxStaleSeconds int64
+}
+
+type Logo struct {
+	DefaultUrl     string // used when no logo is found
+	KeybaseUrl     string `toml:",omitempty"` // keybase lookup url with one %s for identity
+	RefreshSeconds int64  `toml:",omitempty"`
+}
+
+type RateLimit struct {
+	Enable            bool
+	TrustedProxies    []string // cidrs or ips of proxies whose forwarded headers are trusted by ClientIP
+	IpRatePerMinute   int64    // requests per minute of each ip, 0 means no limit
+	IpBurst           int
+	VisitorTtlSeconds int64 // ip limiters unused for ttl are evicted
+	MaxVisitors       int   // least recently used ip limiters are evicted beyond this
+	RouteLimitList    []RouteLimit
+}
+
+type RouteLimit struct {
+	Path          string // route path, such as /stakingElection/api/v1/validator
+	RatePerMinute int64  // requests per minute of all ips
+	Burst         int
+}
+
+type Admin struct {
+	Enable             bool
+	Token              string `secret:"true"` // requests with header "Authorization: Bearer <Token>" are accepted if it's set
+	HmacSecret         string `secret:"true"` // requests signed with hmac-sha256 of this secret are accepted if it's set
+	HmacMaxSkewSeconds int64  // max difference between signed timestamp and now, 300 if not set
+}
+
+type Cors struct {
+	AllowOrigins     []string // origins or wildcard patterns such as https://*.stafihub.io, all origins if empty or has "*"
+	AllowMethods     []string // POST, GET, OPTIONS if empty
+	AllowHeaders     []string
+	ExposeHeaders    []string
+	AllowCredentials bool // can't be used with all origins allowed
+	MaxAgeSeconds    int64
+}
+
+// AllowAllOrigins reports whether any origin is allowed
+func (c Cors) AllowAllOrigins() bool {
+	return len(c.AllowOrigins) == 0 || contains(c.AllowOrigins, "*")
+}
+
+// OriginAllowed reports whether origin matches AllowOrigins, it is shared by cors and websocket
+func (c Cors) OriginAllowed(origin string) bool {
+	if c.AllowAllOrigins() {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	for _, allowOrigin := range c.AllowOrigins {
+		pattern := strings.ToLower(strings.TrimSuffix(allowOrigin, "/"))
+		if matched, _ := path.Match(pattern, origin); matched {
+			return true
+		}
+	}
+	return false
+}
+
+type Tls struct {
+	CertFile           string // api is served over https if both files are set
+	KeyFile            string
+	ReloadCheckSeconds int64 // files are checked for change every ReloadCheckSeconds, 60 if not set
+}
+
+type Event struct {
+	Enable           bool
+	HeartbeatSeconds int64 // heartbeat interval of streams, 15 if not set
+	MaxSubscribers   int   // 1000 if not set
+	BufferSize       int   // events buffered for each subscriber, slower ones are dropped, 16 if not set
+}
+
+type Retention struct {
+	Enable          bool
+	IntervalSeconds int64 // prune interval, 3600 if not set
+	RuleList        []RetentionRule
+}
+
+type RetentionRule struct {
+	Table               string // election_record, redelegation, selected_validator_history or admin_audit
+	TtlDays             int64  // rows older than TtlDays are deleted, 0 keeps them forever
+	DownsampleAfterDays int64  // election_record only, keep decisions older than it are thinned to one per pool per day
+}
+
+// RTokenParams are election parameters of a rToken, unset ones are inherited from [defaults]
+type RTokenParams struct {
+	MaxCommission    *Dec    `toml:",omitempty"` // required by election
+	MaxMissedBlocks  *int64  `toml:",omitempty"` // required by election
+	SlashDuBlock     *int64  `toml:",omitempty"` // slashes within SlashDuBlock blocks are counted, 10000 if not set
+	MaxSlashAmount   *uint64 `toml:",omitempty"` // 0 if not set
+	SampleStepNumber *int64  `toml:",omitempty"` // annual rates of SampleStepNumber heights are averaged, 8 if not set
+	SampleStepSize   *int64  `toml:",omitempty"` // sampled heights are SampleStepSize blocks apart, 10000 if not set
 }
+
 type RTokenInfo struct {
-	Denom           string
-	MaxCommission   *Dec  `toml:",omitempty"`
-	MaxMissedBlocks int64 `toml:",omitempty"`
-	EndpointList    []string
+	Denom        string
+	EndpointList []string
+	RTokenParams
+	// api only
+	CoinGeckoId      string `toml:",omitempty"`
+	CoinMarketSymbol string `toml:",omitempty"`
+	Decimals         int64  `toml:",omitempty"` // decimals of native token, 6 if not set
+	LogoUrlTemplate  string `toml:",omitempty"` // logo url with {address} placeholder
 }
 
 func Load(configFilePath string) (*Config, error) {
@@ -41,6 +176,12 @@ func Load(configFilePath string) (*Config, error) {
 	if err := loadSysConfig(configFilePath, &cfg); err != nil {
 		return nil, err
 	}
+	overrides, err := applyEnv(&cfg, os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	cfg.overrides = overrides
+	cfg.inheritDefaults()
 
 	return &cfg, nil
 }
@@ -50,13 +191,68 @@ func loadSysConfig(path string, config *Config) error {
 	if err != nil {
 		return err
 	}
-	if _, err := toml.DecodeFile(path, config); err != nil {
+	md, err := toml.DecodeFile(path, config)
+	if err != nil {
 		return err
 	}
-	fmt.Println("load config success")
+	if isFlat(md) {
+		flat := flatConfig{}
+		if _, err := toml.DecodeFile(path, &flat); err != nil {
+			return err
+		}
+		*config = flat.convert()
+		fmt.Fprintln(os.Stderr, "config is in the flat format, run `config migrate` to convert it")
+	}
+	fmt.Fprintln(os.Stderr, "load config success")
 	return nil
 }
 
+// inheritDefaults fills unset params of each rToken with [defaults], then built-in defaults.
+// MaxCommission and MaxMissedBlocks have no built-in default and are left nil if not set.
+func (cfg *Config) inheritDefaults() {
+	slashDuBlock := utils.SlashDuBlock
+	maxSlashAmount := utils.MaxSlashAmount
+	stepNumber := utils.DefaultSampling.StepNumber
+	stepSize := utils.DefaultSampling.StepSize
+	builtin := RTokenParams{
+		SlashDuBlock:     &slashDuBlock,
+		MaxSlashAmount:   &maxSlashAmount,
+		SampleStepNumber: &stepNumber,
+		SampleStepSize:   &stepSize,
+	}
+	for i := range cfg.RTokenInfo {
+		params := &cfg.RTokenInfo[i].RTokenParams
+		params.inherit(cfg.Defaults)
+		params.inherit(builtin)
+	}
+}
+
+func (p *RTokenParams) inherit(from RTokenParams) {
+	if p.MaxCommission == nil {
+		p.MaxCommission = from.MaxCommission
+	}
+	if p.MaxMissedBlocks == nil {
+		p.MaxMissedBlocks = from.MaxMissedBlocks
+	}
+	if p.SlashDuBlock == nil {
+		p.SlashDuBlock = from.SlashDuBlock
+	}
+	if p.MaxSlashAmount == nil {
+		p.MaxSlashAmount = from.MaxSlashAmount
+	}
+	if p.SampleStepNumber == nil {
+		p.SampleStepNumber = from.SampleStepNumber
+	}
+	if p.SampleStepSize == nil {
+		p.SampleStepSize = from.SampleStepSize
+	}
+}
+
+// Sampling returns sampling parameters of annual rates, it should be called on a loaded config
+func (r RTokenInfo) Sampling() utils.Sampling {
+	return utils.Sampling{StepNumber: *r.SampleStepNumber, StepSize: *r.SampleStepSize}
+}
+
 type Dec struct {
 	sdk.Dec
 }
@@ -74,3 +270,15 @@ func (d *Dec) UnmarshalTOML(v interface{}) error {
 	d.Dec = dec
 	return nil
 }
+
+func (d Dec) MarshalTOML() ([]byte, error) {
+	if d.Dec.IsNil() {
+		return []byte(`""`), nil
+	}
+	// 0.100000000000000000 is written as 0.1
+	str := d.Dec.String()
+	if strings.Contains(str, ".") {
+		str = strings.TrimRight(strings.TrimRight(str, "0"), ".")
+	}
+	return []byte(strconv.Quote(str)), nil
+}
@@ -0,0 +1,211 @@
+// Copyright 2021 stafiprotocol
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	EnvPrefix     = "STAKING_ELECTION"
+	EnvFileSuffix = "_FILE"
+
+	redactedValue = "******"
+)
+
+// Override records a config key set from the environment
+type Override struct {
+	Key    string // such as db.pwd or rTokenInfo.0.endpointList
+	Source string // such as env STAKING_ELECTION_DB_PWD
+}
+
+// applyEnv overrides config keys by environment variables, precedence from high to low:
+//  1. STAKING_ELECTION_<KEY>_FILE, content of the file, trailing newlines are trimmed
+//  2. STAKING_ELECTION_<KEY>
+//  3. value in config file
+//
+// <KEY> is the upper snake case of the key path, such as DB_PWD for db.pwd and
+// RTOKEN_INFO_0_ENDPOINT_LIST for the first rTokenInfo's endpointList, lists are comma separated.
+// [defaults] is inherited after overrides, so DEFAULTS_MAX_COMMISSION applies to every rToken which doesn't set it.
+// Elements of table lists can be overridden but not added.
+func applyEnv(cfg *Config, lookup func(string) (string, bool)) ([]Override, error) {
+	overrides := make([]Override, 0)
+	err := walkFields(reflect.ValueOf(cfg).Elem(), EnvPrefix, "", func(v reflect.Value, envKey, key string, _ bool) error {
+		value, source, exist, err := lookupEnv(lookup, envKey)
+		if err != nil || !exist {
+			return err
+		}
+		err = setField(v, value)
+		if err != nil {
+			return fmt.Errorf("%s err: %s", source, err)
+		}
+		overrides = append(overrides, Override{Key: key, Source: source})
+		return nil
+	})
+	return overrides, err
+}
+
+func lookupEnv(lookup func(string) (string, bool), envKey string) (value, source string, exist bool, err error) {
+	fileEnvKey := envKey + EnvFileSuffix
+	path, fileExist := lookup(fileEnvKey)
+	value, exist = lookup(envKey)
+	if fileExist && exist {
+		return "", "", false, fmt.Errorf("%s and %s can't be both set", envKey, fileEnvKey)
+	}
+	if exist {
+		return value, "env " + envKey, true, nil
+	}
+	if !fileExist {
+		return "", "", false, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", false, fmt.Errorf("%s read err: %s", fileEnvKey, err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), "file of env " + fileEnvKey, true, nil
+}
+
+// walkFields calls fn with every leaf field of struct v, secret fields are tagged with `secret:"true"`
+func walkFields(v reflect.Value, envPrefix, keyPrefix string, fn func(v reflect.Value, envKey, key string, secret bool) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		envKey := envPrefix + "_" + envName(field.Name)
+		key := tomlName(field.Name)
+		if len(keyPrefix) != 0 {
+			key = keyPrefix + "." + key
+		}
+		fv := v.Field(i)
+
+		switch {
+		case field.Anonymous && fv.Kind() == reflect.Struct:
+			// fields of embedded structs are keys of the embedding table
+			if err := walkFields(fv, envPrefix, keyPrefix, fn); err != nil {
+				return err
+			}
+		case fv.Kind() == reflect.Struct:
+			if err := walkFields(fv, envKey, key, fn); err != nil {
+				return err
+			}
+		case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.Struct:
+			for j := 0; j < fv.Len(); j++ {
+				if err := walkFields(fv.Index(j), fmt.Sprintf("%s_%d", envKey, j), fmt.Sprintf("%s.%d", key, j), fn); err != nil {
+					return err
+				}
+			}
+		default:
+			if err := fn(fv, envKey, key, field.Tag.Get("secret") == "true"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func setField(v reflect.Value, value string) error {
+	if v.Type() == reflect.TypeOf(&Dec{}) {
+		dec := &Dec{}
+		if err := dec.UnmarshalTOML(value); err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(dec))
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		elem := reflect.New(v.Type().Elem())
+		if err := setField(elem.Elem(), value); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("type %s not support", v.Type())
+		}
+		list := make([]string, 0)
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); len(item) != 0 {
+				list = append(list, item)
+			}
+		}
+		v.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("type %s not support", v.Type())
+	}
+	return nil
+}
+
+// Redacted returns a copy of cfg with secret fields masked
+func (cfg *Config) Redacted() *Config {
+	redacted := *cfg
+	// copy slices of tables, or masking would change cfg
+	redacted.RTokenInfo = append([]RTokenInfo(nil), cfg.RTokenInfo...)
+	redacted.Api.RateLimit.RouteLimitList = append([]RouteLimit(nil), cfg.Api.RateLimit.RouteLimitList...)
+	redacted.Api.Retention.RuleList = append([]RetentionRule(nil), cfg.Api.Retention.RuleList...)
+
+	// walkFields only fails if fn does
+	_ = walkFields(reflect.ValueOf(&redacted).Elem(), EnvPrefix, "", func(v reflect.Value, _, _ string, secret bool) error {
+		if secret && v.Kind() == reflect.String && v.Len() != 0 {
+			v.SetString(redactedValue)
+		}
+		return nil
+	})
+	return &redacted
+}
+
+// Overrides returns config keys set from the environment by Load
+func (cfg *Config) Overrides() []Override {
+	return cfg.overrides
+}
+
+// envName converts a field name to upper snake case, RTokenInfo to RTOKEN_INFO
+func envName(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// tomlName converts a field name to the key used in config files, RTokenInfo to rTokenInfo
+func tomlName(name string) string {
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func mapLookup(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, exist := env[key]
+		return value, exist
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestApplyEnvPrecedence(t *testing.T) {
+	secretFile := writeFile(t, "pwd", "from-file\r\n")
+
+	tests := []struct {
+		name       string
+		env        map[string]string
+		wantPwd    string
+		wantSource string // empty if db.pwd is not overridden
+		wantErr    string
+	}{
+		{
+			name:    "config file value without env",
+			env:     map[string]string{},
+			wantPwd: "from-config",
+		},
+		{
+			name:       "env overrides config file",
+			env:        map[string]string{"STAKING_ELECTION_DB_PWD": "from-env"},
+			wantPwd:    "from-env",
+			wantSource: "env STAKING_ELECTION_DB_PWD",
+		},
+		{
+			name:       "file of env overrides config file, trailing newline trimmed",
+			env:        map[string]string{"STAKING_ELECTION_DB_PWD_FILE": secretFile},
+			wantPwd:    "from-file",
+			wantSource: "file of env STAKING_ELECTION_DB_PWD_FILE",
+		},
+		{
+			name:       "empty env still overrides",
+			env:        map[string]string{"STAKING_ELECTION_DB_PWD": ""},
+			wantPwd:    "",
+			wantSource: "env STAKING_ELECTION_DB_PWD",
+		},
+		{
+			name:    "env and file of env both set",
+			env:     map[string]string{"STAKING_ELECTION_DB_PWD": "from-env", "STAKING_ELECTION_DB_PWD_FILE": secretFile},
+			wantErr: "can't be both set",
+		},
+		{
+			name:    "missing file",
+			env:     map[string]string{"STAKING_ELECTION_DB_PWD_FILE": filepath.Join(t.TempDir(), "none")},
+			wantErr: "STAKING_ELECTION_DB_PWD_FILE read err",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Db: Db{Pwd: "from-config"}}
+			overrides, err := applyEnv(cfg, mapLookup(tt.env))
+			if len(tt.wantErr) != 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("applyEnv err %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyEnv err: %s", err)
+			}
+			if cfg.Db.Pwd != tt.wantPwd {
+				t.Errorf("db.pwd %q, want %q", cfg.Db.Pwd, tt.wantPwd)
+			}
+			if len(tt.wantSource) == 0 {
+				if len(overrides) != 0 {
+					t.Errorf("overrides %+v, want none", overrides)
+				}
+				return
+			}
+			if len(overrides) != 1 || overrides[0].Key != "db.pwd" || overrides[0].Source != tt.wantSource {
+				t.Errorf("overrides %+v, want db.pwd from %s", overrides, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestApplyEnvKeys(t *testing.T) {
+	cfg := &Config{
+		RTokenInfo: []RTokenInfo{{Denom: "uratom"}, {Denom: "uriris", RTokenParams: RTokenParams{MaxCommission: decPtr("0.05")}}},
+	}
+	env := map[string]string{
+		"STAKING_ELECTION_STAFI_HUB_ENDPOINT_LIST":        "https://a.io, https://b.io,",
+		"STAKING_ELECTION_RTOKEN_INFO_0_ENDPOINT_LIST":    "https://c.io",
+		"STAKING_ELECTION_RTOKEN_INFO_1_SLASH_DU_BLOCK":   "500",
+		"STAKING_ELECTION_DEFAULTS_MAX_COMMISSION":        "0.1",
+		"STAKING_ELECTION_API_RATE_LIMIT_ENABLE":          "true",
+		"STAKING_ELECTION_API_RATE_LIMIT_IP_BURST":        "7",
+		"STAKING_ELECTION_RTOKEN_INFO_2_ENDPOINT_LIST":    "https://not-added.io",
+		"STAKING_ELECTION_API_ADMIN_HMAC_MAX_SKEW_SECOND": "typo is ignored",
+	}
+	overrides, err := applyEnv(cfg, mapLookup(env))
+	if err != nil {
+		t.Fatalf("applyEnv err: %s", err)
+	}
+	cfg.inheritDefaults()
+
+	if got := strings.Join(cfg.StafiHubEndpointList, " "); got != "https://a.io https://b.io" {
+		t.Errorf("stafiHubEndpointList %q", got)
+	}
+	if got := strings.Join(cfg.RTokenInfo[0].EndpointList, " "); got != "https://c.io" {
+		t.Errorf("rTokenInfo.0.endpointList %q", got)
+	}
+	if *cfg.RTokenInfo[1].SlashDuBlock != 500 || *cfg.RTokenInfo[0].SlashDuBlock != 10000 {
+		t.Errorf("slashDuBlock %d %d", *cfg.RTokenInfo[0].SlashDuBlock, *cfg.RTokenInfo[1].SlashDuBlock)
+	}
+	// defaults are inherited after overrides, a rToken's own value is kept
+	if cfg.RTokenInfo[0].MaxCommission.String() != "0.100000000000000000" || cfg.RTokenInfo[1].MaxCommission.String() != "0.050000000000000000" {
+		t.Errorf("maxCommission %s %s", cfg.RTokenInfo[0].MaxCommission.Dec, cfg.RTokenInfo[1].MaxCommission.Dec)
+	}
+	if !cfg.Api.RateLimit.Enable || cfg.Api.RateLimit.IpBurst != 7 {
+		t.Errorf("rateLimit %+v", cfg.Api.RateLimit)
+	}
+	if len(cfg.RTokenInfo) != 2 {
+		t.Errorf("%d rTokenInfo, elements should not be added", len(cfg.RTokenInfo))
+	}
+	if len(overrides) != 6 {
+		t.Errorf("overrides %+v, want 6", overrides)
+	}
+
+	for _, bad := range []map[string]string{
+		{"STAKING_ELECTION_API_RATE_LIMIT_ENABLE": "yes please"},
+		{"STAKING_ELECTION_API_RATE_LIMIT_IP_BURST": "many"},
+		{"STAKING_ELECTION_DEFAULTS_MAX_COMMISSION": "ten percent"},
+	} {
+		if _, err := applyEnv(&Config{}, mapLookup(bad)); err == nil {
+			t.Errorf("applyEnv of %v want err", bad)
+		}
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := &Config{
+		Db:  Db{User: "root", Pwd: "secret"},
+		Api: Api{Admin: Admin{Token: "token"}},
+	}
+	redacted := cfg.Redacted()
+	if redacted.Db.Pwd != redactedValue || redacted.Api.Admin.Token != redactedValue {
+		t.Errorf("secrets not redacted: %+v %+v", redacted.Db, redacted.Api.Admin)
+	}
+	if redacted.Db.User != "root" || len(redacted.Api.Admin.HmacSecret) != 0 {
+		t.Errorf("non secret or empty fields changed: %+v %+v", redacted.Db, redacted.Api.Admin)
+	}
+	if cfg.Db.Pwd != "secret" || cfg.Api.Admin.Token != "token" {
+		t.Errorf("original config changed: %+v %+v", cfg.Db, cfg.Api.Admin)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	tests := map[string]string{
+		"RTokenInfo":           "RTOKEN_INFO",
+		"StafiHubEndpointList": "STAFI_HUB_ENDPOINT_LIST",
+		"IpRatePerMinute":      "IP_RATE_PER_MINUTE",
+		"Pwd":                  "PWD",
+		"SslMode":              "SSL_MODE",
+	}
+	for name, want := range tests {
+		if got := envName(name); got != want {
+			t.Errorf("envName(%s) %s, want %s", name, got, want)
+		}
+	}
+}
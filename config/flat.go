@@ -0,0 +1,167 @@
+// Copyright 2021 stafiprotocol
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// flatConfig is the format before keys were split into [election] and [api]
+type flatConfig struct {
+	Election
+	Api
+	StafiHubEndpointList []string
+	RTokenInfo           []RTokenInfo
+	Db                   Db
+}
+
+func (f flatConfig) convert() Config {
+	return Config{
+		StafiHubEndpointList: f.StafiHubEndpointList,
+		RTokenInfo:           f.RTokenInfo,
+		Db:                   f.Db,
+		Election:             f.Election,
+		Api:                  f.Api,
+	}
+}
+
+// isFlat tells if a decoded file has keys of [election] or [api] at the top level
+func isFlat(md toml.MetaData) bool {
+	if md.IsDefined("election") || md.IsDefined("api") {
+		return false
+	}
+	sectionKeys := make(map[string]bool)
+	for _, t := range []reflect.Type{reflect.TypeOf(Election{}), reflect.TypeOf(Api{})} {
+		for i := 0; i < t.NumField(); i++ {
+			sectionKeys[strings.ToLower(t.Field(i).Name)] = true
+		}
+	}
+	for _, key := range md.Undecoded() {
+		if sectionKeys[strings.ToLower(key[0])] {
+			return true
+		}
+	}
+	return false
+}
+
+// Migrate converts a flat format file to the sectioned one, parameters shared by all rTokens
+// are moved to [defaults]. Environment overrides are not applied, so secrets stay where they were.
+func Migrate(path string) (*Config, error) {
+	cfg := Config{}
+	md, err := toml.DecodeFile(path, &cfg)
+	if err != nil {
+		return nil, err
+	}
+	if !isFlat(md) {
+		return nil, fmt.Errorf("config %s is not in the flat format", path)
+	}
+	flat := flatConfig{}
+	if _, err := toml.DecodeFile(path, &flat); err != nil {
+		return nil, err
+	}
+	cfg = flat.convert()
+
+	if len(cfg.RTokenInfo) > 1 {
+		cfg.hoistSharedParams()
+	}
+	return &cfg, nil
+}
+
+// hoistSharedParams moves MaxCommission and MaxMissedBlocks set to one value by all rTokens to [defaults]
+func (cfg *Config) hoistSharedParams() {
+	shared := true
+	first := cfg.RTokenInfo[0].MaxCommission
+	for _, rtokenInfo := range cfg.RTokenInfo {
+		if first == nil || rtokenInfo.MaxCommission == nil || !rtokenInfo.MaxCommission.Equal(first.Dec) {
+			shared = false
+			break
+		}
+	}
+	if shared {
+		cfg.Defaults.MaxCommission = first
+		for i := range cfg.RTokenInfo {
+			cfg.RTokenInfo[i].MaxCommission = nil
+		}
+	}
+
+	shared = true
+	firstMissed := cfg.RTokenInfo[0].MaxMissedBlocks
+	for _, rtokenInfo := range cfg.RTokenInfo {
+		if firstMissed == nil || rtokenInfo.MaxMissedBlocks == nil || *rtokenInfo.MaxMissedBlocks != *firstMissed {
+			shared = false
+			break
+		}
+	}
+	if shared {
+		cfg.Defaults.MaxMissedBlocks = firstMissed
+		for i := range cfg.RTokenInfo {
+			cfg.RTokenInfo[i].MaxMissedBlocks = nil
+		}
+	}
+}
+
+// Encode writes cfg in the key names of config files, zero values and empty tables are omitted
+func Encode(w io.Writer, cfg *Config) error {
+	return toml.NewEncoder(w).Encode(tomlValue(reflect.ValueOf(cfg).Elem()))
+}
+
+// tomlValue converts structs to maps keyed by config file key names, the encoder
+// uses field names of structs which differ from the documented camel case keys
+func tomlValue(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		if _, ok := v.Interface().(toml.Marshaler); ok {
+			return v.Interface()
+		}
+		return tomlValue(v.Elem())
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		m := make(map[string]interface{})
+		tomlFields(v, m)
+		return m
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Struct {
+			return v.Interface()
+		}
+		list := make([]map[string]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			list = append(list, tomlValue(v.Index(i)).(map[string]interface{}))
+		}
+		return list
+	default:
+		return v.Interface()
+	}
+}
+
+func tomlFields(v reflect.Value, m map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		if field.Anonymous && fv.Kind() == reflect.Struct {
+			tomlFields(fv, m)
+			continue
+		}
+		if fv.IsZero() {
+			continue
+		}
+		value := tomlValue(fv)
+		if table, ok := value.(map[string]interface{}); value == nil || ok && len(table) == 0 {
+			continue
+		}
+		m[tomlName(field.Name)] = value
+	}
+}
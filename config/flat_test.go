@@ -0,0 +1,162 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+const flatConfigFile = `
+listenAddr = ":8083"
+electorAccount = "relay1"
+gasPrice = "0.05ufis"
+keystorePath = "./keys/stafihub"
+stafiHubEndpointList = ["https://rpc.stafihub.io:443"]
+
+[db]
+host = "127.0.0.1"
+name = "station"
+pwd = "123456"
+
+[[rTokenInfo]]
+denom = "uratom"
+endpointList = ["https://rpc.cosmos.io:443"]
+maxCommission = "0.1"
+maxMissedBlocks = 100
+
+[[rTokenInfo]]
+denom = "uriris"
+endpointList = ["https://rpc.iris.io:443"]
+maxCommission = "0.1"
+maxMissedBlocks = 200
+`
+
+const sectionedConfigFile = `
+stafiHubEndpointList = ["https://rpc.stafihub.io:443"]
+
+[defaults]
+maxCommission = "0.1"
+
+[election]
+electorAccount = "relay1"
+
+[[rTokenInfo]]
+denom = "uratom"
+endpointList = ["https://rpc.cosmos.io:443"]
+maxCommission = "0.08"
+`
+
+func TestLoadFlatAndSectioned(t *testing.T) {
+	tests := []struct {
+		name              string
+		content           string
+		wantListenAddr    string
+		wantElector       string
+		wantMaxCommission []string
+	}{
+		{
+			name:              "flat keys are moved to sections",
+			content:           flatConfigFile,
+			wantListenAddr:    ":8083",
+			wantElector:       "relay1",
+			wantMaxCommission: []string{"0.100000000000000000", "0.100000000000000000"},
+		},
+		{
+			name:              "sectioned with defaults",
+			content:           sectionedConfigFile,
+			wantElector:       "relay1",
+			wantMaxCommission: []string{"0.080000000000000000"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load(writeFile(t, "conf.toml", tt.content))
+			if err != nil {
+				t.Fatalf("Load err: %s", err)
+			}
+			if cfg.Api.ListenAddr != tt.wantListenAddr || cfg.Election.ElectorAccount != tt.wantElector {
+				t.Errorf("listenAddr %q electorAccount %q", cfg.Api.ListenAddr, cfg.Election.ElectorAccount)
+			}
+			if len(cfg.RTokenInfo) != len(tt.wantMaxCommission) {
+				t.Fatalf("%d rTokenInfo, want %d", len(cfg.RTokenInfo), len(tt.wantMaxCommission))
+			}
+			for i, want := range tt.wantMaxCommission {
+				if got := cfg.RTokenInfo[i].MaxCommission.String(); got != want {
+					t.Errorf("maxCommission of %s %s, want %s", cfg.RTokenInfo[i].Denom, got, want)
+				}
+				if cfg.RTokenInfo[i].SlashDuBlock == nil || *cfg.RTokenInfo[i].SlashDuBlock != 10000 {
+					t.Errorf("slashDuBlock of %s not inherited", cfg.RTokenInfo[i].Denom)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	if _, err := Migrate(writeFile(t, "conf.toml", sectionedConfigFile)); err == nil {
+		t.Fatal("Migrate of a sectioned config want err")
+	}
+
+	path := writeFile(t, "conf.toml", flatConfigFile)
+	os.Setenv("STAKING_ELECTION_DB_PWD", "from-env")
+	defer os.Unsetenv("STAKING_ELECTION_DB_PWD")
+	cfg, err := Migrate(path)
+	if err != nil {
+		t.Fatalf("Migrate err: %s", err)
+	}
+
+	// maxCommission shared by all rTokens is moved to defaults, maxMissedBlocks differs
+	if cfg.Defaults.MaxCommission == nil || cfg.Defaults.MaxCommission.String() != "0.100000000000000000" {
+		t.Errorf("defaults.maxCommission %v", cfg.Defaults.MaxCommission)
+	}
+	if cfg.Defaults.MaxMissedBlocks != nil {
+		t.Errorf("defaults.maxMissedBlocks %d, want unset", *cfg.Defaults.MaxMissedBlocks)
+	}
+	for _, rtokenInfo := range cfg.RTokenInfo {
+		if rtokenInfo.MaxCommission != nil || rtokenInfo.MaxMissedBlocks == nil {
+			t.Errorf("params of %s %+v", rtokenInfo.Denom, rtokenInfo.RTokenParams)
+		}
+	}
+	if cfg.Db.Pwd != "123456" {
+		t.Errorf("db.pwd %q, env should not be applied", cfg.Db.Pwd)
+	}
+
+	// the encoded config is sectioned and loads to the same values
+	buf := bytes.Buffer{}
+	if err := Encode(&buf, cfg); err != nil {
+		t.Fatalf("Encode err: %s", err)
+	}
+	encoded := buf.String()
+	for _, want := range []string{"[election]", "[api]", "[defaults]", "electorAccount", "listenAddr"} {
+		if !strings.Contains(encoded, want) {
+			t.Errorf("encoded config has no %s:\n%s", want, encoded)
+		}
+	}
+	migratedPath := writeFile(t, "migrated.toml", encoded)
+	if _, err := Migrate(migratedPath); err == nil {
+		t.Error("Migrate of a migrated config want err")
+	}
+	os.Unsetenv("STAKING_ELECTION_DB_PWD")
+	migrated, err := Load(migratedPath)
+	if err != nil {
+		t.Fatalf("Load migrated err: %s", err)
+	}
+	flat, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load flat err: %s", err)
+	}
+	// effective params of each rToken are the same, only [defaults] is added
+	migrated.Defaults = RTokenParams{}
+	var migratedBuf, flatBuf bytes.Buffer
+	if err := Encode(&migratedBuf, migrated); err != nil {
+		t.Fatal(err)
+	}
+	if err := Encode(&flatBuf, flat); err != nil {
+		t.Fatal(err)
+	}
+	if migratedBuf.String() != flatBuf.String() {
+		t.Errorf("loaded migrated config differs from flat one:\n%s\nwant:\n%s", migratedBuf.String(), flatBuf.String())
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2021 stafiprotocol
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stafihub/staking-election/db"
+)
+
+// Mode is the process a config is used by, fields required by each differ
+type Mode string
+
+const (
+	ModeElection Mode = "election"
+	ModeApi      Mode = "api"
+)
+
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case ModeElection, ModeApi:
+		return Mode(s), nil
+	default:
+		return "", fmt.Errorf("config mode %s not support, should be %s or %s", s, ModeElection, ModeApi)
+	}
+}
+
+// ValidationError holds every problem found in a config
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Add(format string, args ...interface{}) {
+	e.Problems = append(e.Problems, fmt.Sprintf(format, args...))
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("config has %d problem(s):\n  - %s", len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+// OrNil returns nil if no problem is found, so it can be returned as error
+func (e *ValidationError) OrNil() error {
+	if len(e.Problems) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Validate checks fields required by mode, all problems are reported in a *ValidationError
+func (cfg *Config) Validate(mode Mode) error {
+	verr := &ValidationError{}
+
+	if len(cfg.StafiHubEndpointList) == 0 {
+		verr.Add("stafiHubEndpointList is empty")
+	}
+	for i, endpoint := range cfg.StafiHubEndpointList {
+		if len(strings.TrimSpace(endpoint)) == 0 {
+			verr.Add("stafiHubEndpointList[%d] is empty", i)
+		}
+	}
+
+	switch mode {
+	case ModeElection:
+		if len(cfg.Election.ElectorAccount) == 0 {
+			verr.Add("election.electorAccount is empty")
+		}
+		if len(cfg.Election.KeystorePath) == 0 {
+			verr.Add("election.keystorePath is empty")
+		}
+		if _, err := sdk.ParseDecCoins(cfg.Election.GasPrice); err != nil || len(cfg.Election.GasPrice) == 0 {
+			verr.Add("election.gasPrice %q is not a valid coin price, such as 0.05ufis", cfg.Election.GasPrice)
+		}
+		// db is optional for election, start-election warns that admin api changes are ignored without it
+		if len(cfg.Db.Host) != 0 || cfg.Db.Driver == db.DriverSqlite {
+			cfg.Db.validate(verr)
+		}
+	case ModeApi:
+		if len(cfg.Api.ListenAddr) == 0 {
+			verr.Add("api.listenAddr is empty")
+		}
+		cfg.Db.validate(verr)
+		if len(cfg.Api.Tls.CertFile) == 0 != (len(cfg.Api.Tls.KeyFile) == 0) {
+			verr.Add("api.tls certFile and keyFile should be set together")
+		}
+		if cfg.Api.Admin.Enable && len(cfg.Api.Admin.Token) == 0 && len(cfg.Api.Admin.HmacSecret) == 0 {
+			verr.Add("api.admin is enabled but neither token nor hmacSecret is set")
+		}
+		if cfg.Api.Cors.AllowCredentials && cfg.Api.Cors.AllowAllOrigins() {
+			verr.Add("api.cors allowCredentials can't be used with all origins allowed")
+		}
+	default:
+		verr.Add("config mode %s not support", mode)
+	}
+
+	if len(cfg.RTokenInfo) == 0 {
+		verr.Add("rTokenInfo is empty")
+	}
+	denoms := make(map[string]bool)
+	for i, rtokenInfo := range cfg.RTokenInfo {
+		name := fmt.Sprintf("rTokenInfo[%d]", i)
+		if len(rtokenInfo.Denom) == 0 {
+			verr.Add("%s denom is empty", name)
+		} else {
+			name = fmt.Sprintf("rTokenInfo %s", rtokenInfo.Denom)
+			if denoms[rtokenInfo.Denom] {
+				verr.Add("%s is duplicated", name)
+			}
+			denoms[rtokenInfo.Denom] = true
+		}
+		if len(rtokenInfo.EndpointList) == 0 {
+			verr.Add("%s endpointList is empty", name)
+		}
+		for j, endpoint := range rtokenInfo.EndpointList {
+			if len(strings.TrimSpace(endpoint)) == 0 {
+				verr.Add("%s endpointList[%d] is empty", name, j)
+			}
+		}
+
+		// set by inheritDefaults of a loaded config
+		if rtokenInfo.SlashDuBlock != nil && *rtokenInfo.SlashDuBlock <= 0 {
+			verr.Add("%s slashDuBlock should be greater than 0", name)
+		}
+		if rtokenInfo.SampleStepNumber != nil && *rtokenInfo.SampleStepNumber <= 0 {
+			verr.Add("%s sampleStepNumber should be greater than 0", name)
+		}
+		if rtokenInfo.SampleStepSize != nil && *rtokenInfo.SampleStepSize <= 0 {
+			verr.Add("%s sampleStepSize should be greater than 0", name)
+		}
+
+		switch mode {
+		case ModeElection:
+			if rtokenInfo.MaxCommission == nil || rtokenInfo.MaxCommission.Dec.IsNil() {
+				verr.Add("%s maxCommission is not set in it or [defaults]", name)
+			} else if rtokenInfo.MaxCommission.IsNegative() || rtokenInfo.MaxCommission.GT(sdk.OneDec()) {
+				verr.Add("%s maxCommission %s should be between 0 and 1", name, rtokenInfo.MaxCommission.Dec)
+			}
+			if rtokenInfo.MaxMissedBlocks == nil {
+				verr.Add("%s maxMissedBlocks is not set in it or [defaults]", name)
+			} else if *rtokenInfo.MaxMissedBlocks <= 0 {
+				verr.Add("%s maxMissedBlocks should be greater than 0", name)
+			}
+		case ModeApi:
+			if rtokenInfo.Decimals < 0 {
+				verr.Add("%s decimals can't be negative", name)
+			}
+		}
+	}
+
+	return verr.OrNil()
+}
+
+func (d Db) validate(verr *ValidationError) {
+	switch d.Driver {
+	case "", db.DriverMysql, db.DriverPostgres:
+		if len(d.Host) == 0 {
+			verr.Add("db host is empty")
+		}
+		if len(d.Name) == 0 {
+			verr.Add("db name is empty")
+		}
+	case db.DriverSqlite:
+		if len(d.Name) == 0 {
+			verr.Add("db name should be the sqlite file path or %s", db.SqliteMemory)
+		}
+	default:
+		verr.Add("db driver %s not support, should be %s, %s or %s", d.Driver, db.DriverMysql, db.DriverPostgres, db.DriverSqlite)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
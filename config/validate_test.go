@@ -0,0 +1,182 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func int64Ptr(n int64) *int64 {
+	return &n
+}
+
+func decPtr(s string) *Dec {
+	return &Dec{sdk.MustNewDecFromStr(s)}
+}
+
+// validConfig passes Validate of both modes
+func validConfig() *Config {
+	cfg := &Config{
+		StafiHubEndpointList: []string{"https://rpc.stafihub.io:443"},
+		Defaults:             RTokenParams{MaxCommission: decPtr("0.1"), MaxMissedBlocks: int64Ptr(100)},
+		RTokenInfo: []RTokenInfo{
+			{Denom: "uratom", EndpointList: []string{"https://rpc.cosmos.io:443"}},
+			{Denom: "uriris", EndpointList: []string{"https://rpc.iris.io:443"}},
+		},
+		Db:       Db{Host: "127.0.0.1", Name: "station"},
+		Election: Election{ElectorAccount: "relay1", KeystorePath: "./keys", GasPrice: "0.05ufis"},
+		Api:      Api{ListenAddr: ":8083"},
+	}
+	cfg.inheritDefaults()
+	return cfg
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     Mode
+		modify   func(cfg *Config)
+		problems []string // each is contained by one problem, none if empty
+	}{
+		{name: "valid election", mode: ModeElection, modify: func(cfg *Config) {}},
+		{name: "valid api", mode: ModeApi, modify: func(cfg *Config) {}},
+		{
+			name:     "unknown mode",
+			mode:     Mode("other"),
+			modify:   func(cfg *Config) {},
+			problems: []string{"config mode other not support"},
+		},
+		{
+			name: "election fields",
+			mode: ModeElection,
+			modify: func(cfg *Config) {
+				cfg.Election = Election{GasPrice: "fis"}
+				cfg.StafiHubEndpointList = []string{" "}
+			},
+			problems: []string{"electorAccount is empty", "keystorePath is empty", `gasPrice "fis"`, "stafiHubEndpointList[0] is empty"},
+		},
+		{
+			name:   "election fields are not required by api",
+			mode:   ModeApi,
+			modify: func(cfg *Config) { cfg.Election = Election{} },
+		},
+		{
+			name:   "election db is optional",
+			mode:   ModeElection,
+			modify: func(cfg *Config) { cfg.Db = Db{} },
+		},
+		{
+			name: "api sections are not checked by election",
+			mode: ModeElection,
+			modify: func(cfg *Config) {
+				cfg.Db = Db{}
+				cfg.Api = Api{Admin: Admin{Enable: true}, Cors: Cors{AllowCredentials: true}}
+			},
+		},
+		{
+			name: "election sqlite db is checked",
+			mode: ModeElection,
+			modify: func(cfg *Config) {
+				cfg.Db = Db{Driver: "sqlite"}
+			},
+			problems: []string{"db name should be the sqlite file path or :memory:"},
+		},
+		{
+			name:     "api db",
+			mode:     ModeApi,
+			modify:   func(cfg *Config) { cfg.Db = Db{Driver: "postgres"} },
+			problems: []string{"db host is empty", "db name is empty"},
+		},
+		{
+			name:   "api sqlite db needs no host",
+			mode:   ModeApi,
+			modify: func(cfg *Config) { cfg.Db = Db{Driver: "sqlite", Name: ":memory:"} },
+		},
+		{
+			name:     "api unknown db driver",
+			mode:     ModeApi,
+			modify:   func(cfg *Config) { cfg.Db.Driver = "oracle" },
+			problems: []string{"db driver oracle not support"},
+		},
+		{
+			name: "api fields",
+			mode: ModeApi,
+			modify: func(cfg *Config) {
+				cfg.Api.ListenAddr = ""
+				cfg.Api.Tls.CertFile = "cert.pem"
+				cfg.Api.Admin.Enable = true
+				cfg.Api.Cors = Cors{AllowOrigins: []string{"*"}, AllowCredentials: true}
+			},
+			problems: []string{"listenAddr is empty", "certFile and keyFile", "neither token nor hmacSecret", "allowCredentials"},
+		},
+		{
+			name: "rTokenInfo",
+			mode: ModeApi,
+			modify: func(cfg *Config) {
+				cfg.RTokenInfo[1].Denom = "uratom"
+				cfg.RTokenInfo[1].EndpointList = nil
+				cfg.RTokenInfo[0].Decimals = -1
+				cfg.RTokenInfo[0].SampleStepSize = int64Ptr(0)
+			},
+			problems: []string{"rTokenInfo uratom is duplicated", "rTokenInfo uratom endpointList is empty",
+				"decimals can't be negative", "sampleStepSize should be greater than 0"},
+		},
+		{
+			name:     "empty rTokenInfo",
+			mode:     ModeApi,
+			modify:   func(cfg *Config) { cfg.RTokenInfo = nil },
+			problems: []string{"rTokenInfo is empty"},
+		},
+		{
+			name: "election params not set in rToken or defaults",
+			mode: ModeElection,
+			modify: func(cfg *Config) {
+				cfg.RTokenInfo[0].MaxCommission = nil
+				cfg.RTokenInfo[0].MaxMissedBlocks = nil
+				cfg.RTokenInfo[1].MaxCommission = decPtr("1.5")
+				cfg.RTokenInfo[1].MaxMissedBlocks = int64Ptr(0)
+			},
+			problems: []string{"uratom maxCommission is not set", "uratom maxMissedBlocks is not set",
+				"uriris maxCommission 1.500000000000000000 should be between 0 and 1", "uriris maxMissedBlocks should be greater than 0"},
+		},
+		{
+			name: "election params are not required by api",
+			mode: ModeApi,
+			modify: func(cfg *Config) {
+				cfg.RTokenInfo[0].MaxCommission = nil
+				cfg.RTokenInfo[0].MaxMissedBlocks = nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(cfg)
+			err := cfg.Validate(tt.mode)
+			if len(tt.problems) == 0 {
+				if err != nil {
+					t.Fatalf("Validate err: %s", err)
+				}
+				return
+			}
+			verr, ok := err.(*ValidationError)
+			if !ok {
+				t.Fatalf("Validate err %v is not a *ValidationError", err)
+			}
+			if len(verr.Problems) != len(tt.problems) {
+				t.Errorf("problems %q, want %d", verr.Problems, len(tt.problems))
+			}
+			for _, want := range tt.problems {
+				found := false
+				for _, problem := range verr.Problems {
+					found = found || strings.Contains(problem, want)
+				}
+				if !found {
+					t.Errorf("no problem contains %q in %q", want, verr.Problems)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package dao_election
+
+import "github.com/stafihub/staking-election/db"
+
+const (
+	ListTypeAllow = "allow"
+	ListTypeDeny  = "deny"
+)
+
+// ValidatorListEntry puts a validator into the allow or deny list of denom, when a denom has
+// allow list entries only validators in it can be elected
+type ValidatorListEntry struct {
+	db.BaseModel
+	RTokenDenom      string `gorm:"type:varchar(128) not null;default:'';column:rtoken_denom;uniqueIndex:uni_list_denom_val"`
+	ValidatorAddress string `gorm:"type:varchar(80) not null;default:'';column:validator_address;uniqueIndex:uni_list_denom_val"`
+	ListType         string `gorm:"type:varchar(10) not null;default:'';column:list_type"` // allow/deny
+	Remark           string `gorm:"type:varchar(256) not null;default:'';column:remark"`
+}
+
+func (f ValidatorListEntry) TableName() string {
+	return "staking_election_validator_list"
+}
+
+func UpOrInValidatorListEntry(db *db.WrapDb, c *ValidatorListEntry) error {
+	return db.Save(c).Error
+}
+
+func GetValidatorListEntry(db *db.WrapDb, denom, validatorAddress string) (info *ValidatorListEntry, err error) {
+	info = &ValidatorListEntry{}
+	err = db.Take(info, "rtoken_denom = ? and validator_address = ?", denom, validatorAddress).Error
+	return
+}
+
+func GetValidatorListEntriesByDenom(db *db.WrapDb, denom string) (infos []*ValidatorListEntry, err error) {
+	err = db.Order("list_type asc, validator_address asc").Find(&infos, "rtoken_denom = ?", denom).Error
+	return
+}
+
+func DeleteValidatorListEntry(db *db.WrapDb, denom, validatorAddress string) error {
+	return db.Delete(&ValidatorListEntry{}, "rtoken_denom = ? and validator_address = ?", denom, validatorAddress).Error
+}
+
+// PausedPool stops the election of a pool until it's resumed
+type PausedPool struct {
+	db.BaseModel
+	RTokenDenom string `gorm:"type:varchar(128) not null;default:'';column:rtoken_denom;uniqueIndex:uni_paused_denom_pool"`
+	PoolAddress string `gorm:"type:varchar(80) not null;default:'';column:pool_address;uniqueIndex:uni_paused_denom_pool"`
+	Remark      string `gorm:"type:varchar(256) not null;default:'';column:remark"`
+}
+
+func (f PausedPool) TableName() string {
+	return "staking_election_paused_pool"
+}
+
+func UpOrInPausedPool(db *db.WrapDb, c *PausedPool) error {
+	return db.Save(c).Error
+}
+
+func GetPausedPool(db *db.WrapDb, denom, poolAddress string) (info *PausedPool, err error) {
+	info = &PausedPool{}
+	err = db.Take(info, "rtoken_denom = ? and pool_address = ?", denom, poolAddress).Error
+	return
+}
+
+func GetPausedPoolList(db *db.WrapDb) (infos []*PausedPool, err error) {
+	err = db.Order("rtoken_denom asc, pool_address asc").Find(&infos).Error
+	return
+}
+
+func DeletePausedPool(db *db.WrapDb, denom, poolAddress string) error {
+	return db.Delete(&PausedPool{}, "rtoken_denom = ? and pool_address = ?", denom, poolAddress).Error
+}
+
+// AdminAudit records every action of the admin api
+type AdminAudit struct {
+	db.BaseModel
+	Action   string `gorm:"type:varchar(40) not null;default:'';column:action;index"`
+	Params   string `gorm:"type:text;column:params"`
+	RemoteIp string `gorm:"type:varchar(64) not null;default:'';column:remote_ip"`
+	AuthType string `gorm:"type:varchar(10) not null;default:'';column:auth_type"`
+	Success  bool   `gorm:"not null;default:false;column:success"`
+	Message  string `gorm:"type:varchar(512) not null;default:'';column:message"`
+}
+
+func (f AdminAudit) TableName() string {
+	return "staking_election_admin_audit"
+}
+
+func AddAdminAudit(db *db.WrapDb, c *AdminAudit) error {
+	return db.Create(c).Error
+}
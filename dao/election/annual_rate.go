@@ -4,7 +4,7 @@ import "github.com/stafihub/staking-election/db"
 
 type AnnualRate struct {
 	db.BaseModel
-	RTokenDenom string `gorm:"type:varchar(10) not null;default:'';column:rtoken_denom;uniqueIndex"`
+	RTokenDenom string `gorm:"type:varchar(128) not null;default:'';column:rtoken_denom;uniqueIndex"`
 	AnnualRate  string `gorm:"type:varchar(80) not null;default:'';column:annual_rate"`
 }
 
@@ -0,0 +1,86 @@
+package dao_election
+
+import (
+	"github.com/stafihub/staking-election/db"
+	"gorm.io/gorm"
+)
+
+const (
+	DecisionKeep               = "keep"
+	DecisionRedelegate         = "redelegate"
+	DecisionNotEnoughCandidate = "notEnoughCandidate"
+)
+
+// ElectionRecord records how the election process decided on a pool's cycle
+type ElectionRecord struct {
+	db.BaseModel
+	RTokenDenom  string `gorm:"type:varchar(128) not null;default:'';column:rtoken_denom;uniqueIndex:uni_record_denom_pool_cycle"`
+	PoolAddress  string `gorm:"type:varchar(80) not null;default:'';column:pool_address;uniqueIndex:uni_record_denom_pool_cycle"`
+	CycleVersion uint64 `gorm:"not null;default:0;column:cycle_version;uniqueIndex:uni_record_denom_pool_cycle"`
+	CycleNumber  uint64 `gorm:"not null;default:0;column:cycle_number;uniqueIndex:uni_record_denom_pool_cycle"`
+	Decision     string `gorm:"type:varchar(20) not null;default:'';column:decision"`
+	OldValidator string `gorm:"type:varchar(80) not null;default:'';column:old_validator"`
+	NewValidator string `gorm:"type:varchar(80) not null;default:'';column:new_validator"`
+	Detail       string `gorm:"type:text;column:detail"` // json of ElectionDetail
+}
+
+func (f ElectionRecord) TableName() string {
+	return "staking_election_election_record"
+}
+
+// ElectionDetail is what the election process evaluated on a cycle
+type ElectionDetail struct {
+	Decision        string                `json:"decision"`
+	OldValidator    string                `json:"oldValidator"`
+	NewValidator    string                `json:"newValidator"`
+	NeedShuffle     bool                  `json:"needShuffle"`
+	TargetHeight    int64                 `json:"targetHeight"`
+	SlashFromHeight int64                 `json:"slashFromHeight"`
+	RValidators     []ValidatorEvaluation `json:"rValidators"`
+	Candidates      []ValidatorEvaluation `json:"candidates"`
+}
+
+type ValidatorEvaluation struct {
+	ValidatorAddress string        `json:"validatorAddress"`
+	Rank             int           `json:"rank,omitempty"` // rank of candidate, start from 1
+	AnnualRate       string        `json:"annualRate,omitempty"`
+	TokenAmount      string        `json:"tokenAmount,omitempty"`
+	Commission       string        `json:"commission"`
+	SlashAmount      uint64        `json:"slashAmount"`
+	MissedBlocks     int64         `json:"missedBlocks"`
+	Jailed           bool          `json:"jailed"`
+	Tombstoned       bool          `json:"tombstoned"`
+	Rules            []RuleOutcome `json:"rules"`
+	Passed           bool          `json:"passed"`   // all rules passed
+	Selected         bool          `json:"selected"` // rValidator chosen to rm or candidate chosen to add
+}
+
+type RuleOutcome struct {
+	Rule   string `json:"rule"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail"`
+}
+
+func UpOrInElectionRecord(db *db.WrapDb, c *ElectionRecord) error {
+	return db.Save(c).Error
+}
+
+func GetElectionRecord(db *db.WrapDb, denom, poolAddress string, cycleVersion, cycleNumber uint64) (info *ElectionRecord, err error) {
+	info = &ElectionRecord{}
+	err = db.Take(info, "rtoken_denom = ? and pool_address = ? and cycle_version = ? and cycle_number = ?",
+		denom, poolAddress, cycleVersion, cycleNumber).Error
+	return
+}
+
+// GetLatestElectionRecord returns the latest record of pool, cycleNumber 0 means any cycle
+func GetLatestElectionRecord(db *db.WrapDb, denom, poolAddress string, cycleNumber uint64) (info *ElectionRecord, err error) {
+	info = &ElectionRecord{}
+	err = db.Scopes(func(q *gorm.DB) *gorm.DB {
+		q = q.Where("rtoken_denom = ? and pool_address = ?", denom, poolAddress)
+		if cycleNumber != 0 {
+			q = q.Where("cycle_number = ?", cycleNumber)
+		}
+		return q
+	}).Order("cycle_version desc, cycle_number desc").Take(info).Error
+	return
+}
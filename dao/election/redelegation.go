@@ -0,0 +1,79 @@
+package dao_election
+
+import (
+	"github.com/stafihub/staking-election/db"
+	"gorm.io/gorm"
+)
+
+// Redelegation records an executed rvalidator update of a pool on stafihub
+type Redelegation struct {
+	db.BaseModel
+	RTokenDenom  string `gorm:"type:varchar(128) not null;default:'';column:rtoken_denom;index:idx_redelegation_denom_pool"`
+	PoolAddress  string `gorm:"type:varchar(80) not null;default:'';column:pool_address;index:idx_redelegation_denom_pool;uniqueIndex:uni_tx_pool_old"`
+	OldValidator string `gorm:"type:varchar(80) not null;default:'';column:old_validator;uniqueIndex:uni_tx_pool_old"`
+	NewValidator string `gorm:"type:varchar(80) not null;default:'';column:new_validator"`
+	CycleVersion uint64 `gorm:"not null;default:0;column:cycle_version"`
+	CycleNumber  uint64 `gorm:"not null;default:0;column:cycle_number"`
+	ChainEra     uint32 `gorm:"not null;default:0;column:chain_era"`
+	Height       int64  `gorm:"not null;default:0;column:height"` // stafihub block height
+	TxHash       string `gorm:"type:varchar(80) not null;default:'';column:tx_hash;uniqueIndex:uni_tx_pool_old"`
+}
+
+func (f Redelegation) TableName() string {
+	return "staking_election_redelegation"
+}
+
+func UpOrInRedelegation(db *db.WrapDb, c *Redelegation) error {
+	return db.Save(c).Error
+}
+
+func GetRedelegation(db *db.WrapDb, txHash, poolAddress, oldValidator string) (info *Redelegation, err error) {
+	info = &Redelegation{}
+	err = db.Take(info, "tx_hash = ? and pool_address = ? and old_validator = ?", txHash, poolAddress, oldValidator).Error
+	return
+}
+
+// RedelegationCursor is the stafihub height up to which redelegations of a denom are indexed,
+// it is kept apart from redelegations as they can be pruned
+type RedelegationCursor struct {
+	db.BaseModel
+	RTokenDenom string `gorm:"type:varchar(128) not null;default:'';column:rtoken_denom;uniqueIndex"`
+	Height      int64  `gorm:"not null;default:0;column:height"`
+}
+
+func (f RedelegationCursor) TableName() string {
+	return "staking_election_redelegation_cursor"
+}
+
+func UpOrInRedelegationCursor(db *db.WrapDb, c *RedelegationCursor) error {
+	return db.Save(c).Error
+}
+
+func GetRedelegationCursor(db *db.WrapDb, denom string) (info *RedelegationCursor, err error) {
+	info = &RedelegationCursor{}
+	err = db.Take(info, "rtoken_denom = ?", denom).Error
+	return
+}
+
+// GetRedelegationList returns redelegations ordered by height desc,
+// empty denom or poolAddress means no filter, pageIndex starts from 1
+func GetRedelegationList(db *db.WrapDb, denom, poolAddress string, pageIndex, pageSize int) (infos []*Redelegation, totalCount int64, err error) {
+	query := func() *gorm.DB {
+		q := db.Model(&Redelegation{})
+		if len(denom) != 0 {
+			q = q.Where("rtoken_denom = ?", denom)
+		}
+		if len(poolAddress) != 0 {
+			q = q.Where("pool_address = ?", poolAddress)
+		}
+		return q
+	}
+
+	err = query().Count(&totalCount).Error
+	if err != nil {
+		return
+	}
+	err = query().Order("height desc, id desc").
+		Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&infos).Error
+	return
+}
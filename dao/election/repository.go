@@ -0,0 +1,98 @@
+package dao_election
+
+import (
+	"github.com/stafihub/staking-election/db"
+)
+
+// AnnualRateRepository stores average annual rate of each denom, Get returns
+// gorm.ErrRecordNotFound if it doesn't exist
+type AnnualRateRepository interface {
+	GetAnnualRate(denom string) (*AnnualRate, error)
+	GetAnnualRateList() ([]*AnnualRate, error)
+	UpOrInAnnualRate(c *AnnualRate) error
+}
+
+// SelectedValidatorRepository stores validators delegated by pools and the history of removed ones
+type SelectedValidatorRepository interface {
+	GetSelectedValidatorListByDenom(denom string) ([]*SelectedValidator, error)
+	// GetSelectedValidatorList returns all matched validators if pageSize is 0
+	GetSelectedValidatorList(denom, poolAddress string, pageIndex, pageSize int) ([]*SelectedValidator, int64, error)
+	GetSelectedValidatorListByValidator(denom, validatorAddress string) ([]*SelectedValidator, error)
+	UpOrInSelectedValidator(c *SelectedValidator) error
+	DeleteSelectedValidator(denom, poolAddress, validatorAddress string) error
+	AddSelectedValidatorHistory(c *SelectedValidatorHistory) error
+	// GetSelectedValidatorHistoryList returns removed validators of a pool, latest removed first
+	GetSelectedValidatorHistoryList(denom, poolAddress string) ([]*SelectedValidatorHistory, error)
+	// Transaction runs fn with a repository whose writes are kept only if fn returns nil
+	Transaction(fn func(repo SelectedValidatorRepository) error) error
+}
+
+type Repositories struct {
+	AnnualRate        AnnualRateRepository
+	SelectedValidator SelectedValidatorRepository
+}
+
+func NewGormRepositories(db *db.WrapDb) Repositories {
+	return Repositories{
+		AnnualRate:        &gormAnnualRateRepository{db: db},
+		SelectedValidator: &gormSelectedValidatorRepository{db: db},
+	}
+}
+
+type gormAnnualRateRepository struct {
+	db *db.WrapDb
+}
+
+func (r *gormAnnualRateRepository) GetAnnualRate(denom string) (*AnnualRate, error) {
+	return GetAnnualRate(r.db, denom)
+}
+
+func (r *gormAnnualRateRepository) GetAnnualRateList() ([]*AnnualRate, error) {
+	return GetAnnualRateList(r.db)
+}
+
+func (r *gormAnnualRateRepository) UpOrInAnnualRate(c *AnnualRate) error {
+	return UpOrInAnnualRate(r.db, c)
+}
+
+type gormSelectedValidatorRepository struct {
+	db *db.WrapDb
+}
+
+func (r *gormSelectedValidatorRepository) GetSelectedValidatorListByDenom(denom string) ([]*SelectedValidator, error) {
+	return GetSelectedValidatorListByDenom(r.db, denom)
+}
+
+func (r *gormSelectedValidatorRepository) GetSelectedValidatorList(denom, poolAddress string, pageIndex, pageSize int) ([]*SelectedValidator, int64, error) {
+	return GetSelectedValidatorList(r.db, denom, poolAddress, pageIndex, pageSize)
+}
+
+func (r *gormSelectedValidatorRepository) GetSelectedValidatorListByValidator(denom, validatorAddress string) ([]*SelectedValidator, error) {
+	return GetSelectedValidatorListByValidator(r.db, denom, validatorAddress)
+}
+
+func (r *gormSelectedValidatorRepository) UpOrInSelectedValidator(c *SelectedValidator) error {
+	return UpOrInSelectedValidator(r.db, c)
+}
+
+func (r *gormSelectedValidatorRepository) DeleteSelectedValidator(denom, poolAddress, validatorAddress string) error {
+	return DeleteSelectedValidator(r.db, denom, poolAddress, validatorAddress)
+}
+
+func (r *gormSelectedValidatorRepository) AddSelectedValidatorHistory(c *SelectedValidatorHistory) error {
+	return AddSelectedValidatorHistory(r.db, c)
+}
+
+func (r *gormSelectedValidatorRepository) GetSelectedValidatorHistoryList(denom, poolAddress string) ([]*SelectedValidatorHistory, error) {
+	return GetSelectedValidatorHistoryList(r.db, denom, poolAddress)
+}
+
+func (r *gormSelectedValidatorRepository) Transaction(fn func(repo SelectedValidatorRepository) error) error {
+	tx := r.db.NewTransaction()
+	err := fn(&gormSelectedValidatorRepository{db: tx})
+	if err != nil {
+		tx.RollbackTransaction()
+		return err
+	}
+	return tx.CommitTransaction()
+}
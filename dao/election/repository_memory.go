@@ -0,0 +1,218 @@
+package dao_election
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NewMemoryRepositories keeps data in memory, it's meant for tests and local runs
+func NewMemoryRepositories() Repositories {
+	return Repositories{
+		AnnualRate:        NewMemoryAnnualRateRepository(),
+		SelectedValidator: NewMemorySelectedValidatorRepository(),
+	}
+}
+
+type MemoryAnnualRateRepository struct {
+	mutex  sync.RWMutex
+	nextId int64
+	rates  map[string]AnnualRate // denom -> rate
+}
+
+func NewMemoryAnnualRateRepository() *MemoryAnnualRateRepository {
+	return &MemoryAnnualRateRepository{
+		nextId: 1,
+		rates:  make(map[string]AnnualRate),
+	}
+}
+
+func (r *MemoryAnnualRateRepository) GetAnnualRate(denom string) (*AnnualRate, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	rate, exist := r.rates[denom]
+	if !exist {
+		return &AnnualRate{}, gorm.ErrRecordNotFound
+	}
+	return &rate, nil
+}
+
+// GetAnnualRateList returns rates ordered by id, the same as insertion order
+func (r *MemoryAnnualRateRepository) GetAnnualRateList() ([]*AnnualRate, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	infos := make([]*AnnualRate, 0, len(r.rates))
+	for denom := range r.rates {
+		rate := r.rates[denom]
+		infos = append(infos, &rate)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos, nil
+}
+
+func (r *MemoryAnnualRateRepository) UpOrInAnnualRate(c *AnnualRate) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	now := int(time.Now().Unix())
+	if existed, exist := r.rates[c.RTokenDenom]; exist {
+		c.ID = existed.ID
+		c.CreatedAt = existed.CreatedAt
+	} else {
+		c.ID = r.nextId
+		c.CreatedAt = now
+		r.nextId++
+	}
+	c.UpdatedAt = now
+	r.rates[c.RTokenDenom] = *c
+	return nil
+}
+
+type MemorySelectedValidatorRepository struct {
+	mutex      sync.RWMutex
+	nextId     int64
+	validators map[string]SelectedValidator // denom + pool + validator -> selected validator
+	histories  []SelectedValidatorHistory
+}
+
+func NewMemorySelectedValidatorRepository() *MemorySelectedValidatorRepository {
+	return &MemorySelectedValidatorRepository{
+		nextId:     1,
+		validators: make(map[string]SelectedValidator),
+		histories:  make([]SelectedValidatorHistory, 0),
+	}
+}
+
+func selectedValidatorKey(denom, poolAddress, validatorAddress string) string {
+	return denom + "/" + poolAddress + "/" + validatorAddress
+}
+
+// filter returns matched validators ordered by denom, pool and validator
+func (r *MemorySelectedValidatorRepository) filter(match func(v *SelectedValidator) bool) []*SelectedValidator {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	infos := make([]*SelectedValidator, 0)
+	for key := range r.validators {
+		v := r.validators[key]
+		if match(&v) {
+			infos = append(infos, &v)
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].RTokenDenom != infos[j].RTokenDenom {
+			return infos[i].RTokenDenom < infos[j].RTokenDenom
+		}
+		if infos[i].PoolAddress != infos[j].PoolAddress {
+			return infos[i].PoolAddress < infos[j].PoolAddress
+		}
+		return infos[i].ValidatorAddress < infos[j].ValidatorAddress
+	})
+	return infos
+}
+
+func (r *MemorySelectedValidatorRepository) GetSelectedValidatorListByDenom(denom string) ([]*SelectedValidator, error) {
+	return r.filter(func(v *SelectedValidator) bool {
+		return v.RTokenDenom == denom
+	}), nil
+}
+
+func (r *MemorySelectedValidatorRepository) GetSelectedValidatorList(denom, poolAddress string, pageIndex, pageSize int) ([]*SelectedValidator, int64, error) {
+	infos := r.filter(func(v *SelectedValidator) bool {
+		return (len(denom) == 0 || v.RTokenDenom == denom) && (len(poolAddress) == 0 || v.PoolAddress == poolAddress)
+	})
+	totalCount := int64(len(infos))
+	if pageSize <= 0 {
+		return infos, totalCount, nil
+	}
+	start := (pageIndex - 1) * pageSize
+	if start < 0 || start >= len(infos) {
+		return make([]*SelectedValidator, 0), totalCount, nil
+	}
+	end := start + pageSize
+	if end > len(infos) {
+		end = len(infos)
+	}
+	return infos[start:end], totalCount, nil
+}
+
+func (r *MemorySelectedValidatorRepository) GetSelectedValidatorListByValidator(denom, validatorAddress string) ([]*SelectedValidator, error) {
+	return r.filter(func(v *SelectedValidator) bool {
+		return v.RTokenDenom == denom && v.ValidatorAddress == validatorAddress
+	}), nil
+}
+
+func (r *MemorySelectedValidatorRepository) UpOrInSelectedValidator(c *SelectedValidator) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	key := selectedValidatorKey(c.RTokenDenom, c.PoolAddress, c.ValidatorAddress)
+	now := int(time.Now().Unix())
+	if existed, exist := r.validators[key]; exist {
+		c.ID = existed.ID
+		c.CreatedAt = existed.CreatedAt
+	} else {
+		c.ID = r.nextId
+		c.CreatedAt = now
+		r.nextId++
+	}
+	c.UpdatedAt = now
+	r.validators[key] = *c
+	return nil
+}
+
+func (r *MemorySelectedValidatorRepository) DeleteSelectedValidator(denom, poolAddress, validatorAddress string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.validators, selectedValidatorKey(denom, poolAddress, validatorAddress))
+	return nil
+}
+
+func (r *MemorySelectedValidatorRepository) AddSelectedValidatorHistory(c *SelectedValidatorHistory) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	now := int(time.Now().Unix())
+	c.ID = int64(len(r.histories) + 1)
+	c.CreatedAt = now
+	c.UpdatedAt = now
+	r.histories = append(r.histories, *c)
+	return nil
+}
+
+func (r *MemorySelectedValidatorRepository) GetSelectedValidatorHistoryList(denom, poolAddress string) ([]*SelectedValidatorHistory, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	infos := make([]*SelectedValidatorHistory, 0)
+	for i := range r.histories {
+		h := r.histories[i]
+		if h.RTokenDenom == denom && h.PoolAddress == poolAddress {
+			infos = append(infos, &h)
+		}
+	}
+	sort.SliceStable(infos, func(i, j int) bool { return infos[i].RemovedAt > infos[j].RemovedAt })
+	return infos, nil
+}
+
+// Transaction runs fn on a staged copy which replaces the data only if fn succeeds, so
+// others never see writes of a failed or unfinished fn. Others wait until it returns.
+func (r *MemorySelectedValidatorRepository) Transaction(fn func(repo SelectedValidatorRepository) error) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	staged := &MemorySelectedValidatorRepository{
+		nextId:     r.nextId,
+		validators: make(map[string]SelectedValidator, len(r.validators)),
+		histories:  append(make([]SelectedValidatorHistory, 0, len(r.histories)), r.histories...),
+	}
+	for key, v := range r.validators {
+		staged.validators[key] = v
+	}
+
+	err := fn(staged)
+	if err != nil {
+		return err
+	}
+	r.validators = staged.validators
+	r.histories = staged.histories
+	r.nextId = staged.nextId
+	return nil
+}
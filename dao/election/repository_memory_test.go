@@ -0,0 +1,86 @@
+package dao_election
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMemorySelectedValidatorTransaction(t *testing.T) {
+	tests := []struct {
+		name        string
+		fail        bool
+		wantVals    []string
+		wantHistory int
+	}{
+		{"committed on success", false, []string{"val2", "val3"}, 1},
+		{"discarded on err", true, []string{"val1", "val2"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewMemorySelectedValidatorRepository()
+			for _, val := range []string{"val1", "val2"} {
+				if err := r.UpOrInSelectedValidator(&SelectedValidator{RTokenDenom: "uratom", PoolAddress: "pool", ValidatorAddress: val}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := r.Transaction(func(repo SelectedValidatorRepository) error {
+				if err := repo.DeleteSelectedValidator("uratom", "pool", "val1"); err != nil {
+					return err
+				}
+				if err := repo.AddSelectedValidatorHistory(&SelectedValidatorHistory{RTokenDenom: "uratom", PoolAddress: "pool", ValidatorAddress: "val1"}); err != nil {
+					return err
+				}
+				if err := repo.UpOrInSelectedValidator(&SelectedValidator{RTokenDenom: "uratom", PoolAddress: "pool", ValidatorAddress: "val3"}); err != nil {
+					return err
+				}
+				// writes are visible inside fn only
+				staged, _ := repo.GetSelectedValidatorListByDenom("uratom")
+				if got := validatorAddresses(staged); got != "[val2 val3]" {
+					return fmt.Errorf("staged validators %s", got)
+				}
+				if r == repo {
+					return fmt.Errorf("fn got the repository itself")
+				}
+				if tt.fail {
+					return fmt.Errorf("fail")
+				}
+				return nil
+			})
+			if (err != nil) != tt.fail {
+				t.Fatalf("Transaction err: %v", err)
+			}
+
+			list, _ := r.GetSelectedValidatorListByDenom("uratom")
+			if got := validatorAddresses(list); got != fmt.Sprint(tt.wantVals) {
+				t.Errorf("validators %s, want %v", got, tt.wantVals)
+			}
+			histories, _ := r.GetSelectedValidatorHistoryList("uratom", "pool")
+			if len(histories) != tt.wantHistory {
+				t.Errorf("%d histories, want %d", len(histories), tt.wantHistory)
+			}
+
+			// nextId is kept on success and restored with the data on err
+			next := &SelectedValidator{RTokenDenom: "uratom", PoolAddress: "pool", ValidatorAddress: "val4"}
+			if err := r.UpOrInSelectedValidator(next); err != nil {
+				t.Fatal(err)
+			}
+			wantId := int64(3)
+			if !tt.fail {
+				wantId = 4
+			}
+			if next.ID != wantId {
+				t.Errorf("next id %d, want %d", next.ID, wantId)
+			}
+		})
+	}
+}
+
+func validatorAddresses(list []*SelectedValidator) string {
+	addresses := make([]string, 0, len(list))
+	for _, v := range list {
+		addresses = append(addresses, v.ValidatorAddress)
+	}
+	return fmt.Sprint(addresses)
+}
@@ -0,0 +1,68 @@
+package dao_election
+
+import (
+	"github.com/stafihub/staking-election/db"
+)
+
+const (
+	RetentionTableElectionRecord           = "election_record"
+	RetentionTableRedelegation             = "redelegation"
+	RetentionTableSelectedValidatorHistory = "selected_validator_history"
+	RetentionTableAdminAudit               = "admin_audit"
+
+	pruneBatchSize = 1000
+)
+
+// RetentionModels are the history-style tables which can be pruned, keyed by retention table name
+var RetentionModels = map[string]interface{}{
+	RetentionTableElectionRecord:           &ElectionRecord{},
+	RetentionTableRedelegation:             &Redelegation{},
+	RetentionTableSelectedValidatorHistory: &SelectedValidatorHistory{},
+	RetentionTableAdminAudit:               &AdminAudit{},
+}
+
+// CountCreatedBefore counts rows of model created before unix seconds
+func CountCreatedBefore(db *db.WrapDb, model interface{}, before int64) (count int64, err error) {
+	err = db.Model(model).Where("create_time < ?", before).Count(&count).Error
+	return
+}
+
+// DeleteCreatedBefore deletes rows of model created before unix seconds
+func DeleteCreatedBefore(db *db.WrapDb, model interface{}, before int64) (int64, error) {
+	result := db.Where("create_time < ?", before).Delete(model)
+	return result.RowsAffected, result.Error
+}
+
+// ElectionRecordBrief is the part of election record which downsampling needs
+type ElectionRecordBrief struct {
+	ID          int64  `gorm:"column:id"`
+	RTokenDenom string `gorm:"column:rtoken_denom"`
+	PoolAddress string `gorm:"column:pool_address"`
+	CreatedAt   int    `gorm:"column:create_time"`
+}
+
+// GetKeepElectionRecordBriefsBefore returns `keep` decision records created before unix seconds
+// ordered by id, at most limit records after afterId
+func GetKeepElectionRecordBriefsBefore(db *db.WrapDb, before, afterId int64, limit int) (infos []*ElectionRecordBrief, err error) {
+	err = db.Model(&ElectionRecord{}).
+		Select("id, rtoken_denom, pool_address, create_time").
+		Where("decision = ? and create_time < ? and id > ?", DecisionKeep, before, afterId).
+		Order("id asc").Limit(limit).Scan(&infos).Error
+	return
+}
+
+func DeleteElectionRecordsByIds(db *db.WrapDb, ids []int64) (int64, error) {
+	deleted := int64(0)
+	for start := 0; start < len(ids); start += pruneBatchSize {
+		end := start + pruneBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		result := db.Where("id in ?", ids[start:end]).Delete(&ElectionRecord{})
+		if result.Error != nil {
+			return deleted, result.Error
+		}
+		deleted += result.RowsAffected
+	}
+	return deleted, nil
+}
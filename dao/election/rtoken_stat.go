@@ -0,0 +1,29 @@
+package dao_election
+
+import "github.com/stafihub/staking-election/db"
+
+type RTokenStat struct {
+	db.BaseModel
+	RTokenDenom string `gorm:"type:varchar(128) not null;default:'';column:rtoken_denom;uniqueIndex"`
+	TotalBonded string `gorm:"type:varchar(80) not null;default:'';column:total_bonded"` // native token bonded by all pools
+	Height      int64  `gorm:"not null;default:0;column:height"`
+}
+
+func (f RTokenStat) TableName() string {
+	return "staking_election_rtoken_stat"
+}
+
+func UpOrInRTokenStat(db *db.WrapDb, c *RTokenStat) error {
+	return db.Save(c).Error
+}
+
+func GetRTokenStat(db *db.WrapDb, denom string) (info *RTokenStat, err error) {
+	info = &RTokenStat{}
+	err = db.Take(info, "rtoken_denom = ?", denom).Error
+	return
+}
+
+func GetRTokenStatList(db *db.WrapDb) (infos []*RTokenStat, err error) {
+	err = db.Find(&infos).Error
+	return
+}
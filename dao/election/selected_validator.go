@@ -1,10 +1,13 @@
 package dao_election
 
-import "github.com/stafihub/staking-election/db"
+import (
+	"github.com/stafihub/staking-election/db"
+	"gorm.io/gorm"
+)
 
 type SelectedValidator struct {
 	db.BaseModel
-	RTokenDenom      string `gorm:"type:varchar(10) not null;default:'';column:rtoken_denom;uniqueIndex:uni_denom_pool_val"`
+	RTokenDenom      string `gorm:"type:varchar(128) not null;default:'';column:rtoken_denom;uniqueIndex:uni_denom_pool_val"`
 	PoolAddress      string `gorm:"type:varchar(80) not null;default:'';column:pool_address;uniqueIndex:uni_denom_pool_val"`
 	ValidatorAddress string `gorm:"type:varchar(80) not null;default:'';column:validator_address;uniqueIndex:uni_denom_pool_val"`
 	Moniker          string `gorm:"type:varchar(50) not null;default:'';column:moniker"`
@@ -32,3 +35,39 @@ func GetAllSelectedValidators(db *db.WrapDb) (infos []*SelectedValidator, err er
 func DeleteSelectedValidator(db *db.WrapDb, denom, poolAddress, validatorAddress string) error {
 	return db.Delete(&SelectedValidator{}, "rtoken_denom = ? and pool_address = ? and validator_address = ?", denom, poolAddress, validatorAddress).Error
 }
+
+func GetSelectedValidatorListByDenom(db *db.WrapDb, denom string) (infos []*SelectedValidator, err error) {
+	err = db.Find(&infos, "rtoken_denom = ?", denom).Error
+	return
+}
+
+// GetSelectedValidatorList returns selected validators ordered by denom, pool and validator,
+// empty denom or poolAddress means no filter, pageIndex starts from 1, pageSize 0 returns all
+func GetSelectedValidatorList(db *db.WrapDb, denom, poolAddress string, pageIndex, pageSize int) (infos []*SelectedValidator, totalCount int64, err error) {
+	query := func() *gorm.DB {
+		q := db.Model(&SelectedValidator{})
+		if len(denom) != 0 {
+			q = q.Where("rtoken_denom = ?", denom)
+		}
+		if len(poolAddress) != 0 {
+			q = q.Where("pool_address = ?", poolAddress)
+		}
+		return q
+	}
+
+	err = query().Count(&totalCount).Error
+	if err != nil {
+		return
+	}
+	q := query().Order("rtoken_denom asc, pool_address asc, validator_address asc")
+	if pageSize > 0 {
+		q = q.Offset((pageIndex - 1) * pageSize).Limit(pageSize)
+	}
+	err = q.Find(&infos).Error
+	return
+}
+
+func GetSelectedValidatorListByValidator(db *db.WrapDb, denom, validatorAddress string) (infos []*SelectedValidator, err error) {
+	err = db.Order("pool_address asc").Find(&infos, "rtoken_denom = ? and validator_address = ?", denom, validatorAddress).Error
+	return
+}
@@ -0,0 +1,27 @@
+package dao_election
+
+import "github.com/stafihub/staking-election/db"
+
+// SelectedValidatorHistory records validators which were removed from a pool's selected validators
+type SelectedValidatorHistory struct {
+	db.BaseModel
+	RTokenDenom      string `gorm:"type:varchar(128) not null;default:'';column:rtoken_denom;index:idx_history_denom_pool"`
+	PoolAddress      string `gorm:"type:varchar(80) not null;default:'';column:pool_address;index:idx_history_denom_pool"`
+	ValidatorAddress string `gorm:"type:varchar(80) not null;default:'';column:validator_address"`
+	Moniker          string `gorm:"type:varchar(50) not null;default:'';column:moniker"`
+	SelectedAt       int64  `gorm:"not null;default:0;column:selected_at"` // unix seconds
+	RemovedAt        int64  `gorm:"not null;default:0;column:removed_at"`  // unix seconds
+}
+
+func (f SelectedValidatorHistory) TableName() string {
+	return "staking_election_selected_validator_history"
+}
+
+func AddSelectedValidatorHistory(db *db.WrapDb, c *SelectedValidatorHistory) error {
+	return db.Create(c).Error
+}
+
+func GetSelectedValidatorHistoryList(db *db.WrapDb, denom, poolAddress string) (infos []*SelectedValidatorHistory, err error) {
+	err = db.Order("removed_at desc").Find(&infos, "rtoken_denom = ? and pool_address = ?", denom, poolAddress).Error
+	return
+}
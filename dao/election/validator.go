@@ -0,0 +1,42 @@
+package dao_election
+
+import "github.com/stafihub/staking-election/db"
+
+type Validator struct {
+	db.BaseModel
+	RTokenDenom      string `gorm:"type:varchar(128) not null;default:'';column:rtoken_denom;uniqueIndex:uni_validator_denom_val"`
+	ValidatorAddress string `gorm:"type:varchar(80) not null;default:'';column:validator_address;uniqueIndex:uni_validator_denom_val"`
+	Moniker          string `gorm:"type:varchar(70) not null;default:'';column:moniker"`
+	Identity         string `gorm:"type:varchar(64) not null;default:'';column:identity"`
+	AnnualRate       string `gorm:"type:varchar(80) not null;default:'';column:annual_rate"`
+	Commission       string `gorm:"type:varchar(80) not null;default:'';column:commission"`
+	TokenAmount      string `gorm:"type:varchar(80) not null;default:'';column:token_amount"`
+	Height           int64  `gorm:"not null;default:0;column:height"`
+}
+
+func (f Validator) TableName() string {
+	return "staking_election_validator"
+}
+
+func UpOrInValidator(db *db.WrapDb, c *Validator) error {
+	return db.Save(c).Error
+}
+
+func GetValidator(db *db.WrapDb, denom, validatorAddress string) (info *Validator, err error) {
+	info = &Validator{}
+	err = db.Take(info, "rtoken_denom = ? and validator_address = ?", denom, validatorAddress).Error
+	return
+}
+
+func GetValidatorListByDenom(db *db.WrapDb, denom string) (infos []*Validator, err error) {
+	err = db.Find(&infos, "rtoken_denom = ?", denom).Error
+	return
+}
+
+// rm validators of denom that are not in the latest snapshot
+func DeleteValidatorsNotIn(db *db.WrapDb, denom string, validatorAddresses []string) error {
+	if len(validatorAddresses) == 0 {
+		return db.Delete(&Validator{}, "rtoken_denom = ?", denom).Error
+	}
+	return db.Delete(&Validator{}, "rtoken_denom = ? and validator_address not in ?", denom, validatorAddresses).Error
+}
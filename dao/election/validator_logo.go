@@ -0,0 +1,30 @@
+package dao_election
+
+import "github.com/stafihub/staking-election/db"
+
+type ValidatorLogo struct {
+	db.BaseModel
+	RTokenDenom      string `gorm:"type:varchar(128) not null;default:'';column:rtoken_denom;uniqueIndex:uni_logo_denom_val"`
+	ValidatorAddress string `gorm:"type:varchar(80) not null;default:'';column:validator_address;uniqueIndex:uni_logo_denom_val"`
+	LogoUrl          string `gorm:"type:varchar(512) not null;default:'';column:logo_url"`
+	Source           string `gorm:"type:varchar(20) not null;default:'';column:source"` // template/keybase/default
+}
+
+func (f ValidatorLogo) TableName() string {
+	return "staking_election_validator_logo"
+}
+
+func UpOrInValidatorLogo(db *db.WrapDb, c *ValidatorLogo) error {
+	return db.Save(c).Error
+}
+
+func GetValidatorLogo(db *db.WrapDb, denom, validatorAddress string) (info *ValidatorLogo, err error) {
+	info = &ValidatorLogo{}
+	err = db.Take(info, "rtoken_denom = ? and validator_address = ?", denom, validatorAddress).Error
+	return
+}
+
+func GetValidatorLogoListByDenom(db *db.WrapDb, denom string) (infos []*ValidatorLogo, err error) {
+	err = db.Find(&infos, "rtoken_denom = ?", denom).Error
+	return
+}
@@ -5,14 +5,206 @@ package migrate
 
 import (
 	"fmt"
-	"github.com/stafihub/staking-election/dao/election"
+	"time"
+
+	"github.com/sirupsen/logrus"
 	"github.com/stafihub/staking-election/db"
+	"gorm.io/gorm"
 )
 
-func AutoMigrate(db *db.WrapDb) error {
-	err := dao_election.AutoMigrate(db)
+// Migration is one step of schema change, Down reverts what Up does
+type Migration struct {
+	Version int64
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration records an applied migration
+type SchemaMigration struct {
+	Version   int64  `gorm:"primaryKey;autoIncrement:false;column:version"`
+	Name      string `gorm:"type:varchar(128) not null;default:'';column:name"`
+	AppliedAt int64  `gorm:"not null;default:0;column:applied_at"`
+}
+
+func (f SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt int64
+}
+
+// LatestVersion is the schema version this binary expects
+func LatestVersion() int64 {
+	return migrations[len(migrations)-1].Version
+}
+
+// CurrentVersion returns the latest applied version, 0 if none
+func CurrentVersion(db *db.WrapDb) (int64, error) {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return 0, err
+	}
+	version := int64(0)
+	for v := range applied {
+		if v > version {
+			version = v
+		}
+	}
+	return version, nil
+}
+
+// CheckVersion returns an error if schema is not at LatestVersion
+func CheckVersion(db *db.WrapDb) error {
+	version, err := CurrentVersion(db)
+	if err != nil {
+		return err
+	}
+	if version != LatestVersion() {
+		return fmt.Errorf("schema version is %d but %d is expected, please run `migrate up` first", version, LatestVersion())
+	}
+	return nil
+}
+
+func Status(db *db.WrapDb) ([]MigrationStatus, error) {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+	status := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		record, exist := applied[m.Version]
+		status = append(status, MigrationStatus{
+			Version:   m.Version,
+			Name:      m.Name,
+			Applied:   exist,
+			AppliedAt: record.AppliedAt,
+		})
+	}
+	return status, nil
+}
+
+// Up applies all pending migrations
+func Up(db *db.WrapDb) error {
+	return To(db, LatestVersion())
+}
+
+// Down reverts the latest applied migration
+func Down(db *db.WrapDb) error {
+	version, err := CurrentVersion(db)
+	if err != nil {
+		return err
+	}
+	if version == 0 {
+		return fmt.Errorf("no migration to revert")
+	}
+	target := int64(0)
+	for _, m := range migrations {
+		if m.Version < version {
+			target = m.Version
+		}
+	}
+	return To(db, target)
+}
+
+// To migrates schema up or down to version, each step runs in its own transaction.
+// Mysql can't roll back DDL, a failed step may leave its DDL applied without the version
+// row, it is applied again by the next run.
+func To(db *db.WrapDb, version int64) error {
+	if version != 0 && findMigration(version) == nil {
+		return fmt.Errorf("migration version %d not exist", version)
+	}
+	current, err := CurrentVersion(db)
+	if err != nil {
+		return err
+	}
+
+	if version >= current {
+		for _, m := range migrations {
+			if m.Version <= current || m.Version > version {
+				continue
+			}
+			err = apply(db, m, true)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.Version > current || m.Version <= version {
+			continue
+		}
+		err = apply(db, m, false)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func apply(db *db.WrapDb, m Migration, up bool) error {
+	direction := "up"
+	if !up {
+		direction = "down"
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if up {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			// created here so CheckVersion and Status never write
+			if !tx.Migrator().HasTable(SchemaMigration{}) {
+				if err := tx.Migrator().CreateTable(SchemaMigration{}); err != nil {
+					return err
+				}
+			}
+			return tx.Create(&SchemaMigration{
+				Version:   m.Version,
+				Name:      m.Name,
+				AppliedAt: time.Now().Unix(),
+			}).Error
+		}
+		if err := m.Down(tx); err != nil {
+			return err
+		}
+		return tx.Delete(&SchemaMigration{}, "version = ?", m.Version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migration %d %s %s err: %s", m.Version, m.Name, direction, err)
+	}
+	logrus.Infof("migration %d %s %s done", m.Version, m.Name, direction)
+	return nil
+}
+
+// appliedMigrations returns version -> applied record, empty if schema_migrations not exist
+func appliedMigrations(db *db.WrapDb) (map[int64]SchemaMigration, error) {
+	applied := make(map[int64]SchemaMigration)
+	if !db.Migrator().HasTable(SchemaMigration{}) {
+		return applied, nil
+	}
+	records := make([]SchemaMigration, 0)
+	err := db.Find(&records).Error
 	if err != nil {
-		return fmt.Errorf("dao_user.AutoMigrate %s", err)
+		return nil, err
+	}
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+func findMigration(version int64) *Migration {
+	for i := range migrations {
+		if migrations[i].Version == version {
+			return &migrations[i]
+		}
 	}
 	return nil
 }
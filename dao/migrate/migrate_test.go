@@ -0,0 +1,183 @@
+package migrate
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stafihub/staking-election/dao/election"
+	"github.com/stafihub/staking-election/db"
+)
+
+func newMemoryDb(t *testing.T) *db.WrapDb {
+	t.Helper()
+	wrapDb, err := db.NewDB(&db.Config{Driver: db.DriverSqlite, DBName: db.SqliteMemory})
+	if err != nil {
+		t.Fatalf("NewDB err: %s", err)
+	}
+	t.Cleanup(func() {
+		if sqlDb, err := wrapDb.DB.DB(); err == nil {
+			sqlDb.Close()
+		}
+	})
+	return wrapDb
+}
+
+func assertVersion(t *testing.T, wrapDb *db.WrapDb, want int64) {
+	t.Helper()
+	version, err := CurrentVersion(wrapDb)
+	if err != nil {
+		t.Fatalf("CurrentVersion err: %s", err)
+	}
+	if version != want {
+		t.Fatalf("version %d, want %d", version, want)
+	}
+}
+
+func TestCheckVersionOnlyReads(t *testing.T) {
+	wrapDb := newMemoryDb(t)
+
+	assertVersion(t, wrapDb, 0)
+	if err := CheckVersion(wrapDb); err == nil {
+		t.Fatal("CheckVersion of empty db want err")
+	}
+	status, err := Status(wrapDb)
+	if err != nil {
+		t.Fatalf("Status err: %s", err)
+	}
+	for _, s := range status {
+		if s.Applied {
+			t.Errorf("migration %d applied on empty db", s.Version)
+		}
+	}
+	if wrapDb.Migrator().HasTable(SchemaMigration{}) {
+		t.Fatal("schema_migrations created by a check")
+	}
+}
+
+func TestUpDownTo(t *testing.T) {
+	wrapDb := newMemoryDb(t)
+
+	if err := Up(wrapDb); err != nil {
+		t.Fatalf("Up err: %s", err)
+	}
+	assertVersion(t, wrapDb, LatestVersion())
+	if err := CheckVersion(wrapDb); err != nil {
+		t.Fatalf("CheckVersion after Up err: %s", err)
+	}
+	for _, table := range v1Tables {
+		if !wrapDb.Migrator().HasTable(table) {
+			t.Errorf("table of %T not created", table)
+		}
+	}
+	// up again is a no-op
+	if err := Up(wrapDb); err != nil {
+		t.Fatalf("second Up err: %s", err)
+	}
+
+	if err := Down(wrapDb); err != nil {
+		t.Fatalf("Down err: %s", err)
+	}
+	assertVersion(t, wrapDb, 2)
+	if wrapDb.Migrator().HasTable(v3RedelegationCursor{}) {
+		t.Error("table of v3RedelegationCursor not dropped")
+	}
+
+	if err := To(wrapDb, 0); err != nil {
+		t.Fatalf("To(0) err: %s", err)
+	}
+	assertVersion(t, wrapDb, 0)
+	for _, table := range v1Tables {
+		if wrapDb.Migrator().HasTable(table) {
+			t.Errorf("table of %T not dropped", table)
+		}
+	}
+	if err := Down(wrapDb); err == nil {
+		t.Error("Down at version 0 want err")
+	}
+	if err := To(wrapDb, LatestVersion()+1); err == nil {
+		t.Error("To not existing version want err")
+	}
+
+	if err := To(wrapDb, 1); err != nil {
+		t.Fatalf("To(1) err: %s", err)
+	}
+	assertVersion(t, wrapDb, 1)
+}
+
+func TestWidenRTokenDenomDownRefusesLongDenom(t *testing.T) {
+	wrapDb := newMemoryDb(t)
+	if err := To(wrapDb, 2); err != nil {
+		t.Fatalf("To 2 err: %s", err)
+	}
+
+	ibcDenom := "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2"
+	err := wrapDb.Create(&dao_election.AnnualRate{RTokenDenom: ibcDenom, AnnualRate: "0.1"}).Error
+	if err != nil {
+		t.Fatalf("create annual rate err: %s", err)
+	}
+	err = Down(wrapDb)
+	if err == nil || !strings.Contains(err.Error(), "rtoken_denom") {
+		t.Fatalf("Down with a long denom stored want err, got %v", err)
+	}
+	assertVersion(t, wrapDb, 2)
+
+	err = wrapDb.Delete(&dao_election.AnnualRate{}, "rtoken_denom = ?", ibcDenom).Error
+	if err != nil {
+		t.Fatalf("delete annual rate err: %s", err)
+	}
+	if err := Down(wrapDb); err != nil {
+		t.Fatalf("Down err: %s", err)
+	}
+	assertVersion(t, wrapDb, 1)
+}
+
+func TestVarcharSize(t *testing.T) {
+	wrapDb := newMemoryDb(t)
+	if err := To(wrapDb, 1); err != nil {
+		t.Fatalf("To 1 err: %s", err)
+	}
+
+	size, err := varcharSize(wrapDb.DB, "staking_election_annual_rate", "rtoken_denom")
+	if err != nil {
+		t.Fatalf("varcharSize err: %s", err)
+	}
+	if size != 10 {
+		t.Errorf("size %d, want 10", size)
+	}
+	if _, err := varcharSize(wrapDb.DB, "staking_election_annual_rate", "no_column"); err == nil {
+		t.Error("varcharSize of unknown column want err")
+	}
+}
+
+func TestRedelegationCursorStartsFromIndexedHeights(t *testing.T) {
+	wrapDb := newMemoryDb(t)
+	if err := To(wrapDb, 2); err != nil {
+		t.Fatalf("To 2 err: %s", err)
+	}
+	for i, r := range []dao_election.Redelegation{
+		{RTokenDenom: "uratom", PoolAddress: "poolA", Height: 10},
+		{RTokenDenom: "uratom", PoolAddress: "poolB", Height: 20},
+		{RTokenDenom: "uriris", PoolAddress: "poolC", Height: 15},
+	} {
+		r.TxHash = fmt.Sprintf("tx%d", i)
+		if err := wrapDb.Create(&r).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := To(wrapDb, 3); err != nil {
+		t.Fatalf("To 3 err: %s", err)
+	}
+	cursors := make([]dao_election.RedelegationCursor, 0)
+	if err := wrapDb.Order("rtoken_denom asc").Find(&cursors).Error; err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, 0)
+	for _, cursor := range cursors {
+		got = append(got, fmt.Sprintf("%s:%d", cursor.RTokenDenom, cursor.Height))
+	}
+	if strings.Join(got, " ") != "uratom:19 uriris:14" {
+		t.Errorf("cursors %v", got)
+	}
+}
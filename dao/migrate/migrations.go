@@ -0,0 +1,148 @@
+package migrate
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stafihub/staking-election/db"
+	"gorm.io/gorm"
+)
+
+// migrations must be ordered by version, applied ones must never be changed.
+// Mysql commits each DDL statement implicitly, so steps must be safe to run again
+// after they fail halfway.
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "baseline",
+		// creates the tables of v1_baseline.go, or adopts the ones created by the former AutoMigrate
+		Up: func(tx *gorm.DB) error {
+			// table options are mysql only
+			if tx.Dialector.Name() == db.DriverMysql {
+				tx = tx.Set("gorm:table_options", "ENGINE=InnoDB AUTO_INCREMENT=1 DEFAULT CHARSET=utf8")
+			}
+			return tx.AutoMigrate(v1Tables...)
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(v1Tables...)
+		},
+	},
+	{
+		Version: 2,
+		Name:    "widen_rtoken_denom",
+		// ibc denoms are longer than varchar(10)
+		Up: func(tx *gorm.DB) error {
+			return alterVarchar(tx, rTokenDenomTables, "rtoken_denom", 128)
+		},
+		// refuses if a stored denom doesn't fit, rather than truncating it
+		Down: func(tx *gorm.DB) error {
+			err := checkMaxLength(tx, rTokenDenomTables, "rtoken_denom", 10)
+			if err != nil {
+				return err
+			}
+			return alterVarchar(tx, rTokenDenomTables, "rtoken_denom", 10)
+		},
+	},
+	{
+		Version: 3,
+		Name:    "redelegation_cursor",
+		// the redelegation indexer resumes from its cursor, so pruning redelegations never rewinds it
+		Up: func(tx *gorm.DB) error {
+			if tx.Dialector.Name() == db.DriverMysql {
+				tx = tx.Set("gorm:table_options", "ENGINE=InnoDB AUTO_INCREMENT=1 DEFAULT CHARSET=utf8")
+			}
+			err := tx.AutoMigrate(&v3RedelegationCursor{})
+			if err != nil {
+				return err
+			}
+			// the latest indexed height is queried again, a pass may have stopped within it
+			now := time.Now().Unix()
+			return tx.Exec(`INSERT INTO staking_election_redelegation_cursor (rtoken_denom, height, create_time, update_time)
+				SELECT rtoken_denom, MAX(height) - 1, ?, ? FROM staking_election_redelegation
+				WHERE rtoken_denom NOT IN (SELECT rtoken_denom FROM staking_election_redelegation_cursor)
+				GROUP BY rtoken_denom`, now, now).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v3RedelegationCursor{})
+		},
+	},
+}
+
+var rTokenDenomTables = []string{
+	"staking_election_selected_validator",
+	"staking_election_annual_rate",
+	"staking_election_validator",
+	"staking_election_rtoken_stat",
+	"staking_election_selected_validator_history",
+	"staking_election_validator_logo",
+	"staking_election_redelegation",
+	"staking_election_election_record",
+	"staking_election_validator_list",
+	"staking_election_paused_pool",
+}
+
+// alterVarchar changes size of a not null varchar column, sqlite doesn't enforce the size.
+// Tables already of size are skipped, so a step half applied by mysql can be run again.
+func alterVarchar(tx *gorm.DB, tables []string, column string, size int) error {
+	for _, table := range tables {
+		if tx.Dialector.Name() == db.DriverSqlite {
+			continue
+		}
+		current, err := varcharSize(tx, table, column)
+		if err != nil {
+			return err
+		}
+		if current == int64(size) {
+			continue
+		}
+
+		var sql string
+		switch tx.Dialector.Name() {
+		case db.DriverMysql:
+			sql = fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s varchar(%d) NOT NULL DEFAULT ''", table, column, size)
+		case db.DriverPostgres:
+			sql = fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE varchar(%d)", table, column, size)
+		default:
+			continue
+		}
+		err = tx.Exec(sql).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// varcharSize returns the declared size of a varchar column
+func varcharSize(tx *gorm.DB, table, column string) (int64, error) {
+	columnTypes, err := tx.Migrator().ColumnTypes(table)
+	if err != nil {
+		return 0, err
+	}
+	for _, columnType := range columnTypes {
+		if columnType.Name() != column {
+			continue
+		}
+		size, ok := columnType.Length()
+		if !ok {
+			return 0, fmt.Errorf("size of %s.%s is unknown", table, column)
+		}
+		return size, nil
+	}
+	return 0, fmt.Errorf("column %s.%s not exist", table, column)
+}
+
+// checkMaxLength returns an error if a value of column in tables is longer than size
+func checkMaxLength(tx *gorm.DB, tables []string, column string, size int) error {
+	for _, table := range tables {
+		var maxLength int
+		err := tx.Table(table).Select(fmt.Sprintf("COALESCE(MAX(LENGTH(%s)), 0)", column)).Scan(&maxLength).Error
+		if err != nil {
+			return err
+		}
+		if maxLength > size {
+			return fmt.Errorf("%s.%s has values of length %d, longer than %d, delete them first", table, column, maxLength, size)
+		}
+	}
+	return nil
+}
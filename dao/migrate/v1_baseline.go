@@ -0,0 +1,164 @@
+// Copyright 2021 stafiprotocol
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package migrate
+
+// tables of schema version 1, frozen copies of the models at that version so later
+// changes of dao/election never change what baseline creates. Never edit them,
+// add a migration instead.
+
+type v1BaseModel struct {
+	ID        int64 `gorm:"not null;primaryKey;autoIncrement;column:id"`
+	CreatedAt int   `gorm:"type:int;autoCreateTime;not null;column:create_time"`
+	UpdatedAt int   `gorm:"type:int;autoUpdateTime;not null;column:update_time"`
+}
+
+type v1SelectedValidator struct {
+	BaseModel        v1BaseModel `gorm:"embedded"`
+	RTokenDenom      string      `gorm:"type:varchar(10) not null;default:'';column:rtoken_denom;uniqueIndex:uni_denom_pool_val"`
+	PoolAddress      string      `gorm:"type:varchar(80) not null;default:'';column:pool_address;uniqueIndex:uni_denom_pool_val"`
+	ValidatorAddress string      `gorm:"type:varchar(80) not null;default:'';column:validator_address;uniqueIndex:uni_denom_pool_val"`
+	Moniker          string      `gorm:"type:varchar(50) not null;default:'';column:moniker"`
+}
+
+func (f v1SelectedValidator) TableName() string {
+	return "staking_election_selected_validator"
+}
+
+type v1AnnualRate struct {
+	BaseModel   v1BaseModel `gorm:"embedded"`
+	RTokenDenom string      `gorm:"type:varchar(10) not null;default:'';column:rtoken_denom;uniqueIndex"`
+	AnnualRate  string      `gorm:"type:varchar(80) not null;default:'';column:annual_rate"`
+}
+
+func (f v1AnnualRate) TableName() string {
+	return "staking_election_annual_rate"
+}
+
+type v1Validator struct {
+	BaseModel        v1BaseModel `gorm:"embedded"`
+	RTokenDenom      string      `gorm:"type:varchar(10) not null;default:'';column:rtoken_denom;uniqueIndex:uni_validator_denom_val"`
+	ValidatorAddress string      `gorm:"type:varchar(80) not null;default:'';column:validator_address;uniqueIndex:uni_validator_denom_val"`
+	Moniker          string      `gorm:"type:varchar(70) not null;default:'';column:moniker"`
+	Identity         string      `gorm:"type:varchar(64) not null;default:'';column:identity"`
+	AnnualRate       string      `gorm:"type:varchar(80) not null;default:'';column:annual_rate"`
+	Commission       string      `gorm:"type:varchar(80) not null;default:'';column:commission"`
+	TokenAmount      string      `gorm:"type:varchar(80) not null;default:'';column:token_amount"`
+	Height           int64       `gorm:"not null;default:0;column:height"`
+}
+
+func (f v1Validator) TableName() string {
+	return "staking_election_validator"
+}
+
+type v1RTokenStat struct {
+	BaseModel   v1BaseModel `gorm:"embedded"`
+	RTokenDenom string      `gorm:"type:varchar(10) not null;default:'';column:rtoken_denom;uniqueIndex"`
+	TotalBonded string      `gorm:"type:varchar(80) not null;default:'';column:total_bonded"`
+	Height      int64       `gorm:"not null;default:0;column:height"`
+}
+
+func (f v1RTokenStat) TableName() string {
+	return "staking_election_rtoken_stat"
+}
+
+type v1SelectedValidatorHistory struct {
+	BaseModel        v1BaseModel `gorm:"embedded"`
+	RTokenDenom      string      `gorm:"type:varchar(10) not null;default:'';column:rtoken_denom;index:idx_history_denom_pool"`
+	PoolAddress      string      `gorm:"type:varchar(80) not null;default:'';column:pool_address;index:idx_history_denom_pool"`
+	ValidatorAddress string      `gorm:"type:varchar(80) not null;default:'';column:validator_address"`
+	Moniker          string      `gorm:"type:varchar(50) not null;default:'';column:moniker"`
+	SelectedAt       int64       `gorm:"not null;default:0;column:selected_at"`
+	RemovedAt        int64       `gorm:"not null;default:0;column:removed_at"`
+}
+
+func (f v1SelectedValidatorHistory) TableName() string {
+	return "staking_election_selected_validator_history"
+}
+
+type v1ValidatorLogo struct {
+	BaseModel        v1BaseModel `gorm:"embedded"`
+	RTokenDenom      string      `gorm:"type:varchar(10) not null;default:'';column:rtoken_denom;uniqueIndex:uni_logo_denom_val"`
+	ValidatorAddress string      `gorm:"type:varchar(80) not null;default:'';column:validator_address;uniqueIndex:uni_logo_denom_val"`
+	LogoUrl          string      `gorm:"type:varchar(512) not null;default:'';column:logo_url"`
+	Source           string      `gorm:"type:varchar(20) not null;default:'';column:source"`
+}
+
+func (f v1ValidatorLogo) TableName() string {
+	return "staking_election_validator_logo"
+}
+
+type v1Redelegation struct {
+	BaseModel    v1BaseModel `gorm:"embedded"`
+	RTokenDenom  string      `gorm:"type:varchar(10) not null;default:'';column:rtoken_denom;index:idx_redelegation_denom_pool"`
+	PoolAddress  string      `gorm:"type:varchar(80) not null;default:'';column:pool_address;index:idx_redelegation_denom_pool;uniqueIndex:uni_tx_pool_old"`
+	OldValidator string      `gorm:"type:varchar(80) not null;default:'';column:old_validator;uniqueIndex:uni_tx_pool_old"`
+	NewValidator string      `gorm:"type:varchar(80) not null;default:'';column:new_validator"`
+	CycleVersion uint64      `gorm:"not null;default:0;column:cycle_version"`
+	CycleNumber  uint64      `gorm:"not null;default:0;column:cycle_number"`
+	ChainEra     uint32      `gorm:"not null;default:0;column:chain_era"`
+	Height       int64       `gorm:"not null;default:0;column:height"`
+	TxHash       string      `gorm:"type:varchar(80) not null;default:'';column:tx_hash;uniqueIndex:uni_tx_pool_old"`
+}
+
+func (f v1Redelegation) TableName() string {
+	return "staking_election_redelegation"
+}
+
+type v1ElectionRecord struct {
+	BaseModel    v1BaseModel `gorm:"embedded"`
+	RTokenDenom  string      `gorm:"type:varchar(10) not null;default:'';column:rtoken_denom;uniqueIndex:uni_record_denom_pool_cycle"`
+	PoolAddress  string      `gorm:"type:varchar(80) not null;default:'';column:pool_address;uniqueIndex:uni_record_denom_pool_cycle"`
+	CycleVersion uint64      `gorm:"not null;default:0;column:cycle_version;uniqueIndex:uni_record_denom_pool_cycle"`
+	CycleNumber  uint64      `gorm:"not null;default:0;column:cycle_number;uniqueIndex:uni_record_denom_pool_cycle"`
+	Decision     string      `gorm:"type:varchar(20) not null;default:'';column:decision"`
+	OldValidator string      `gorm:"type:varchar(80) not null;default:'';column:old_validator"`
+	NewValidator string      `gorm:"type:varchar(80) not null;default:'';column:new_validator"`
+	Detail       string      `gorm:"type:text;column:detail"`
+}
+
+func (f v1ElectionRecord) TableName() string {
+	return "staking_election_election_record"
+}
+
+type v1ValidatorListEntry struct {
+	BaseModel        v1BaseModel `gorm:"embedded"`
+	RTokenDenom      string      `gorm:"type:varchar(10) not null;default:'';column:rtoken_denom;uniqueIndex:uni_list_denom_val"`
+	ValidatorAddress string      `gorm:"type:varchar(80) not null;default:'';column:validator_address;uniqueIndex:uni_list_denom_val"`
+	ListType         string      `gorm:"type:varchar(10) not null;default:'';column:list_type"`
+	Remark           string      `gorm:"type:varchar(256) not null;default:'';column:remark"`
+}
+
+func (f v1ValidatorListEntry) TableName() string {
+	return "staking_election_validator_list"
+}
+
+type v1PausedPool struct {
+	BaseModel   v1BaseModel `gorm:"embedded"`
+	RTokenDenom string      `gorm:"type:varchar(10) not null;default:'';column:rtoken_denom;uniqueIndex:uni_paused_denom_pool"`
+	PoolAddress string      `gorm:"type:varchar(80) not null;default:'';column:pool_address;uniqueIndex:uni_paused_denom_pool"`
+	Remark      string      `gorm:"type:varchar(256) not null;default:'';column:remark"`
+}
+
+func (f v1PausedPool) TableName() string {
+	return "staking_election_paused_pool"
+}
+
+type v1AdminAudit struct {
+	BaseModel v1BaseModel `gorm:"embedded"`
+	Action    string      `gorm:"type:varchar(40) not null;default:'';column:action;index"`
+	Params    string      `gorm:"type:text;column:params"`
+	RemoteIp  string      `gorm:"type:varchar(64) not null;default:'';column:remote_ip"`
+	AuthType  string      `gorm:"type:varchar(10) not null;default:'';column:auth_type"`
+	Success   bool        `gorm:"not null;default:false;column:success"`
+	Message   string      `gorm:"type:varchar(512) not null;default:'';column:message"`
+}
+
+func (f v1AdminAudit) TableName() string {
+	return "staking_election_admin_audit"
+}
+
+var v1Tables = []interface{}{
+	v1SelectedValidator{}, v1AnnualRate{}, v1Validator{}, v1RTokenStat{}, v1SelectedValidatorHistory{}, v1ValidatorLogo{},
+	v1Redelegation{}, v1ElectionRecord{}, v1ValidatorListEntry{}, v1PausedPool{}, v1AdminAudit{},
+}
@@ -0,0 +1,17 @@
+// Copyright 2021 stafiprotocol
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package migrate
+
+// table added by schema version 3, a frozen copy like v1_baseline.go. Never edit it,
+// add a migration instead.
+
+type v3RedelegationCursor struct {
+	BaseModel   v1BaseModel `gorm:"embedded"`
+	RTokenDenom string      `gorm:"type:varchar(128) not null;default:'';column:rtoken_denom;uniqueIndex"`
+	Height      int64       `gorm:"not null;default:0;column:height"`
+}
+
+func (f v3RedelegationCursor) TableName() string {
+	return "staking_election_redelegation_cursor"
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
@@ -17,32 +19,36 @@ const (
 	maxIdleConn = 30
 )
 
+const (
+	DriverMysql    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSqlite   = "sqlite"
+
+	// DBName of sqlite driver to use an in-memory database
+	SqliteMemory = ":memory:"
+)
+
 type Config struct {
 	Host, Port, User, Pass, DBName, Mode string
+	Driver                               string // mysql if empty
+	SslMode                              string // postgres only, disable if empty
 }
 
 // don't use soft delete
 type BaseModel struct {
 	ID        int64 `gorm:"not null;primaryKey;autoIncrement;column:id"`
-	CreatedAt int   `gorm:"type:int(11);autoCreateTime;unsigned;not null;column:create_time"`
-	UpdatedAt int   `gorm:"type:int(11);autoUpdateTime;unsigned;not null;column:update_time"`
+	CreatedAt int   `gorm:"type:int;autoCreateTime;not null;column:create_time"`
+	UpdatedAt int   `gorm:"type:int;autoUpdateTime;not null;column:update_time"`
 }
 
 func NewDB(cfg *Config) (wrapDb *WrapDb, err error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8&parseTime=True",
-		cfg.User, cfg.Pass, cfg.Host, cfg.Port, cfg.DBName)
+	dialector, err := newDialector(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	logLevel := logger.Error
-	db, err := gorm.Open(
-		mysql.New(
-			mysql.Config{
-				DSN:                       dsn,   // data source name
-				DefaultStringSize:         256,   // default size for string fields
-				DisableDatetimePrecision:  true,  // disable datetime precision, which not supported before MySQL 5.6
-				DontSupportRenameIndex:    true,  // drop & create when rename index, rename index not supported before MySQL 5.7, MariaDB
-				DontSupportRenameColumn:   true,  // `change` when rename column, rename column not supported before MySQL 8, MariaDB
-				SkipInitializeWithVersion: false, // auto configure based on currently MySQL version
-			}),
+	db, err := gorm.Open(dialector,
 		&gorm.Config{
 			Logger: logger.Default.LogMode(logLevel)})
 
@@ -56,7 +62,48 @@ func NewDB(cfg *Config) (wrapDb *WrapDb, err error) {
 	}
 	sqlDb.SetMaxIdleConns(maxIdleConn)
 	sqlDb.SetMaxOpenConns(maxOpenConn)
+	if cfg.Driver == DriverSqlite {
+		// sqlite allows only one writer, and each connection of :memory: is a different database
+		sqlDb.SetMaxOpenConns(1)
+	}
 	wrapDb = NewWrapDb(db)
 	logrus.Debug("new DB success")
 	return
 }
+
+func newDialector(cfg *Config) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case "", DriverMysql:
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8&parseTime=True",
+			cfg.User, cfg.Pass, cfg.Host, cfg.Port, cfg.DBName)
+		return mysql.New(
+			mysql.Config{
+				DSN:                       dsn,   // data source name
+				DefaultStringSize:         256,   // default size for string fields
+				DisableDatetimePrecision:  true,  // disable datetime precision, which not supported before MySQL 5.6
+				DontSupportRenameIndex:    true,  // drop & create when rename index, rename index not supported before MySQL 5.7, MariaDB
+				DontSupportRenameColumn:   true,  // `change` when rename column, rename column not supported before MySQL 8, MariaDB
+				SkipInitializeWithVersion: false, // auto configure based on currently MySQL version
+			}), nil
+	case DriverPostgres:
+		sslMode := cfg.SslMode
+		if len(sslMode) == 0 {
+			sslMode = "disable"
+		}
+		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s TimeZone=UTC",
+			cfg.Host, cfg.Port, cfg.User, cfg.Pass, cfg.DBName, sslMode)
+		return postgres.Open(dsn), nil
+	case DriverSqlite:
+		// DBName is the file path
+		dsn := cfg.DBName
+		if len(dsn) == 0 {
+			return nil, fmt.Errorf("sqlite file path is empty")
+		}
+		if dsn == SqliteMemory {
+			dsn = "file::memory:"
+		}
+		return sqlite.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("db driver %s not support", cfg.Driver)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2021 stafiprotocol
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package event
+
+import (
+	"fmt"
+	"sync"
+)
+
+const (
+	TypeAnnualRate         = "annualRate"
+	TypeSelectedValidators = "selectedValidators"
+)
+
+type Event struct {
+	Type  string      `json:"type"`
+	Denom string      `json:"denom"`
+	Data  interface{} `json:"data"`
+}
+
+type AnnualRateData struct {
+	AnnualRate string `json:"annualRate"`
+}
+
+type SelectedValidatorsData struct {
+	Added   []SelectedValidator `json:"added"`
+	Removed []SelectedValidator `json:"removed"`
+}
+
+type SelectedValidator struct {
+	PoolAddress      string `json:"poolAddress"`
+	ValidatorAddress string `json:"validatorAddress"`
+	Moniker          string `json:"moniker"`
+}
+
+// Subscriber receives events of the denoms it subscribed, all denoms if none
+type Subscriber struct {
+	denoms map[string]bool
+	events chan Event
+}
+
+// Events is closed when subscriber is unsubscribed or dropped for being too slow
+func (s *Subscriber) Events() <-chan Event {
+	return s.events
+}
+
+func (s *Subscriber) wants(denom string) bool {
+	return len(s.denoms) == 0 || s.denoms[denom]
+}
+
+// Broker fans out published events to subscribers without blocking the publisher,
+// a subscriber whose buffer is full is dropped and is expected to reconnect.
+type Broker struct {
+	mutex          sync.Mutex
+	subscribers    map[*Subscriber]struct{}
+	bufferSize     int
+	maxSubscribers int
+	closed         bool
+}
+
+func NewBroker(bufferSize, maxSubscribers int) *Broker {
+	return &Broker{
+		subscribers:    make(map[*Subscriber]struct{}),
+		bufferSize:     bufferSize,
+		maxSubscribers: maxSubscribers,
+	}
+}
+
+func (b *Broker) Subscribe(denoms []string) (*Subscriber, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return nil, fmt.Errorf("broker is closed")
+	}
+	if len(b.subscribers) >= b.maxSubscribers {
+		return nil, fmt.Errorf("subscribers reach limit %d", b.maxSubscribers)
+	}
+	s := &Subscriber{
+		denoms: make(map[string]bool),
+		events: make(chan Event, b.bufferSize),
+	}
+	for _, denom := range denoms {
+		s.denoms[denom] = true
+	}
+	b.subscribers[s] = struct{}{}
+	return s, nil
+}
+
+func (b *Broker) Unsubscribe(s *Subscriber) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.remove(s)
+}
+
+func (b *Broker) Publish(e Event) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for s := range b.subscribers {
+		if !s.wants(e.Denom) {
+			continue
+		}
+		select {
+		case s.events <- e:
+		default:
+			b.remove(s)
+		}
+	}
+}
+
+// Close drops all subscribers so that streams end, new subscriptions are rejected
+func (b *Broker) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.closed = true
+	for s := range b.subscribers {
+		b.remove(s)
+	}
+}
+
+func (b *Broker) remove(s *Subscriber) {
+	if _, exist := b.subscribers[s]; !exist {
+		return
+	}
+	delete(b.subscribers, s)
+	close(s.events)
+}
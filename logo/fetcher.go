@@ -0,0 +1,39 @@
+// Copyright 2021 stafiprotocol
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package logo
+
+import (
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// Fetcher gets resources needed to resolve logos, can be replaced to use proxies or in tests
+type Fetcher interface {
+	// Get returns status code and body of url
+	Get(url string) (int, []byte, error)
+}
+
+type HttpFetcher struct {
+	client *http.Client
+}
+
+func NewHttpFetcher(timeout time.Duration) *HttpFetcher {
+	return &HttpFetcher{
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (f *HttpFetcher) Get(url string) (int, []byte, error) {
+	rsp, err := f.client.Get(url)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rsp.Body.Close()
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return rsp.StatusCode, bodyBytes, nil
+}
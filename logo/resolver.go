@@ -0,0 +1,111 @@
+// Copyright 2021 stafiprotocol
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package logo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	SourceTemplate = "template"
+	SourceKeybase  = "keybase"
+	SourceDefault  = "default"
+
+	// placeholder of validator address in url template
+	AddressPlaceholder = "{address}"
+
+	DefaultKeybaseUrl = "https://keybase.io/_/api/1.0/user/lookup.json?key_suffix=%s&fields=pictures"
+)
+
+// Resolver resolves logo url of validator, it tries the url template of denom first,
+// then the keybase picture of validator's identity, and falls back to the default logo.
+type Resolver struct {
+	fetcher        Fetcher
+	urlTemplates   map[string]string // denom -> url template
+	keybaseUrl     string
+	defaultLogoUrl string
+}
+
+func NewResolver(fetcher Fetcher, urlTemplates map[string]string, keybaseUrl, defaultLogoUrl string) *Resolver {
+	if len(keybaseUrl) == 0 {
+		keybaseUrl = DefaultKeybaseUrl
+	}
+	return &Resolver{
+		fetcher:        fetcher,
+		urlTemplates:   urlTemplates,
+		keybaseUrl:     keybaseUrl,
+		defaultLogoUrl: defaultLogoUrl,
+	}
+}
+
+func (r *Resolver) DefaultLogoUrl() string {
+	return r.defaultLogoUrl
+}
+
+// Resolve returns logo url and its source, err is returned only if the
+// lookups failed unexpectedly so the caller can retry later
+func (r *Resolver) Resolve(denom, validatorAddress, identity string) (string, string, error) {
+	if template := r.urlTemplates[denom]; len(template) != 0 {
+		logoUrl := strings.ReplaceAll(template, AddressPlaceholder, validatorAddress)
+		status, _, err := r.fetcher.Get(logoUrl)
+		if err != nil {
+			return "", "", err
+		}
+		if status == http.StatusOK {
+			return logoUrl, SourceTemplate, nil
+		}
+	}
+
+	if len(identity) != 0 {
+		logoUrl, err := r.getKeybasePicture(identity)
+		if err != nil {
+			return "", "", err
+		}
+		if len(logoUrl) != 0 {
+			return logoUrl, SourceKeybase, nil
+		}
+	}
+
+	return r.defaultLogoUrl, SourceDefault, nil
+}
+
+type rspKeybaseLookup struct {
+	Status struct {
+		Code int `json:"code"`
+	} `json:"status"`
+	Them []struct {
+		Pictures struct {
+			Primary struct {
+				Url string `json:"url"`
+			} `json:"primary"`
+		} `json:"pictures"`
+	} `json:"them"`
+}
+
+// getKeybasePicture returns empty url if identity has no picture
+func (r *Resolver) getKeybasePicture(identity string) (string, error) {
+	status, body, err := r.fetcher.Get(fmt.Sprintf(r.keybaseUrl, url.QueryEscape(identity)))
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusOK {
+		return "", fmt.Errorf("keybase lookup status err %d", status)
+	}
+
+	rsp := rspKeybaseLookup{}
+	err = json.Unmarshal(body, &rsp)
+	if err != nil {
+		return "", err
+	}
+	for _, them := range rsp.Them {
+		if len(them.Pictures.Primary.Url) != 0 {
+			return them.Pictures.Primary.Url, nil
+		}
+	}
+	return "", nil
+}
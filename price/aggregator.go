@@ -0,0 +1,116 @@
+// Copyright 2021 stafiprotocol
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package price
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const refreshSlack = time.Second
+
+type Price struct {
+	Denom     string
+	Usd       float64
+	Source    string
+	UpdatedAt time.Time
+}
+
+// Aggregator caches prices got from providers, providers are used in order and
+// the next one is used as fallback for denoms the previous ones can't price.
+// A cached price is used when all providers fail, until it's older than maxStale.
+type Aggregator struct {
+	providers     []PriceProvider
+	denoms        []string
+	cacheDuration time.Duration
+	maxStale      time.Duration
+
+	refreshMutex sync.Mutex
+	mutex        sync.RWMutex
+	prices       map[string]Price
+	lastRefresh  time.Time
+}
+
+func NewAggregator(providers []PriceProvider, denoms []string, cacheDuration, maxStale time.Duration) *Aggregator {
+	if maxStale < cacheDuration {
+		maxStale = cacheDuration
+	}
+	return &Aggregator{
+		providers:     providers,
+		denoms:        denoms,
+		cacheDuration: cacheDuration,
+		maxStale:      maxStale,
+		prices:        make(map[string]Price),
+	}
+}
+
+// GetPrice returns the cached price of denom, it never calls providers, the cache is
+// filled by Refresh
+func (a *Aggregator) GetPrice(denom string) (Price, error) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	price, exist := a.prices[denom]
+	if !exist {
+		return Price{}, fmt.Errorf("price of %s not exist", denom)
+	}
+	if time.Since(price.UpdatedAt) > a.maxStale {
+		return Price{}, fmt.Errorf("price of %s is stale, updated at %s", denom, price.UpdatedAt)
+	}
+	return price, nil
+}
+
+func (a *Aggregator) needRefresh() bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	// slack keeps a ticker with the same period as cacheDuration from skipping every other refresh
+	return time.Since(a.lastRefresh) >= a.cacheDuration-refreshSlack
+}
+
+// Refresh gets prices from providers if cache expired, it's called by the update loop
+// so requests never wait for providers
+func (a *Aggregator) Refresh() {
+	a.refreshMutex.Lock()
+	defer a.refreshMutex.Unlock()
+	if !a.needRefresh() {
+		return
+	}
+
+	now := time.Now()
+	newPrices := make(map[string]Price)
+	for _, provider := range a.providers {
+		missed := make([]string, 0)
+		for _, denom := range a.denoms {
+			if _, exist := newPrices[denom]; !exist {
+				missed = append(missed, denom)
+			}
+		}
+		if len(missed) == 0 {
+			break
+		}
+
+		prices, err := provider.GetPrices(missed)
+		if err != nil {
+			logrus.Warnf("price provider %s GetPrices err: %s", provider.Name(), err)
+			continue
+		}
+		for denom, usd := range prices {
+			newPrices[denom] = Price{
+				Denom:     denom,
+				Usd:       usd,
+				Source:    provider.Name(),
+				UpdatedAt: now,
+			}
+		}
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	for denom, price := range newPrices {
+		a.prices[denom] = price
+	}
+	a.lastRefresh = now
+}
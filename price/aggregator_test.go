@@ -0,0 +1,140 @@
+package price
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// switchServer serves body while ok is set and 500 otherwise, calls counts requests
+func switchServer(t *testing.T, body string, ok *int32, calls *int64) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(calls, 1)
+		if atomic.LoadInt32(ok) == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAggregatorFallbackOrder(t *testing.T) {
+	var geckoOk, marketOk int32
+	var geckoCalls, marketCalls int64
+	gecko := switchServer(t, `{"cosmos":{"usd":10}}`, &geckoOk, &geckoCalls)
+	market := switchServer(t, `{"data":{"ATOM":{"quote":{"USD":{"price":11}}},"IRIS":{"quote":{"USD":{"price":0.05}}}}}`, &marketOk, &marketCalls)
+
+	providers := []PriceProvider{
+		NewCoinGeckoProvider(gecko.URL+"/?ids=%s", map[string]string{"uratom": "cosmos", "uriris": "iris-network"}),
+		NewCoinMarketProvider(market.URL+"/?symbol=%s", map[string]string{"uratom": "ATOM", "uriris": "IRIS"}),
+	}
+
+	tests := []struct {
+		name       string
+		geckoOk    bool
+		marketOk   bool
+		wantSource map[string]string // denom -> source, empty source means no price
+	}{
+		{
+			name:       "first provider wins, next one prices the rest",
+			geckoOk:    true,
+			marketOk:   true,
+			wantSource: map[string]string{"uratom": "coingecko", "uriris": "coinmarketcap"},
+		},
+		{
+			name:       "next provider is used when first fails",
+			geckoOk:    false,
+			marketOk:   true,
+			wantSource: map[string]string{"uratom": "coinmarketcap", "uriris": "coinmarketcap"},
+		},
+		{
+			name:       "no price when all fail",
+			geckoOk:    false,
+			marketOk:   false,
+			wantSource: map[string]string{"uratom": "", "uriris": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&geckoOk, boolToInt32(tt.geckoOk))
+			atomic.StoreInt32(&marketOk, boolToInt32(tt.marketOk))
+			aggregator := NewAggregator(providers, []string{"uratom", "uriris"}, time.Minute, time.Hour)
+			aggregator.Refresh()
+
+			for denom, source := range tt.wantSource {
+				price, err := aggregator.GetPrice(denom)
+				if len(source) == 0 {
+					if err == nil {
+						t.Errorf("GetPrice(%s) want err, got %+v", denom, price)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("GetPrice(%s) err: %s", denom, err)
+				}
+				if price.Source != source {
+					t.Errorf("source of %s %s, want %s", denom, price.Source, source)
+				}
+			}
+		})
+	}
+}
+
+func TestAggregatorCacheAndStaleness(t *testing.T) {
+	var ok int32
+	var calls int64
+	atomic.StoreInt32(&ok, 1)
+	server := switchServer(t, `{"cosmos":{"usd":10}}`, &ok, &calls)
+	providers := []PriceProvider{NewCoinGeckoProvider(server.URL+"/?ids=%s", map[string]string{"uratom": "cosmos"})}
+
+	// GetPrice never calls providers
+	aggregator := NewAggregator(providers, []string{"uratom"}, time.Hour, time.Hour)
+	if _, err := aggregator.GetPrice("uratom"); err == nil {
+		t.Fatal("GetPrice before Refresh want err")
+	}
+	if atomic.LoadInt64(&calls) != 0 {
+		t.Fatalf("GetPrice called provider %d times", atomic.LoadInt64(&calls))
+	}
+
+	// Refresh calls providers at most once in cacheDuration
+	aggregator.Refresh()
+	aggregator.Refresh()
+	if atomic.LoadInt64(&calls) != 1 {
+		t.Fatalf("provider called %d times, want 1", atomic.LoadInt64(&calls))
+	}
+	if price, err := aggregator.GetPrice("uratom"); err != nil || price.Usd != 10 {
+		t.Fatalf("GetPrice got %+v, %v", price, err)
+	}
+
+	// cached price is used while providers fail until it's older than maxStale
+	atomic.StoreInt64(&calls, 0)
+	maxStale := 300 * time.Millisecond
+	aggregator = NewAggregator(providers, []string{"uratom"}, 0, maxStale)
+	aggregator.Refresh()
+	atomic.StoreInt32(&ok, 0)
+	aggregator.Refresh()
+	if atomic.LoadInt64(&calls) != 2 {
+		t.Fatalf("provider called %d times, want 2", atomic.LoadInt64(&calls))
+	}
+	if _, err := aggregator.GetPrice("uratom"); err != nil {
+		t.Fatalf("GetPrice within maxStale err: %s", err)
+	}
+	time.Sleep(maxStale + 50*time.Millisecond)
+	aggregator.Refresh()
+	if _, err := aggregator.GetPrice("uratom"); err == nil {
+		t.Fatal("GetPrice older than maxStale want err")
+	}
+}
+
+func boolToInt32(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
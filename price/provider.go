@@ -0,0 +1,97 @@
+// Copyright 2021 stafiprotocol
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package price
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stafihub/staking-election/utils"
+)
+
+// PriceProvider fetches usd prices of rtoken denoms from one price source
+type PriceProvider interface {
+	Name() string
+	// GetPrices returns usd prices keyed by denom, denoms without price are omitted
+	GetPrices(denoms []string) (map[string]float64, error)
+}
+
+// CoinGeckoProvider gets prices from coingecko simple price api,
+// urlTemplate must contain one %s which will be replaced by comma separated coin ids, such as:
+// https://api.coingecko.com/api/v3/simple/price?ids=%s&vs_currencies=usd
+type CoinGeckoProvider struct {
+	urlTemplate string
+	coinIds     map[string]string // denom -> coingecko id
+}
+
+func NewCoinGeckoProvider(urlTemplate string, coinIds map[string]string) *CoinGeckoProvider {
+	return &CoinGeckoProvider{
+		urlTemplate: urlTemplate,
+		coinIds:     coinIds,
+	}
+}
+
+func (p *CoinGeckoProvider) Name() string {
+	return "coingecko"
+}
+
+func (p *CoinGeckoProvider) GetPrices(denoms []string) (map[string]float64, error) {
+	return getPricesByKey(denoms, p.coinIds, p.urlTemplate, utils.GetPriceFromCoinGecko)
+}
+
+// CoinMarketProvider gets prices from coinmarketcap quotes api,
+// urlTemplate must contain one %s which will be replaced by comma separated symbols, such as:
+// https://pro-api.coinmarketcap.com/v1/cryptocurrency/quotes/latest?symbol=%s&CMC_PRO_API_KEY=xxx
+type CoinMarketProvider struct {
+	urlTemplate string
+	symbols     map[string]string // denom -> coinmarketcap symbol
+}
+
+func NewCoinMarketProvider(urlTemplate string, symbols map[string]string) *CoinMarketProvider {
+	return &CoinMarketProvider{
+		urlTemplate: urlTemplate,
+		symbols:     symbols,
+	}
+}
+
+func (p *CoinMarketProvider) Name() string {
+	return "coinmarketcap"
+}
+
+func (p *CoinMarketProvider) GetPrices(denoms []string) (map[string]float64, error) {
+	return getPricesByKey(denoms, p.symbols, p.urlTemplate, utils.GetPriceFromCoinMarket)
+}
+
+// getPricesByKey maps denoms to the keys used by a price source, fetches prices
+// of these keys and maps them back to denoms
+func getPricesByKey(denoms []string, keyOfDenom map[string]string, urlTemplate string,
+	fetch func(url string) (map[string]float64, error)) (map[string]float64, error) {
+
+	keys := make([]string, 0)
+	for _, denom := range denoms {
+		if key, exist := keyOfDenom[denom]; exist && len(key) != 0 {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return map[string]float64{}, nil
+	}
+
+	prices, err := fetch(fmt.Sprintf(urlTemplate, strings.Join(keys, ",")))
+	if err != nil {
+		return nil, err
+	}
+
+	retPrices := make(map[string]float64)
+	for _, denom := range denoms {
+		key, exist := keyOfDenom[denom]
+		if !exist || len(key) == 0 {
+			continue
+		}
+		if price, exist := prices[key]; exist && price > 0 {
+			retPrices[denom] = price
+		}
+	}
+	return retPrices, nil
+}
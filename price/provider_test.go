@@ -0,0 +1,192 @@
+package price
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// priceServer serves body with status and records the last requested ids or symbols
+func priceServer(t *testing.T, status int, body string, lastKeys *string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if lastKeys != nil {
+			*lastKeys = r.URL.Query().Get("keys")
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCoinGeckoProvider(t *testing.T) {
+	coinIds := map[string]string{"uratom": "cosmos", "uriris": "iris-network", "urnone": ""}
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		denoms   []string
+		wantKeys string
+		want     map[string]float64
+		wantErr  bool
+	}{
+		{
+			name:     "prices of known ids",
+			status:   http.StatusOK,
+			body:     `{"cosmos":{"usd":10.5},"iris-network":{"usd":0.03}}`,
+			denoms:   []string{"uratom", "uriris"},
+			wantKeys: "cosmos,iris-network",
+			want:     map[string]float64{"uratom": 10.5, "uriris": 0.03},
+		},
+		{
+			name:     "id missing in response is omitted",
+			status:   http.StatusOK,
+			body:     `{"cosmos":{"usd":10.5}}`,
+			denoms:   []string{"uratom", "uriris"},
+			wantKeys: "cosmos,iris-network",
+			want:     map[string]float64{"uratom": 10.5},
+		},
+		{
+			name:   "denom without id is not requested",
+			status: http.StatusOK,
+			body:   `{}`,
+			denoms: []string{"urnone", "urunknown"},
+			want:   map[string]float64{},
+		},
+		{
+			name:     "zero price is omitted",
+			status:   http.StatusOK,
+			body:     `{"cosmos":{"usd":0}}`,
+			denoms:   []string{"uratom"},
+			wantKeys: "cosmos",
+			want:     map[string]float64{},
+		},
+		{
+			name:     "status err",
+			status:   http.StatusTooManyRequests,
+			body:     `{"error":"rate limited"}`,
+			denoms:   []string{"uratom"},
+			wantKeys: "cosmos",
+			wantErr:  true,
+		},
+		{
+			name:     "empty body",
+			status:   http.StatusOK,
+			denoms:   []string{"uratom"},
+			wantKeys: "cosmos",
+			wantErr:  true,
+		},
+		{
+			name:     "invalid body",
+			status:   http.StatusOK,
+			body:     `<html>`,
+			denoms:   []string{"uratom"},
+			wantKeys: "cosmos",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keys string
+			server := priceServer(t, tt.status, tt.body, &keys)
+			provider := NewCoinGeckoProvider(server.URL+"/simple/price?keys=%s&vs_currencies=usd", coinIds)
+
+			got, err := provider.GetPrices(tt.denoms)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("GetPrices want err, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetPrices err: %s", err)
+			}
+			if keys != tt.wantKeys {
+				t.Errorf("requested ids %q, want %q", keys, tt.wantKeys)
+			}
+			assertPrices(t, got, tt.want)
+		})
+	}
+}
+
+func TestCoinMarketProvider(t *testing.T) {
+	symbols := map[string]string{"uratom": "ATOM", "uriris": "IRIS"}
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		denoms   []string
+		wantKeys string
+		want     map[string]float64
+		wantErr  bool
+	}{
+		{
+			name:     "prices of known symbols",
+			status:   http.StatusOK,
+			body:     `{"data":{"ATOM":{"symbol":"ATOM","quote":{"USD":{"price":10.5}}},"IRIS":{"symbol":"IRIS","quote":{"USD":{"price":0.03}}}}}`,
+			denoms:   []string{"uratom", "uriris"},
+			wantKeys: "ATOM,IRIS",
+			want:     map[string]float64{"uratom": 10.5, "uriris": 0.03},
+		},
+		{
+			name:     "symbol missing in response is omitted",
+			status:   http.StatusOK,
+			body:     `{"data":{"ATOM":{"symbol":"ATOM","quote":{"USD":{"price":10.5}}}}}`,
+			denoms:   []string{"uratom", "uriris"},
+			wantKeys: "ATOM,IRIS",
+			want:     map[string]float64{"uratom": 10.5},
+		},
+		{
+			name:     "status err",
+			status:   http.StatusUnauthorized,
+			body:     `{"status":{"error_code":1002,"error_message":"API key missing."}}`,
+			denoms:   []string{"uratom"},
+			wantKeys: "ATOM",
+			wantErr:  true,
+		},
+		{
+			name:     "empty body",
+			status:   http.StatusOK,
+			denoms:   []string{"uratom"},
+			wantKeys: "ATOM",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keys string
+			server := priceServer(t, tt.status, tt.body, &keys)
+			provider := NewCoinMarketProvider(server.URL+"/quotes/latest?keys=%s", symbols)
+
+			got, err := provider.GetPrices(tt.denoms)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("GetPrices want err, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetPrices err: %s", err)
+			}
+			if keys != tt.wantKeys {
+				t.Errorf("requested symbols %q, want %q", keys, tt.wantKeys)
+			}
+			assertPrices(t, got, tt.want)
+		})
+	}
+}
+
+func assertPrices(t *testing.T, got, want map[string]float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("prices %v, want %v", got, want)
+	}
+	for denom, usd := range want {
+		if got[denom] != usd {
+			t.Errorf("price of %s %v, want %v", denom, got[denom], usd)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 stafiprotocol
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package retention
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stafihub/staking-election/config"
+	"github.com/stafihub/staking-election/dao/election"
+	"github.com/stafihub/staking-election/db"
+)
+
+const (
+	ActionExpire     = "expire"
+	ActionDownsample = "downsample"
+
+	secondsPerDay = 24 * 60 * 60
+	scanBatchSize = 1000
+)
+
+type Rule struct {
+	Table           string
+	Ttl             time.Duration // rows older than ttl are deleted, 0 keeps them forever
+	DownsampleAfter time.Duration // election_record only, 0 disables downsampling
+}
+
+// Result is what a rule deleted, or would delete in dry run
+type Result struct {
+	Table  string
+	Action string
+	Before time.Time // rows created before it are affected
+	Rows   int64
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("%-28s %-10s rows created before %s: %d", r.Table, r.Action, r.Before.UTC().Format(time.RFC3339), r.Rows)
+}
+
+// RulesFromConfig converts and checks retention rules of config
+func RulesFromConfig(cfg config.Retention) ([]Rule, error) {
+	rules := make([]Rule, 0, len(cfg.RuleList))
+	seen := make(map[string]bool)
+	for _, r := range cfg.RuleList {
+		if _, exist := dao_election.RetentionModels[r.Table]; !exist {
+			return nil, fmt.Errorf("retention table %s not support", r.Table)
+		}
+		if seen[r.Table] {
+			return nil, fmt.Errorf("retention table %s duplicated", r.Table)
+		}
+		seen[r.Table] = true
+		if r.TtlDays < 0 || r.DownsampleAfterDays < 0 {
+			return nil, fmt.Errorf("retention days of %s can't be negative", r.Table)
+		}
+		if r.DownsampleAfterDays != 0 && r.Table != dao_election.RetentionTableElectionRecord {
+			return nil, fmt.Errorf("downsampling is only supported by %s", dao_election.RetentionTableElectionRecord)
+		}
+		if r.TtlDays != 0 && r.DownsampleAfterDays >= r.TtlDays {
+			return nil, fmt.Errorf("downsampleAfterDays of %s should be less than ttlDays", r.Table)
+		}
+		rules = append(rules, Rule{
+			Table:           r.Table,
+			Ttl:             time.Duration(r.TtlDays) * secondsPerDay * time.Second,
+			DownsampleAfter: time.Duration(r.DownsampleAfterDays) * secondsPerDay * time.Second,
+		})
+	}
+	return rules, nil
+}
+
+// Prune applies rules at now, in dry run it only counts the rows which would be deleted
+func Prune(db *db.WrapDb, rules []Rule, now time.Time, dryRun bool) ([]Result, error) {
+	results := make([]Result, 0)
+	for _, rule := range rules {
+		model := dao_election.RetentionModels[rule.Table]
+
+		if rule.Ttl > 0 {
+			before := now.Add(-rule.Ttl)
+			var rows int64
+			var err error
+			if dryRun {
+				rows, err = dao_election.CountCreatedBefore(db, model, before.Unix())
+			} else {
+				rows, err = dao_election.DeleteCreatedBefore(db, model, before.Unix())
+			}
+			if err != nil {
+				return results, fmt.Errorf("expire %s err: %s", rule.Table, err)
+			}
+			results = append(results, Result{Table: rule.Table, Action: ActionExpire, Before: before, Rows: rows})
+		}
+
+		if rule.DownsampleAfter > 0 {
+			before := now.Add(-rule.DownsampleAfter)
+			rows, err := downsampleElectionRecords(db, before.Unix(), dryRun)
+			if err != nil {
+				return results, fmt.Errorf("downsample %s err: %s", rule.Table, err)
+			}
+			results = append(results, Result{Table: rule.Table, Action: ActionDownsample, Before: before, Rows: rows})
+		}
+	}
+	return results, nil
+}
+
+// downsampleElectionRecords keeps the first `keep` decision of each pool per day,
+// decisions which changed rValidators are always kept
+func downsampleElectionRecords(db *db.WrapDb, before int64, dryRun bool) (int64, error) {
+	kept := make(map[string]bool) // denom + pool + day
+	removeIds := make([]int64, 0)
+	afterId := int64(0)
+	for {
+		briefs, err := dao_election.GetKeepElectionRecordBriefsBefore(db, before, afterId, scanBatchSize)
+		if err != nil {
+			return 0, err
+		}
+		for _, brief := range briefs {
+			key := fmt.Sprintf("%s/%s/%d", brief.RTokenDenom, brief.PoolAddress, brief.CreatedAt/secondsPerDay)
+			if kept[key] {
+				removeIds = append(removeIds, brief.ID)
+				continue
+			}
+			kept[key] = true
+		}
+		if len(briefs) < scanBatchSize {
+			break
+		}
+		afterId = briefs[len(briefs)-1].ID
+	}
+
+	if dryRun {
+		return int64(len(removeIds)), nil
+	}
+	return dao_election.DeleteElectionRecordsByIds(db, removeIds)
+}
@@ -0,0 +1,194 @@
+package retention
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stafihub/staking-election/config"
+	"github.com/stafihub/staking-election/dao/election"
+	"github.com/stafihub/staking-election/dao/migrate"
+	"github.com/stafihub/staking-election/db"
+)
+
+var testNow = time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+// newRecordDb inserts election records, each is denom/pool/decision/seconds after the day 5 days ago
+func newRecordDb(t *testing.T, records ...string) *db.WrapDb {
+	t.Helper()
+	wrapDb, err := db.NewDB(&db.Config{Driver: db.DriverSqlite, DBName: db.SqliteMemory})
+	if err != nil {
+		t.Fatalf("NewDB err: %s", err)
+	}
+	t.Cleanup(func() {
+		if sqlDb, err := wrapDb.DB.DB(); err == nil {
+			sqlDb.Close()
+		}
+	})
+	if err := migrate.Up(wrapDb); err != nil {
+		t.Fatalf("migrate err: %s", err)
+	}
+
+	day := testNow.Add(-5 * secondsPerDay * time.Second).Unix()
+	for i, r := range records {
+		var denom, pool, decision string
+		var seconds int64
+		if _, err := fmt.Sscanf(strings.ReplaceAll(r, "/", " "), "%s %s %s %d", &denom, &pool, &decision, &seconds); err != nil {
+			t.Fatalf("record %s: %s", r, err)
+		}
+		record := &dao_election.ElectionRecord{
+			RTokenDenom: denom,
+			PoolAddress: pool,
+			CycleNumber: uint64(i + 1),
+			Decision:    decision,
+		}
+		record.CreatedAt = int(day + seconds)
+		if err := dao_election.UpOrInElectionRecord(wrapDb, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return wrapDb
+}
+
+// remainingCycles returns cycle numbers of remaining records, they are the insert order
+func remainingCycles(t *testing.T, wrapDb *db.WrapDb) string {
+	t.Helper()
+	var cycles []uint64
+	if err := wrapDb.Model(&dao_election.ElectionRecord{}).Order("cycle_number asc").Pluck("cycle_number", &cycles).Error; err != nil {
+		t.Fatal(err)
+	}
+	return fmt.Sprint(cycles)
+}
+
+var testRecords = []string{
+	"uratom/poolA/keep/100",               // 1 first keep of the day
+	"uratom/poolA/keep/200",               // 2 downsampled
+	"uratom/poolA/redelegate/300",         // 3 not a keep decision
+	"uratom/poolB/keep/400",               // 4 another pool
+	"uriris/poolA/keep/500",               // 5 another denom
+	"uratom/poolA/keep/86410",             // 6 first keep of the next day
+	"uratom/poolA/keep/86420",             // 7 downsampled
+	"uratom/poolA/keep/345610",            // 8 within downsampleAfter
+	"uratom/poolA/keep/345620",            // 9 within downsampleAfter
+	"uratom/poolA/notEnoughCandidate/600", // 10 not a keep decision
+}
+
+func TestPruneDownsample(t *testing.T) {
+	wrapDb := newRecordDb(t, testRecords...)
+	rules := []Rule{{Table: dao_election.RetentionTableElectionRecord, DownsampleAfter: 2 * secondsPerDay * time.Second}}
+
+	results, err := Prune(wrapDb, rules, testNow, true)
+	if err != nil {
+		t.Fatalf("dry run Prune err: %s", err)
+	}
+	if len(results) != 1 || results[0].Action != ActionDownsample || results[0].Rows != 2 {
+		t.Fatalf("dry run results %v, want 2 downsampled", results)
+	}
+	if got := remainingCycles(t, wrapDb); got != "[1 2 3 4 5 6 7 8 9 10]" {
+		t.Fatalf("dry run deleted records, remaining %s", got)
+	}
+
+	results, err = Prune(wrapDb, rules, testNow, false)
+	if err != nil {
+		t.Fatalf("Prune err: %s", err)
+	}
+	if len(results) != 1 || results[0].Rows != 2 || !results[0].Before.Equal(testNow.Add(-48*time.Hour)) {
+		t.Fatalf("results %v, want 2 downsampled before %s", results, testNow.Add(-48*time.Hour))
+	}
+	if got := remainingCycles(t, wrapDb); got != "[1 3 4 5 6 8 9 10]" {
+		t.Errorf("remaining %s", got)
+	}
+
+	// downsampled records are stable
+	results, err = Prune(wrapDb, rules, testNow, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Rows != 0 {
+		t.Errorf("second downsampling deleted %d", results[0].Rows)
+	}
+}
+
+func TestPruneExpire(t *testing.T) {
+	wrapDb := newRecordDb(t, testRecords...)
+	rules := []Rule{{
+		Table:           dao_election.RetentionTableElectionRecord,
+		Ttl:             3 * secondsPerDay * time.Second,
+		DownsampleAfter: 2 * secondsPerDay * time.Second,
+	}}
+
+	results, err := Prune(wrapDb, rules, testNow, false)
+	if err != nil {
+		t.Fatalf("Prune err: %s", err)
+	}
+	// expiring runs first, nothing is left to downsample
+	if len(results) != 2 || results[0].Action != ActionExpire || results[0].Rows != 8 || results[1].Rows != 0 {
+		t.Fatalf("results %v, want 8 expired and 0 downsampled", results)
+	}
+	if got := remainingCycles(t, wrapDb); got != "[8 9]" {
+		t.Errorf("remaining %s", got)
+	}
+}
+
+func TestRulesFromConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []config.RetentionRule
+		wantErr string
+	}{
+		{
+			name: "valid",
+			rules: []config.RetentionRule{
+				{Table: dao_election.RetentionTableElectionRecord, TtlDays: 90, DownsampleAfterDays: 7},
+				{Table: dao_election.RetentionTableAdminAudit, TtlDays: 365},
+			},
+		},
+		{
+			name:    "unknown table",
+			rules:   []config.RetentionRule{{Table: "staking_election_annual_rate", TtlDays: 1}},
+			wantErr: "not support",
+		},
+		{
+			name: "duplicated table",
+			rules: []config.RetentionRule{
+				{Table: dao_election.RetentionTableAdminAudit, TtlDays: 1},
+				{Table: dao_election.RetentionTableAdminAudit, TtlDays: 2},
+			},
+			wantErr: "duplicated",
+		},
+		{
+			name:    "negative days",
+			rules:   []config.RetentionRule{{Table: dao_election.RetentionTableAdminAudit, TtlDays: -1}},
+			wantErr: "negative",
+		},
+		{
+			name:    "downsampling other table",
+			rules:   []config.RetentionRule{{Table: dao_election.RetentionTableRedelegation, DownsampleAfterDays: 1}},
+			wantErr: "only supported",
+		},
+		{
+			name:    "downsampling after ttl",
+			rules:   []config.RetentionRule{{Table: dao_election.RetentionTableElectionRecord, TtlDays: 7, DownsampleAfterDays: 7}},
+			wantErr: "less than ttlDays",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := RulesFromConfig(config.Retention{RuleList: tt.rules})
+			if len(tt.wantErr) != 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("RulesFromConfig err %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RulesFromConfig err: %s", err)
+			}
+			if len(rules) != 2 || rules[0].Ttl != 90*24*time.Hour || rules[0].DownsampleAfter != 7*24*time.Hour {
+				t.Errorf("rules %+v", rules)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/sirupsen/logrus"
+	stafiHubXRValidatorTypes "github.com/stafihub/stafihub/x/rvalidator/types"
+	"github.com/stafihub/staking-election/dao/election"
+	"gorm.io/gorm"
+)
+
+const redelegationTxsPageLimit = 50
+
+// txSearcher is the part of stafihub client the indexer needs, it can be replaced in tests
+type txSearcher interface {
+	GetTxs(events []string, page, limit int, orderBy string) (*sdk.SearchTxsResult, error)
+}
+
+// index executed rvalidator updates of each denom from stafihub txs
+func (svr *Server) updateRedelegation() error {
+	for _, rtokenInfo := range svr.cfg.RTokenInfo {
+		if err := svr.indexRedelegation(svr.stafihubClient, rtokenInfo.Denom); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (svr *Server) indexRedelegation(searcher txSearcher, denom string) error {
+	// resume after the cursor rather than the latest redelegation, which can be pruned
+	cursor, err := dao_election.GetRedelegationCursor(svr.db, denom)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return err
+	}
+	cursor.RTokenDenom = denom
+	fromHeight := int64(0)
+	if err == nil {
+		fromHeight = cursor.Height + 1
+	}
+	indexedHeight := cursor.Height
+	events := []string{
+		fmt.Sprintf("%s.%s='%s'", stafiHubXRValidatorTypes.EventTypeUpdateRValidator, stafiHubXRValidatorTypes.AttributeKeyDenom, denom),
+		fmt.Sprintf("tx.height>=%d", fromHeight),
+	}
+
+	for page := 1; ; page++ {
+		txsRes, err := searcher.GetTxs(events, page, redelegationTxsPageLimit, "asc")
+		if err != nil {
+			return err
+		}
+
+		for _, tx := range txsRes.Txs {
+			if tx.Height > indexedHeight {
+				indexedHeight = tx.Height
+			}
+			if tx.Code != 0 {
+				continue
+			}
+			for _, log := range tx.Logs {
+				for _, event := range log.Events {
+					if event.Type != stafiHubXRValidatorTypes.EventTypeUpdateRValidator {
+						continue
+					}
+					redelegations, err := parseUpdateRValidatorEvent(event)
+					if err != nil {
+						return fmt.Errorf("parseUpdateRValidatorEvent of tx %s err: %s", tx.TxHash, err)
+					}
+
+					for _, redelegation := range redelegations {
+						if redelegation.RTokenDenom != denom {
+							continue
+						}
+						redelegation.Height = tx.Height
+						redelegation.TxHash = tx.TxHash
+
+						err = svr.saveRedelegation(redelegation)
+						if err != nil {
+							return err
+						}
+					}
+				}
+			}
+		}
+
+		if uint64(page*redelegationTxsPageLimit) >= txsRes.TotalCount {
+			break
+		}
+	}
+
+	// a block's txs are searchable all at once, so heights passed are fully indexed,
+	// a failed pass leaves the cursor and saved redelegations are skipped next time
+	if indexedHeight == cursor.Height {
+		return nil
+	}
+	cursor.Height = indexedHeight
+	return dao_election.UpOrInRedelegationCursor(svr.db, cursor)
+}
+
+func (svr *Server) saveRedelegation(redelegation *dao_election.Redelegation) error {
+	_, err := dao_election.GetRedelegation(svr.db, redelegation.TxHash, redelegation.PoolAddress, redelegation.OldValidator)
+	if err == nil {
+		return nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"denom":        redelegation.RTokenDenom,
+		"pool":         redelegation.PoolAddress,
+		"oldValidator": redelegation.OldValidator,
+		"newValidator": redelegation.NewValidator,
+		"cycleVersion": redelegation.CycleVersion,
+		"cycleNumber":  redelegation.CycleNumber,
+		"height":       redelegation.Height,
+	}).Info("redelegation indexed")
+	return dao_election.UpOrInRedelegation(svr.db, redelegation)
+}
+
+// events of the same type in one msg are merged into one string event,
+// so a new redelegation begins whenever the denom attribute shows up
+func parseUpdateRValidatorEvent(event sdk.StringEvent) ([]*dao_election.Redelegation, error) {
+	redelegations := make([]*dao_election.Redelegation, 0)
+	var current *dao_election.Redelegation
+	for _, attr := range event.Attributes {
+		if attr.Key == stafiHubXRValidatorTypes.AttributeKeyDenom {
+			current = &dao_election.Redelegation{RTokenDenom: attr.Value}
+			redelegations = append(redelegations, current)
+			continue
+		}
+		if current == nil {
+			return nil, fmt.Errorf("attribute %s before denom", attr.Key)
+		}
+
+		switch attr.Key {
+		case stafiHubXRValidatorTypes.AttributeKeyPoolAddress:
+			current.PoolAddress = attr.Value
+		case stafiHubXRValidatorTypes.AttributeKeyOldAddress:
+			current.OldValidator = attr.Value
+		case stafiHubXRValidatorTypes.AttributeKeyNewAddress:
+			current.NewValidator = attr.Value
+		case stafiHubXRValidatorTypes.AttributeKeyChainEra:
+			chainEra, err := strconv.ParseUint(attr.Value, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			current.ChainEra = uint32(chainEra)
+		case stafiHubXRValidatorTypes.AttributeKeyCycleVersion:
+			cycleVersion, err := strconv.ParseUint(attr.Value, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			current.CycleVersion = cycleVersion
+		case stafiHubXRValidatorTypes.AttributeKeyCycleNumber:
+			cycleNumber, err := strconv.ParseUint(attr.Value, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			current.CycleNumber = cycleNumber
+		}
+	}
+	return redelegations, nil
+}
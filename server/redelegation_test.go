@@ -0,0 +1,244 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	stafiHubXRValidatorTypes "github.com/stafihub/stafihub/x/rvalidator/types"
+	"github.com/stafihub/staking-election/config"
+	"github.com/stafihub/staking-election/dao/election"
+	"github.com/stafihub/staking-election/retention"
+)
+
+// fakeTxSearcher serves txs in height order, filtered by the tx.height>= event like stafihub
+type fakeTxSearcher struct {
+	txs         []*sdk.TxResponse
+	fromHeights []int64 // tx.height>= of each first page query
+}
+
+func (f *fakeTxSearcher) GetTxs(events []string, page, limit int, orderBy string) (*sdk.SearchTxsResult, error) {
+	fromHeight := int64(0)
+	for _, event := range events {
+		if strings.HasPrefix(event, "tx.height>=") {
+			height, err := strconv.ParseInt(strings.TrimPrefix(event, "tx.height>="), 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			fromHeight = height
+		}
+	}
+	if page == 1 {
+		f.fromHeights = append(f.fromHeights, fromHeight)
+	}
+
+	matched := make([]*sdk.TxResponse, 0)
+	for _, tx := range f.txs {
+		if tx.Height >= fromHeight {
+			matched = append(matched, tx)
+		}
+	}
+	start := (page - 1) * limit
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := start + limit
+	if end > len(matched) {
+		end = len(matched)
+	}
+	return &sdk.SearchTxsResult{TotalCount: uint64(len(matched)), Txs: matched[start:end]}, nil
+}
+
+// updateRValidatorTx builds a tx of one merged update_rvalidator event, each update is denom/pool/old/new/cycle
+func updateRValidatorTx(height int64, code uint32, updates ...string) *sdk.TxResponse {
+	event := sdk.StringEvent{Type: stafiHubXRValidatorTypes.EventTypeUpdateRValidator}
+	for _, update := range updates {
+		parts := strings.Split(update, "/")
+		event.Attributes = append(event.Attributes,
+			sdk.Attribute{Key: stafiHubXRValidatorTypes.AttributeKeyDenom, Value: parts[0]},
+			sdk.Attribute{Key: stafiHubXRValidatorTypes.AttributeKeyPoolAddress, Value: parts[1]},
+			sdk.Attribute{Key: stafiHubXRValidatorTypes.AttributeKeyOldAddress, Value: parts[2]},
+			sdk.Attribute{Key: stafiHubXRValidatorTypes.AttributeKeyNewAddress, Value: parts[3]},
+			sdk.Attribute{Key: stafiHubXRValidatorTypes.AttributeKeyCycleVersion, Value: "1"},
+			sdk.Attribute{Key: stafiHubXRValidatorTypes.AttributeKeyCycleNumber, Value: parts[4]},
+			sdk.Attribute{Key: stafiHubXRValidatorTypes.AttributeKeyChainEra, Value: "7"},
+		)
+	}
+	return &sdk.TxResponse{
+		Height: height,
+		TxHash: fmt.Sprintf("tx%d", height),
+		Code:   code,
+		Logs:   sdk.ABCIMessageLogs{{Events: sdk.StringEvents{{Type: "message"}, event}}},
+	}
+}
+
+func newIndexServer(t *testing.T) *Server {
+	t.Helper()
+	return &Server{
+		cfg: &config.Config{RTokenInfo: []config.RTokenInfo{{Denom: "uratom"}}},
+		db:  newMemoryDb(t),
+	}
+}
+
+// indexed returns redelegations of denom as height:pool/old/new/cycle, latest first
+func indexed(t *testing.T, svr *Server, denom string) []string {
+	t.Helper()
+	list, _, err := dao_election.GetRedelegationList(svr.db, denom, "", 1, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := make([]string, 0, len(list))
+	for _, r := range list {
+		if r.TxHash != fmt.Sprintf("tx%d", r.Height) || r.CycleVersion != 1 || r.ChainEra != 7 {
+			t.Errorf("redelegation %+v", r)
+		}
+		s = append(s, fmt.Sprintf("%d:%s/%s/%s/%d", r.Height, r.PoolAddress, r.OldValidator, r.NewValidator, r.CycleNumber))
+	}
+	return s
+}
+
+func TestIndexRedelegation(t *testing.T) {
+	svr := newIndexServer(t)
+	searcher := &fakeTxSearcher{txs: []*sdk.TxResponse{
+		updateRValidatorTx(10, 0, "uratom/poolA/val1/val2/3"),
+		updateRValidatorTx(11, 5, "uratom/poolA/val2/val3/4"), // failed tx
+		// one msg updating two pools, the other denom is skipped
+		updateRValidatorTx(12, 0, "uratom/poolA/val2/val3/4", "uriris/poolC/val7/val8/4", "uratom/poolB/val4/val5/4"),
+	}}
+
+	if err := svr.indexRedelegation(searcher, "uratom"); err != nil {
+		t.Fatalf("indexRedelegation err: %s", err)
+	}
+	want := []string{"12:poolB/val4/val5/4", "12:poolA/val2/val3/4", "10:poolA/val1/val2/3"}
+	if got := indexed(t, svr, "uratom"); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("indexed %v, want %v", got, want)
+	}
+	if got := indexed(t, svr, "uriris"); len(got) != 0 {
+		t.Errorf("indexed uriris %v", got)
+	}
+
+	// the next pass resumes after the indexed height
+	searcher.txs = append(searcher.txs, updateRValidatorTx(20, 0, "uratom/poolB/val5/val6/5"))
+	if err := svr.indexRedelegation(searcher, "uratom"); err != nil {
+		t.Fatalf("indexRedelegation err: %s", err)
+	}
+	want = append([]string{"20:poolB/val5/val6/5"}, want...)
+	if got := indexed(t, svr, "uratom"); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("indexed %v, want %v", got, want)
+	}
+	if fmt.Sprint(searcher.fromHeights) != "[0 13]" {
+		t.Errorf("queried from heights %v, want [0 13]", searcher.fromHeights)
+	}
+}
+
+func TestIndexRedelegationAfterPrune(t *testing.T) {
+	svr := newIndexServer(t)
+	searcher := &fakeTxSearcher{txs: []*sdk.TxResponse{
+		updateRValidatorTx(10, 0, "uratom/poolA/val1/val2/3"),
+		updateRValidatorTx(12, 0, "uratom/poolA/val2/val3/4"),
+	}}
+	if err := svr.indexRedelegation(searcher, "uratom"); err != nil {
+		t.Fatalf("indexRedelegation err: %s", err)
+	}
+
+	// every redelegation expires
+	rules := []retention.Rule{{Table: dao_election.RetentionTableRedelegation, Ttl: time.Hour}}
+	if _, err := retention.Prune(svr.db, rules, time.Now().Add(2*time.Hour), false); err != nil {
+		t.Fatalf("Prune err: %s", err)
+	}
+	if got := indexed(t, svr, "uratom"); len(got) != 0 {
+		t.Fatalf("indexed %v after prune", got)
+	}
+
+	// pruned redelegations are neither rescanned nor indexed again
+	searcher.txs = append(searcher.txs, updateRValidatorTx(20, 0, "uratom/poolB/val5/val6/5"))
+	if err := svr.indexRedelegation(searcher, "uratom"); err != nil {
+		t.Fatalf("indexRedelegation err: %s", err)
+	}
+	if got := indexed(t, svr, "uratom"); strings.Join(got, " ") != "20:poolB/val5/val6/5" {
+		t.Errorf("indexed %v after prune", got)
+	}
+	if fmt.Sprint(searcher.fromHeights) != "[0 13]" {
+		t.Errorf("queried from heights %v, want [0 13]", searcher.fromHeights)
+	}
+}
+
+func TestIndexRedelegationPages(t *testing.T) {
+	svr := newIndexServer(t)
+	searcher := &fakeTxSearcher{}
+	for height := int64(1); height <= redelegationTxsPageLimit*2+3; height++ {
+		searcher.txs = append(searcher.txs, updateRValidatorTx(height, 0, fmt.Sprintf("uratom/poolA/val%d/val%d/%d", height, height+1, height)))
+	}
+
+	if err := svr.indexRedelegation(searcher, "uratom"); err != nil {
+		t.Fatalf("indexRedelegation err: %s", err)
+	}
+	if got := indexed(t, svr, "uratom"); len(got) != redelegationTxsPageLimit*2+3 {
+		t.Errorf("%d indexed, want %d", len(got), redelegationTxsPageLimit*2+3)
+	}
+}
+
+func TestParseUpdateRValidatorEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		attrs   [][2]string
+		want    []string // denom/pool/old/new/cycle
+		wantErr bool
+	}{
+		{
+			name: "merged events",
+			attrs: [][2]string{
+				{stafiHubXRValidatorTypes.AttributeKeyDenom, "uratom"},
+				{stafiHubXRValidatorTypes.AttributeKeyPoolAddress, "poolA"},
+				{stafiHubXRValidatorTypes.AttributeKeyOldAddress, "val1"},
+				{stafiHubXRValidatorTypes.AttributeKeyNewAddress, "val2"},
+				{stafiHubXRValidatorTypes.AttributeKeyCycleNumber, "3"},
+				{stafiHubXRValidatorTypes.AttributeKeyDenom, "uatom"},
+				{stafiHubXRValidatorTypes.AttributeKeyPoolAddress, "poolB"},
+			},
+			want: []string{"uratom/poolA/val1/val2/3", "uatom/poolB///0"},
+		},
+		{
+			name:    "attribute before denom",
+			attrs:   [][2]string{{stafiHubXRValidatorTypes.AttributeKeyPoolAddress, "poolA"}},
+			wantErr: true,
+		},
+		{
+			name: "invalid cycle number",
+			attrs: [][2]string{
+				{stafiHubXRValidatorTypes.AttributeKeyDenom, "uratom"},
+				{stafiHubXRValidatorTypes.AttributeKeyCycleNumber, "x"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := sdk.StringEvent{Type: stafiHubXRValidatorTypes.EventTypeUpdateRValidator}
+			for _, attr := range tt.attrs {
+				event.Attributes = append(event.Attributes, sdk.Attribute{Key: attr[0], Value: attr[1]})
+			}
+			redelegations, err := parseUpdateRValidatorEvent(event)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("parseUpdateRValidatorEvent want err")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseUpdateRValidatorEvent err: %s", err)
+			}
+			got := make([]string, 0, len(redelegations))
+			for _, r := range redelegations {
+				got = append(got, fmt.Sprintf("%s/%s/%s/%s/%d", r.RTokenDenom, r.PoolAddress, r.OldValidator, r.NewValidator, r.CycleNumber))
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("redelegations %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,11 @@
 package server
 
 import (
+	"context"
+	"crypto/tls"
+	"net"
 	"net/http"
+	"sync"
 	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -10,51 +14,174 @@ import (
 	"github.com/stafihub/rtoken-relay-core/common/core"
 	stafihubClient "github.com/stafihub/stafi-hub-relay-sdk/client"
 	"github.com/stafihub/staking-election/api"
+	"github.com/stafihub/staking-election/api/election_handlers"
 	"github.com/stafihub/staking-election/config"
 	"github.com/stafihub/staking-election/dao/election"
 	"github.com/stafihub/staking-election/db"
+	"github.com/stafihub/staking-election/event"
+	"github.com/stafihub/staking-election/logo"
+	"github.com/stafihub/staking-election/price"
+	"github.com/stafihub/staking-election/retention"
 	"github.com/stafihub/staking-election/utils"
+	"google.golang.org/grpc"
 	"gorm.io/gorm"
 )
 
+const (
+	defaultPriceCacheSeconds    = 60
+	defaultPriceMaxStaleSeconds = 3600
+	defaultLogoRefreshSeconds   = 24 * 60 * 60
+	defaultEventBufferSize      = 16
+	defaultEventMaxSubscribers  = 1000
+	defaultShutdownTimeout      = 15
+	updateIntervalSeconds       = 60
+	defaultTlsReloadSeconds     = 60
+	maxLogoResolvePerRound      = 50 // logo lookups of each denom per round
+	defaultRetentionSeconds     = 60 * 60
+)
+
 type Server struct {
-	listenAddr      string
-	httpServer      *http.Server
-	stop            chan struct{}
-	cfg             *config.Config
-	db              *db.WrapDb
-	cosmosClientMap map[string]*cosmosClient.Client
-	stafihubClient  *stafihubClient.Client
+	listenAddr            string
+	httpServer            *http.Server
+	grpcServer            *grpc.Server  // nil if grpc api is not enabled
+	certReloader          *certReloader // nil if tls is not enabled
+	updateMutex           sync.Mutex    // held during an update pass
+	stop                  chan struct{}
+	refresh               chan struct{} // triggers an immediate update, buffered by one
+	cfg                   *config.Config
+	db                    *db.WrapDb
+	annualRateRepo        dao_election.AnnualRateRepository
+	selectedValidatorRepo dao_election.SelectedValidatorRepository
+	cosmosClientMap       map[string]*cosmosClient.Client
+	stafihubClient        *stafihubClient.Client
+	priceAggregator       *price.Aggregator
+	logoResolver          *logo.Resolver
+	eventBroker           *event.Broker
+	responseCache         *api.ResponseCache
+	retentionRules        []retention.Rule
 }
 
-func NewServer(cfg *config.Config, stafihubClient *stafihubClient.Client, db *db.WrapDb) (*Server, error) {
+func NewServer(cfg *config.Config, stafihubClient *stafihubClient.Client, db *db.WrapDb, repos dao_election.Repositories) (*Server, error) {
 	s := &Server{
-		listenAddr:     cfg.ListenAddr,
-		cfg:            cfg,
-		stop:           make(chan struct{}),
-		stafihubClient: stafihubClient,
-		db:             db,
+		listenAddr:            cfg.Api.ListenAddr,
+		cfg:                   cfg,
+		stop:                  make(chan struct{}),
+		refresh:               make(chan struct{}, 1),
+		stafihubClient:        stafihubClient,
+		db:                    db,
+		annualRateRepo:        repos.AnnualRate,
+		selectedValidatorRepo: repos.SelectedValidator,
+		// filled in Start, shared with api handlers
+		cosmosClientMap: make(map[string]*cosmosClient.Client),
+	}
+
+	logoUrlTemplates := make(map[string]string)
+	for _, rtokenInfo := range cfg.RTokenInfo {
+		logoUrlTemplates[rtokenInfo.Denom] = rtokenInfo.LogoUrlTemplate
+	}
+	s.logoResolver = logo.NewResolver(logo.NewHttpFetcher(10*time.Second), logoUrlTemplates, cfg.Api.Logo.KeybaseUrl, cfg.Api.Logo.DefaultUrl)
+
+	coinGeckoIds := make(map[string]string)
+	coinMarketSymbols := make(map[string]string)
+	denoms := make([]string, 0)
+	for _, rtokenInfo := range cfg.RTokenInfo {
+		coinGeckoIds[rtokenInfo.Denom] = rtokenInfo.CoinGeckoId
+		coinMarketSymbols[rtokenInfo.Denom] = rtokenInfo.CoinMarketSymbol
+		denoms = append(denoms, rtokenInfo.Denom)
+	}
+	providers := make([]price.PriceProvider, 0)
+	if len(cfg.Api.Price.CoinGeckoUrl) != 0 {
+		providers = append(providers, price.NewCoinGeckoProvider(cfg.Api.Price.CoinGeckoUrl, coinGeckoIds))
+	}
+	if len(cfg.Api.Price.CoinMarketUrl) != 0 {
+		providers = append(providers, price.NewCoinMarketProvider(cfg.Api.Price.CoinMarketUrl, coinMarketSymbols))
+	}
+	cacheSeconds := cfg.Api.Price.CacheSeconds
+	if cacheSeconds <= 0 {
+		cacheSeconds = defaultPriceCacheSeconds
+	}
+	maxStaleSeconds := cfg.Api.Price.MaxStaleSeconds
+	if maxStaleSeconds <= 0 {
+		maxStaleSeconds = defaultPriceMaxStaleSeconds
+	}
+	s.priceAggregator = price.NewAggregator(providers, denoms,
+		time.Duration(cacheSeconds)*time.Second, time.Duration(maxStaleSeconds)*time.Second)
+
+	eventBufferSize := cfg.Api.Event.BufferSize
+	if eventBufferSize <= 0 {
+		eventBufferSize = defaultEventBufferSize
+	}
+	eventMaxSubscribers := cfg.Api.Event.MaxSubscribers
+	if eventMaxSubscribers <= 0 {
+		eventMaxSubscribers = defaultEventMaxSubscribers
+	}
+	s.eventBroker = event.NewBroker(eventBufferSize, eventMaxSubscribers)
+	s.responseCache = api.NewResponseCache(updateIntervalSeconds * time.Second)
+
+	if cfg.Api.Retention.Enable {
+		rules, err := retention.RulesFromConfig(cfg.Api.Retention)
+		if err != nil {
+			return nil, err
+		}
+		s.retentionRules = rules
 	}
 
-	handler := s.InitHandler(s.db)
+	handler, err := s.InitHandler(s.db)
+	if err != nil {
+		return nil, err
+	}
 
 	s.httpServer = &http.Server{
 		Addr:         s.listenAddr,
 		Handler:      handler,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
+		// event streams move the write deadline of their own connection
+		ConnContext: election_handlers.ConnContext,
+	}
+	// end event streams, or shutdown would wait for them until timeout
+	s.httpServer.RegisterOnShutdown(s.eventBroker.Close)
+
+	if len(cfg.Api.Tls.CertFile) != 0 || len(cfg.Api.Tls.KeyFile) != 0 {
+		s.certReloader, err = newCertReloader(cfg.Api.Tls.CertFile, cfg.Api.Tls.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		s.httpServer.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: s.certReloader.GetCertificate,
+		}
+		// the write timeout of a http/2 stream can't be moved before go 1.20, serve http/1.1 only
+		if cfg.Api.Event.Enable {
+			s.httpServer.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+		}
+	}
+
+	if len(cfg.Api.GrpcListenAddr) != 0 {
+		s.grpcServer = api.InitGrpcServer(cfg, s.db, repos, s.priceAggregator, s.cosmosClientMap)
 	}
 
 	return s, nil
 }
 
-func (svr *Server) InitHandler(db *db.WrapDb) http.Handler {
-	return api.InitRouters(db)
+func (svr *Server) InitHandler(db *db.WrapDb) (http.Handler, error) {
+	repos := dao_election.Repositories{
+		AnnualRate:        svr.annualRateRepo,
+		SelectedValidator: svr.selectedValidatorRepo,
+	}
+	return api.InitRouters(svr.cfg, db, repos, svr.priceAggregator, svr.cosmosClientMap, svr, svr.eventBroker, svr.responseCache)
 }
 
 func (svr *Server) ApiServer() {
-	logrus.Infof("Gin server start on %s", svr.listenAddr)
-	err := svr.httpServer.ListenAndServe()
+	var err error
+	if svr.certReloader != nil {
+		logrus.Infof("Gin server start on %s with tls", svr.listenAddr)
+		// certificate is got from TLSConfig
+		err = svr.httpServer.ListenAndServeTLS("", "")
+	} else {
+		logrus.Infof("Gin server start on %s", svr.listenAddr)
+		err = svr.httpServer.ListenAndServe()
+	}
 	if err != nil && err != http.ErrServerClosed {
 		logrus.Errorf("Gin server start err: %s", err.Error())
 		utils.ShutdownRequestChannel <- struct{}{} //shutdown server
@@ -63,9 +190,25 @@ func (svr *Server) ApiServer() {
 	logrus.Infof("Gin server done on %s", svr.listenAddr)
 }
 
+func (svr *Server) GrpcServer() {
+	logrus.Infof("Grpc server start on %s", svr.cfg.Api.GrpcListenAddr)
+	listener, err := net.Listen("tcp", svr.cfg.Api.GrpcListenAddr)
+	if err != nil {
+		logrus.Errorf("Grpc server listen err: %s", err.Error())
+		utils.ShutdownRequestChannel <- struct{}{} //shutdown server
+		return
+	}
+	err = svr.grpcServer.Serve(listener)
+	if err != nil {
+		logrus.Errorf("Grpc server start err: %s", err.Error())
+		utils.ShutdownRequestChannel <- struct{}{} //shutdown server
+		return
+	}
+	logrus.Infof("Grpc server done on %s", svr.cfg.Api.GrpcListenAddr)
+}
+
 func (svr *Server) Start() error {
-	svr.cosmosClientMap = make(map[string]*cosmosClient.Client)
-	// init client and selected validators
+	// init client
 	for _, rtokenInfo := range svr.cfg.RTokenInfo {
 		addressPrefixRes, err := svr.stafihubClient.QueryAddressPrefix(rtokenInfo.Denom)
 		if err != nil {
@@ -76,98 +219,99 @@ func (svr *Server) Start() error {
 			return err
 		}
 		svr.cosmosClientMap[rtokenInfo.Denom] = client
-
-		bondedPoolsRes, err := svr.stafihubClient.QueryPools(rtokenInfo.Denom)
-		if err != nil {
-			return err
-		}
-
-		for _, poolAddrStr := range bondedPoolsRes.Addrs {
-			done := core.UseSdkConfigContext(client.GetAccountPrefix())
-			poolAddr, err := sdk.AccAddressFromBech32(poolAddrStr)
-			if err != nil {
-				done()
-				return err
-			}
-			done()
-
-			delegationsRes, err := client.QueryDelegations(poolAddr, 0)
-			if err != nil {
-				return err
-			}
-
-			for _, delegation := range delegationsRes.DelegationResponses {
-				valAddress := delegation.Delegation.ValidatorAddress
-				_, err := dao_election.GetSelectedValidator(svr.db, rtokenInfo.Denom, poolAddrStr, valAddress)
-				if err != nil {
-					if err != gorm.ErrRecordNotFound {
-						return err
-					} else {
-						valRes, err := client.QueryValidator(valAddress, 0)
-						if err != nil {
-							return err
-						}
-
-						selectedValidator := &dao_election.SelectedValidator{
-							RTokenDenom:      rtokenInfo.Denom,
-							PoolAddress:      poolAddrStr,
-							ValidatorAddress: valAddress,
-							Moniker:          valRes.Validator.GetMoniker(),
-						}
-
-						err = dao_election.UpOrInSelectedValidator(svr.db, selectedValidator)
-						if err != nil {
-							return err
-						}
-					}
-				}
-
-			}
-		}
 	}
 
-	// init annual rate
-	for denom, client := range svr.cosmosClientMap {
-		height, err := client.GetCurrentBlockHeight()
-		if err != nil {
-			return err
-		}
-		rate, err := utils.GetAverageAnnualRate(client, height, nil)
-		if err != nil {
-			return err
-		}
-		annualRate, err := dao_election.GetAnnualRate(svr.db, denom)
-		if err != nil && err != gorm.ErrRecordNotFound {
-			return err
-		}
-
-		annualRate.RTokenDenom = denom
-		annualRate.AnnualRate = rate.String()
+	// init selected validators
+	err := svr.updateSelectedValidator()
+	if err != nil {
+		return err
+	}
 
-		err = dao_election.UpOrInAnnualRate(svr.db, annualRate)
-		if err != nil {
-			return err
-		}
+	// init annual rate and validators
+	err = svr.updateAnnualRate()
+	if err != nil {
+		return err
+	}
+	// init prices and rtoken stat
+	svr.priceAggregator.Refresh()
+	err = svr.updateRTokenStat()
+	if err != nil {
+		return err
 	}
 
 	utils.SafeGoWithRestart(svr.ApiServer)
+	if svr.grpcServer != nil {
+		utils.SafeGoWithRestart(svr.GrpcServer)
+	}
 	utils.SafeGoWithRestart(svr.AverageAnnualRateHandler)
+	if svr.cfg.Api.Retention.Enable {
+		utils.SafeGoWithRestart(svr.RetentionHandler)
+	}
+	if svr.certReloader != nil {
+		reloadSeconds := svr.cfg.Api.Tls.ReloadCheckSeconds
+		if reloadSeconds <= 0 {
+			reloadSeconds = defaultTlsReloadSeconds
+		}
+		utils.SafeGo(func() {
+			svr.certReloader.watch(time.Duration(reloadSeconds)*time.Second, svr.stop)
+		})
+	}
 	return nil
 }
 
+// Stop drains in-flight requests and waits for the current update pass to finish,
+// connections left after the shutdown timeout are closed.
 func (svr *Server) Stop() {
+	timeoutSeconds := svr.cfg.Api.ShutdownTimeoutSeconds
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = defaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutSeconds)*time.Second)
+	defer cancel()
+
+	// no update pass starts after stop is closed
+	close(svr.stop)
+
 	if svr.httpServer != nil {
-		err := svr.httpServer.Close()
+		err := svr.httpServer.Shutdown(ctx)
 		if err != nil {
 			logrus.Errorf("Problem shutdown Gin server :%s", err.Error())
+			err = svr.httpServer.Close()
+			if err != nil {
+				logrus.Errorf("Problem close Gin server :%s", err.Error())
+			}
 		}
 	}
-	close(svr.stop)
+	if svr.grpcServer != nil {
+		stopped := make(chan struct{})
+		go func() {
+			svr.grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			logrus.Errorf("Problem shutdown Grpc server :%s", ctx.Err())
+			svr.grpcServer.Stop()
+		}
+	}
+
+	updateDone := make(chan struct{})
+	go func() {
+		svr.updateMutex.Lock()
+		svr.updateMutex.Unlock()
+		close(updateDone)
+	}()
+	select {
+	case <-updateDone:
+	case <-ctx.Done():
+		logrus.Errorf("Problem wait for current update pass :%s", ctx.Err())
+	}
 }
 
 func (s *Server) AverageAnnualRateHandler() {
 	logrus.Infof("AverageAnnualRateHandler start")
-	ticker := time.NewTicker(time.Duration(60) * time.Second)
+	ticker := time.NewTicker(time.Duration(updateIntervalSeconds) * time.Second)
 	defer ticker.Stop()
 
 	for {
@@ -175,45 +319,126 @@ func (s *Server) AverageAnnualRateHandler() {
 		case <-s.stop:
 			return
 		case <-ticker.C:
-			logrus.Debugf("AverageAnnualRateHandler start -----------")
-			err := s.updateAnnualRate()
-			if err != nil {
-				logrus.Warnf("updateAnnualRate err: %s", err)
-				continue
-			}
-			logrus.Debugf("AverageAnnualRateHandler end -----------")
+			s.updateAll()
+		case <-s.refresh:
+			logrus.Info("refresh triggered")
+			s.updateAll()
+		}
+	}
+}
+
+// RetentionHandler prunes history tables by retention rules on schedule
+func (s *Server) RetentionHandler() {
+	logrus.Infof("RetentionHandler start")
+	intervalSeconds := s.cfg.Api.Retention.IntervalSeconds
+	if intervalSeconds <= 0 {
+		intervalSeconds = defaultRetentionSeconds
+	}
+	ticker := time.NewTicker(time.Duration(intervalSeconds) * time.Second)
+	defer ticker.Stop()
 
-			logrus.Debugf("updateSelectedValidator start -----------")
-			err = s.updateSelectedValidator()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			results, err := retention.Prune(s.db, s.retentionRules, time.Now(), false)
+			for _, result := range results {
+				if result.Rows > 0 {
+					logrus.Infof("retention: %s", result)
+				}
+			}
 			if err != nil {
-				logrus.Warnf("updateSelectedValidator err: %s", err)
-				continue
+				logrus.Warnf("retention prune err: %s", err)
 			}
-			logrus.Debugf("updateSelectedValidator end -----------")
 		}
 	}
 }
 
+// TriggerUpdate asks AverageAnnualRateHandler to update at once, it returns false
+// if an update is already pending
+func (s *Server) TriggerUpdate() bool {
+	select {
+	case s.refresh <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Server) updateAll() {
+	s.updateMutex.Lock()
+	defer s.updateMutex.Unlock()
+	// stop may be closed while waiting in select with a ready ticker
+	select {
+	case <-s.stop:
+		return
+	default:
+	}
+
+	s.priceAggregator.Refresh()
+
+	// passes are independent, an error of one is logged and doesn't stop the others
+	passes := []struct {
+		name   string
+		update func() error
+	}{
+		{"updateAnnualRate", s.updateAnnualRate},
+		{"updateSelectedValidator", s.updateSelectedValidator},
+		{"updateRTokenStat", s.updateRTokenStat},
+		{"updateValidatorLogo", s.updateValidatorLogo},
+		{"updateRedelegation", s.updateRedelegation},
+	}
+	for _, pass := range passes {
+		logrus.Debugf("%s start -----------", pass.name)
+		if err := pass.update(); err != nil {
+			logrus.Warnf("%s err: %s", pass.name, err)
+			continue
+		}
+		logrus.Debugf("%s end -----------", pass.name)
+	}
+}
+
 func (svr *Server) updateAnnualRate() error {
-	for denom, client := range svr.cosmosClientMap {
+	for _, rtokenInfo := range svr.cfg.RTokenInfo {
+		denom := rtokenInfo.Denom
+		client := svr.cosmosClientMap[denom]
 		height, err := client.GetCurrentBlockHeight()
 		if err != nil {
 			return err
 		}
-		rate, err := utils.GetAverageAnnualRate(client, height, nil)
+		valMap, err := utils.GetValidatorAnnualRate(client, height, rtokenInfo.Sampling())
+		if err != nil {
+			return err
+		}
+		rate, err := utils.GetAverageAnnualRate(client, height, valMap)
 		if err != nil {
 			return err
 		}
 
-		annualRate, err := dao_election.GetAnnualRate(svr.db, denom)
+		annualRate, err := svr.annualRateRepo.GetAnnualRate(denom)
 		if err != nil && err != gorm.ErrRecordNotFound {
 			return err
 		}
 
+		changed := annualRate.AnnualRate != rate.String()
 		annualRate.RTokenDenom = denom
 		annualRate.AnnualRate = rate.String()
 
-		err = dao_election.UpOrInAnnualRate(svr.db, annualRate)
+		err = svr.annualRateRepo.UpOrInAnnualRate(annualRate)
+		if err != nil {
+			return err
+		}
+		if changed {
+			svr.responseCache.Invalidate(api.CacheGroupAnnualRate)
+			svr.eventBroker.Publish(event.Event{
+				Type:  event.TypeAnnualRate,
+				Denom: denom,
+				Data:  event.AnnualRateData{AnnualRate: annualRate.AnnualRate},
+			})
+		}
+
+		err = svr.updateValidators(denom, height, valMap)
 		if err != nil {
 			return err
 		}
@@ -223,16 +448,219 @@ func (svr *Server) updateAnnualRate() error {
 	return nil
 }
 
+// replace the validator snapshot of denom with valMap
+func (svr *Server) updateValidators(denom string, height int64, valMap map[string]*utils.Validator) error {
+	tx := svr.db.NewTransaction()
+	valAddresses := make([]string, 0, len(valMap))
+	for valAddress, val := range valMap {
+		validator, err := dao_election.GetValidator(tx, denom, valAddress)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			tx.RollbackTransaction()
+			return err
+		}
+
+		validator.RTokenDenom = denom
+		validator.ValidatorAddress = valAddress
+		validator.Moniker = val.Moniker
+		validator.Identity = val.Identity
+		validator.AnnualRate = val.AnnualRate.String()
+		validator.Commission = val.Commission.String()
+		validator.TokenAmount = val.TokenAmount.String()
+		validator.Height = height
+
+		err = dao_election.UpOrInValidator(tx, validator)
+		if err != nil {
+			tx.RollbackTransaction()
+			return err
+		}
+		valAddresses = append(valAddresses, valAddress)
+	}
+
+	err := dao_election.DeleteValidatorsNotIn(tx, denom, valAddresses)
+	if err != nil {
+		tx.RollbackTransaction()
+		return err
+	}
+
+	return tx.CommitTransaction()
+}
+
+// reconcile selected validators of each denom with delegations of its pools on chain
 func (svr *Server) updateSelectedValidator() error {
 	for _, rtokenInfo := range svr.cfg.RTokenInfo {
+		onChainMap, err := svr.queryDelegatedValidators(rtokenInfo.Denom)
+		if err != nil {
+			return err
+		}
+		err = svr.reconcileSelectedValidator(rtokenInfo.Denom, onChainMap)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
+// queryDelegatedValidators returns poolAddress + validatorAddress -> validator delegated by bonded pools of denom
+func (svr *Server) queryDelegatedValidators(denom string) (map[string]*dao_election.SelectedValidator, error) {
+	client := svr.cosmosClientMap[denom]
+
+	bondedPoolsRes, err := svr.stafihubClient.QueryPools(denom)
+	if err != nil {
+		return nil, err
+	}
+
+	// poolAddress + validatorAddress -> selected validator on chain
+	onChainMap := make(map[string]*dao_election.SelectedValidator)
+	monikerMap := make(map[string]string)
+	for _, poolAddrStr := range bondedPoolsRes.Addrs {
+		done := core.UseSdkConfigContext(client.GetAccountPrefix())
+		poolAddr, err := sdk.AccAddressFromBech32(poolAddrStr)
+		if err != nil {
+			done()
+			return nil, err
+		}
+		done()
+
+		delegationsRes, err := client.QueryDelegations(poolAddr, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, delegation := range delegationsRes.DelegationResponses {
+			valAddress := delegation.Delegation.ValidatorAddress
+			moniker, exist := monikerMap[valAddress]
+			if !exist {
+				valRes, err := client.QueryValidator(valAddress, 0)
+				if err != nil {
+					return nil, err
+				}
+				moniker = valRes.Validator.GetMoniker()
+				monikerMap[valAddress] = moniker
+			}
+
+			onChainMap[poolAddrStr+valAddress] = &dao_election.SelectedValidator{
+				RTokenDenom:      denom,
+				PoolAddress:      poolAddrStr,
+				ValidatorAddress: valAddress,
+				Moniker:          moniker,
+			}
+		}
+	}
+	return onChainMap, nil
+}
+
+// reconcileSelectedValidator syncs selected validators of denom with onChainMap, removed ones are kept in history
+func (svr *Server) reconcileSelectedValidator(denom string, onChainMap map[string]*dao_election.SelectedValidator) error {
+	now := time.Now().Unix()
+	changes := event.SelectedValidatorsData{
+		Added:   make([]event.SelectedValidator, 0),
+		Removed: make([]event.SelectedValidator, 0),
+	}
+	err := svr.selectedValidatorRepo.Transaction(func(repo dao_election.SelectedValidatorRepository) error {
+		localList, err := repo.GetSelectedValidatorListByDenom(denom)
+		if err != nil {
+			return err
+		}
+
+		localMap := make(map[string]*dao_election.SelectedValidator)
+		for _, local := range localList {
+			key := local.PoolAddress + local.ValidatorAddress
+			localMap[key] = local
+
+			onChain, exist := onChainMap[key]
+			switch {
+			// removed: pool redelegated away from it or pool is not bonded any more
+			case !exist:
+				err = repo.DeleteSelectedValidator(denom, local.PoolAddress, local.ValidatorAddress)
+				if err != nil {
+					return err
+				}
+				err = repo.AddSelectedValidatorHistory(&dao_election.SelectedValidatorHistory{
+					RTokenDenom:      denom,
+					PoolAddress:      local.PoolAddress,
+					ValidatorAddress: local.ValidatorAddress,
+					Moniker:          local.Moniker,
+					SelectedAt:       int64(local.CreatedAt),
+					RemovedAt:        now,
+				})
+				if err != nil {
+					return err
+				}
+				logrus.WithFields(logrus.Fields{
+					"denom":    denom,
+					"pool":     local.PoolAddress,
+					"valAddr":  local.ValidatorAddress,
+					"moniker":  local.Moniker,
+					"selected": local.CreatedAt,
+				}).Info("selected validator removed")
+				changes.Removed = append(changes.Removed, event.SelectedValidator{
+					PoolAddress:      local.PoolAddress,
+					ValidatorAddress: local.ValidatorAddress,
+					Moniker:          local.Moniker,
+				})
+
+			// updated: moniker changed
+			case onChain.Moniker != local.Moniker:
+				local.Moniker = onChain.Moniker
+				err = repo.UpOrInSelectedValidator(local)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		// inserted: newly delegated validators
+		for key, onChain := range onChainMap {
+			if _, exist := localMap[key]; exist {
+				continue
+			}
+			err = repo.UpOrInSelectedValidator(onChain)
+			if err != nil {
+				return err
+			}
+			logrus.WithFields(logrus.Fields{
+				"denom":   denom,
+				"pool":    onChain.PoolAddress,
+				"valAddr": onChain.ValidatorAddress,
+				"moniker": onChain.Moniker,
+			}).Info("selected validator added")
+			changes.Added = append(changes.Added, event.SelectedValidator{
+				PoolAddress:      onChain.PoolAddress,
+				ValidatorAddress: onChain.ValidatorAddress,
+				Moniker:          onChain.Moniker,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	svr.responseCache.Invalidate(api.CacheGroupSelectedValidators)
+	if len(changes.Added) != 0 || len(changes.Removed) != 0 {
+		svr.eventBroker.Publish(event.Event{
+			Type:  event.TypeSelectedValidators,
+			Denom: denom,
+			Data:  changes,
+		})
+	}
+	return nil
+}
+
+// sum the native token bonded by all pools of each denom
+func (svr *Server) updateRTokenStat() error {
+	for _, rtokenInfo := range svr.cfg.RTokenInfo {
 		client := svr.cosmosClientMap[rtokenInfo.Denom]
 
+		height, err := client.GetCurrentBlockHeight()
+		if err != nil {
+			return err
+		}
 		bondedPoolsRes, err := svr.stafihubClient.QueryPools(rtokenInfo.Denom)
 		if err != nil {
 			return err
 		}
 
+		totalBonded := sdk.ZeroInt()
 		for _, poolAddrStr := range bondedPoolsRes.Addrs {
 			done := core.UseSdkConfigContext(client.GetAccountPrefix())
 			poolAddr, err := sdk.AccAddressFromBech32(poolAddrStr)
@@ -242,38 +670,115 @@ func (svr *Server) updateSelectedValidator() error {
 			}
 			done()
 
-			delegationsRes, err := client.QueryDelegations(poolAddr, 0)
+			delegationsRes, err := client.QueryDelegations(poolAddr, height)
 			if err != nil {
 				return err
 			}
-
 			for _, delegation := range delegationsRes.DelegationResponses {
-				valAddress := delegation.Delegation.ValidatorAddress
-				_, err := dao_election.GetSelectedValidator(svr.db, rtokenInfo.Denom, poolAddrStr, valAddress)
+				totalBonded = totalBonded.Add(delegation.Balance.Amount)
+			}
+		}
+
+		rTokenStat, err := dao_election.GetRTokenStat(svr.db, rtokenInfo.Denom)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+		rTokenStat.RTokenDenom = rtokenInfo.Denom
+		rTokenStat.TotalBonded = totalBonded.String()
+		rTokenStat.Height = height
+
+		err = dao_election.UpOrInRTokenStat(svr.db, rTokenStat)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve logos of selected validators and validators in snapshot, selected validators
+// go first as they are shown to users, logos are refreshed after refreshSeconds
+func (svr *Server) updateValidatorLogo() error {
+	refreshSeconds := svr.cfg.Api.Logo.RefreshSeconds
+	if refreshSeconds <= 0 {
+		refreshSeconds = defaultLogoRefreshSeconds
+	}
+	now := time.Now().Unix()
+
+	for _, rtokenInfo := range svr.cfg.RTokenInfo {
+		denom := rtokenInfo.Denom
+		client := svr.cosmosClientMap[denom]
+
+		logoList, err := dao_election.GetValidatorLogoListByDenom(svr.db, denom)
+		if err != nil {
+			return err
+		}
+		logoMap := make(map[string]*dao_election.ValidatorLogo)
+		for _, l := range logoList {
+			logoMap[l.ValidatorAddress] = l
+		}
+
+		selectedList, err := svr.selectedValidatorRepo.GetSelectedValidatorListByDenom(denom)
+		if err != nil {
+			return err
+		}
+		validatorList, err := dao_election.GetValidatorListByDenom(svr.db, denom)
+		if err != nil {
+			return err
+		}
+		identityMap := make(map[string]string)
+		for _, val := range validatorList {
+			identityMap[val.ValidatorAddress] = val.Identity
+		}
+
+		valAddresses := make([]string, 0)
+		for _, val := range selectedList {
+			valAddresses = append(valAddresses, val.ValidatorAddress)
+		}
+		for _, val := range validatorList {
+			valAddresses = append(valAddresses, val.ValidatorAddress)
+		}
+
+		// failed lookups count too, so a keybase outage can't make every round fetch every validator
+		attempts := 0
+		for _, valAddress := range valAddresses {
+			if attempts >= maxLogoResolvePerRound {
+				break
+			}
+			validatorLogo, exist := logoMap[valAddress]
+			if exist && now-int64(validatorLogo.UpdatedAt) < refreshSeconds {
+				continue
+			}
+
+			identity, exist := identityMap[valAddress]
+			if !exist {
+				valRes, err := client.QueryValidator(valAddress, 0)
 				if err != nil {
-					if err != gorm.ErrRecordNotFound {
-						return err
-					} else {
-						valRes, err := client.QueryValidator(valAddress, 0)
-						if err != nil {
-							return err
-						}
-
-						selectedValidator := &dao_election.SelectedValidator{
-							RTokenDenom:      rtokenInfo.Denom,
-							PoolAddress:      poolAddrStr,
-							ValidatorAddress: valAddress,
-							Moniker:          valRes.Validator.GetMoniker(),
-						}
-
-						err = dao_election.UpOrInSelectedValidator(svr.db, selectedValidator)
-						if err != nil {
-							return err
-						}
-					}
+					return err
 				}
+				identity = valRes.Validator.Description.Identity
+			}
 
+			attempts++
+			logoUrl, source, err := svr.logoResolver.Resolve(denom, valAddress, identity)
+			if err != nil {
+				logrus.Warnf("logoResolver.Resolve %s err: %s", valAddress, err)
+				continue
+			}
+
+			if validatorLogo == nil {
+				validatorLogo = &dao_election.ValidatorLogo{}
+			}
+			validatorLogo.RTokenDenom = denom
+			validatorLogo.ValidatorAddress = valAddress
+			validatorLogo.LogoUrl = logoUrl
+			validatorLogo.Source = source
+			err = dao_election.UpOrInValidatorLogo(svr.db, validatorLogo)
+			if err != nil {
+				return err
 			}
+			// logo url is part of selected validators response
+			svr.responseCache.Invalidate(api.CacheGroupSelectedValidators)
+			logoMap[valAddress] = validatorLogo
 		}
 	}
 	return nil
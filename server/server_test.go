@@ -0,0 +1,261 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stafihub/staking-election/api"
+	"github.com/stafihub/staking-election/config"
+	"github.com/stafihub/staking-election/dao/election"
+	"github.com/stafihub/staking-election/dao/migrate"
+	"github.com/stafihub/staking-election/db"
+	"github.com/stafihub/staking-election/event"
+	"github.com/stafihub/staking-election/logo"
+)
+
+func newMemoryDb(t *testing.T) *db.WrapDb {
+	t.Helper()
+	wrapDb, err := db.NewDB(&db.Config{Driver: db.DriverSqlite, DBName: db.SqliteMemory})
+	if err != nil {
+		t.Fatalf("NewDB err: %s", err)
+	}
+	t.Cleanup(func() {
+		if sqlDb, err := wrapDb.DB.DB(); err == nil {
+			sqlDb.Close()
+		}
+	})
+	if err := migrate.Up(wrapDb); err != nil {
+		t.Fatalf("migrate err: %s", err)
+	}
+	return wrapDb
+}
+
+func newReconcileServer(t *testing.T) (*Server, *event.Subscriber) {
+	t.Helper()
+	broker := event.NewBroker(16, 1)
+	sub, err := broker.Subscribe(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Server{
+		selectedValidatorRepo: dao_election.NewMemorySelectedValidatorRepository(),
+		responseCache:         api.NewResponseCache(time.Minute),
+		eventBroker:           broker,
+	}, sub
+}
+
+// onChain builds the delegations of pools as pool/validator/moniker
+func onChain(denom string, vals ...string) map[string]*dao_election.SelectedValidator {
+	m := make(map[string]*dao_election.SelectedValidator)
+	for _, v := range vals {
+		parts := strings.Split(v, "/")
+		m[parts[0]+parts[1]] = &dao_election.SelectedValidator{
+			RTokenDenom:      denom,
+			PoolAddress:      parts[0],
+			ValidatorAddress: parts[1],
+			Moniker:          parts[2],
+		}
+	}
+	return m
+}
+
+func selectedOf(t *testing.T, svr *Server, denom string) []string {
+	t.Helper()
+	list, err := svr.selectedValidatorRepo.GetSelectedValidatorListByDenom(denom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vals := make([]string, 0, len(list))
+	for _, v := range list {
+		vals = append(vals, v.PoolAddress+"/"+v.ValidatorAddress+"/"+v.Moniker)
+	}
+	return vals
+}
+
+func eventValidators(vals []event.SelectedValidator) []string {
+	s := make([]string, 0, len(vals))
+	for _, v := range vals {
+		s = append(s, v.PoolAddress+"/"+v.ValidatorAddress+"/"+v.Moniker)
+	}
+	sort.Strings(s)
+	return s
+}
+
+func TestReconcileSelectedValidator(t *testing.T) {
+	tests := []struct {
+		name        string
+		onChain     []string // pool/validator/moniker
+		wantLocal   []string
+		wantAdded   []string
+		wantRemoved []string
+		wantEvent   bool
+	}{
+		{
+			name:      "initial delegations are added",
+			onChain:   []string{"poolA/val1/one", "poolA/val2/two", "poolB/val1/one"},
+			wantLocal: []string{"poolA/val1/one", "poolA/val2/two", "poolB/val1/one"},
+			wantAdded: []string{"poolA/val1/one", "poolA/val2/two", "poolB/val1/one"},
+			wantEvent: true,
+		},
+		{
+			name:      "unchanged delegations publish nothing",
+			onChain:   []string{"poolA/val1/one", "poolA/val2/two", "poolB/val1/one"},
+			wantLocal: []string{"poolA/val1/one", "poolA/val2/two", "poolB/val1/one"},
+		},
+		{
+			name:      "moniker change is updated without event",
+			onChain:   []string{"poolA/val1/uno", "poolA/val2/two", "poolB/val1/uno"},
+			wantLocal: []string{"poolA/val1/uno", "poolA/val2/two", "poolB/val1/uno"},
+		},
+		{
+			name:        "redelegated validator is replaced",
+			onChain:     []string{"poolA/val1/uno", "poolA/val3/three", "poolB/val1/uno"},
+			wantLocal:   []string{"poolA/val1/uno", "poolA/val3/three", "poolB/val1/uno"},
+			wantAdded:   []string{"poolA/val3/three"},
+			wantRemoved: []string{"poolA/val2/two"},
+			wantEvent:   true,
+		},
+		{
+			name:        "unbonded pool is removed",
+			onChain:     []string{"poolA/val1/uno", "poolA/val3/three"},
+			wantLocal:   []string{"poolA/val1/uno", "poolA/val3/three"},
+			wantRemoved: []string{"poolB/val1/uno"},
+			wantEvent:   true,
+		},
+	}
+
+	svr, sub := newReconcileServer(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svr.reconcileSelectedValidator("uratom", onChain("uratom", tt.onChain...))
+			if err != nil {
+				t.Fatalf("reconcileSelectedValidator err: %s", err)
+			}
+			if got := selectedOf(t, svr, "uratom"); strings.Join(got, " ") != strings.Join(tt.wantLocal, " ") {
+				t.Errorf("selected %v, want %v", got, tt.wantLocal)
+			}
+
+			select {
+			case e := <-sub.Events():
+				if !tt.wantEvent {
+					t.Fatalf("unexpected event %+v", e)
+				}
+				data := e.Data.(event.SelectedValidatorsData)
+				if e.Type != event.TypeSelectedValidators || e.Denom != "uratom" {
+					t.Errorf("event %s of %s", e.Type, e.Denom)
+				}
+				if got := eventValidators(data.Added); strings.Join(got, " ") != strings.Join(tt.wantAdded, " ") {
+					t.Errorf("added %v, want %v", got, tt.wantAdded)
+				}
+				if got := eventValidators(data.Removed); strings.Join(got, " ") != strings.Join(tt.wantRemoved, " ") {
+					t.Errorf("removed %v, want %v", got, tt.wantRemoved)
+				}
+			default:
+				if tt.wantEvent {
+					t.Fatal("no event published")
+				}
+			}
+		})
+	}
+}
+
+func TestReconcileSelectedValidatorHistory(t *testing.T) {
+	svr, _ := newReconcileServer(t)
+	err := svr.reconcileSelectedValidator("uratom", onChain("uratom", "poolA/val1/one", "poolA/val2/two"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	selected, _ := svr.selectedValidatorRepo.GetSelectedValidatorListByDenom("uratom")
+	selectedAt := map[string]int64{}
+	for _, v := range selected {
+		selectedAt[v.ValidatorAddress] = int64(v.CreatedAt)
+	}
+
+	before := time.Now().Unix()
+	err = svr.reconcileSelectedValidator("uratom", onChain("uratom", "poolA/val3/three"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// another denom is not touched
+	err = svr.reconcileSelectedValidator("uriris", onChain("uriris"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	histories, err := svr.selectedValidatorRepo.GetSelectedValidatorHistoryList("uratom", "poolA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(histories) != 2 {
+		t.Fatalf("%d history rows, want 2", len(histories))
+	}
+	wantMonikers := map[string]string{"val1": "one", "val2": "two"}
+	for _, h := range histories {
+		if h.Moniker != wantMonikers[h.ValidatorAddress] {
+			t.Errorf("history of %s with moniker %q", h.ValidatorAddress, h.Moniker)
+		}
+		delete(wantMonikers, h.ValidatorAddress)
+		if h.RTokenDenom != "uratom" || h.PoolAddress != "poolA" {
+			t.Errorf("history of %s in %s %s", h.ValidatorAddress, h.RTokenDenom, h.PoolAddress)
+		}
+		if h.SelectedAt != selectedAt[h.ValidatorAddress] {
+			t.Errorf("selectedAt of %s %d, want %d", h.ValidatorAddress, h.SelectedAt, selectedAt[h.ValidatorAddress])
+		}
+		if h.RemovedAt < before || h.RemovedAt > time.Now().Unix() {
+			t.Errorf("removedAt of %s %d", h.ValidatorAddress, h.RemovedAt)
+		}
+	}
+	if len(wantMonikers) != 0 {
+		t.Errorf("no history rows of %v", wantMonikers)
+	}
+}
+
+// downFetcher fails every lookup like an unreachable keybase
+type downFetcher struct {
+	calls int
+}
+
+func (f *downFetcher) Get(url string) (int, []byte, error) {
+	f.calls++
+	return 0, nil, fmt.Errorf("keybase is down")
+}
+
+func TestUpdateValidatorLogoCountsFailedLookups(t *testing.T) {
+	wrapDb := newMemoryDb(t)
+	for i := 0; i < maxLogoResolvePerRound+10; i++ {
+		err := dao_election.UpOrInValidator(wrapDb, &dao_election.Validator{
+			RTokenDenom:      "uratom",
+			ValidatorAddress: fmt.Sprintf("val%d", i),
+			Identity:         "identity",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	fetcher := &downFetcher{}
+	svr := &Server{
+		cfg:                   &config.Config{RTokenInfo: []config.RTokenInfo{{Denom: "uratom"}}},
+		db:                    wrapDb,
+		selectedValidatorRepo: dao_election.NewMemorySelectedValidatorRepository(),
+		logoResolver:          logo.NewResolver(fetcher, nil, "", "https://example.com/default.png"),
+	}
+
+	for round := 1; round <= 2; round++ {
+		if err := svr.updateValidatorLogo(); err != nil {
+			t.Fatalf("updateValidatorLogo err: %s", err)
+		}
+		if fetcher.calls != round*maxLogoResolvePerRound {
+			t.Fatalf("%d lookups after round %d, want %d", fetcher.calls, round, round*maxLogoResolvePerRound)
+		}
+	}
+	logoList, err := dao_election.GetValidatorLogoListByDenom(wrapDb, "uratom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logoList) != 0 {
+		t.Errorf("%d logos saved from failed lookups", len(logoList))
+	}
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// certReloader serves the certificate loaded from certFile/keyFile and reloads it
+// when either file changes, the old certificate is kept if reloading fails.
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mutex       sync.RWMutex
+	cert        *tls.Certificate
+	certModTime time.Time
+	keyModTime  time.Time
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	_, err := r.reloadIfChanged()
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.cert, nil
+}
+
+func (r *certReloader) reloadIfChanged() (bool, error) {
+	certStat, err := os.Stat(r.certFile)
+	if err != nil {
+		return false, err
+	}
+	keyStat, err := os.Stat(r.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	r.mutex.RLock()
+	changed := r.cert == nil || !certStat.ModTime().Equal(r.certModTime) || !keyStat.ModTime().Equal(r.keyModTime)
+	r.mutex.RUnlock()
+	if !changed {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("load key pair err: %s", err)
+	}
+
+	r.mutex.Lock()
+	r.cert = &cert
+	r.certModTime = certStat.ModTime()
+	r.keyModTime = keyStat.ModTime()
+	r.mutex.Unlock()
+	return true, nil
+}
+
+// watch checks the files every interval until stop is closed
+func (r *certReloader) watch(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			reloaded, err := r.reloadIfChanged()
+			if err != nil {
+				logrus.Warnf("reload tls certificate err: %s", err)
+				continue
+			}
+			if reloaded {
+				logrus.Infof("tls certificate reloaded from %s", r.certFile)
+			}
+		}
+	}
+}
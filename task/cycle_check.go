@@ -4,13 +4,14 @@ import (
 	"fmt"
 	"time"
 
-	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	stakingTypes "github.com/cosmos/cosmos-sdk/x/staking/types"
 	"github.com/sirupsen/logrus"
 	cosmosSdkClient "github.com/stafihub/cosmos-relay-sdk/client"
 	"github.com/stafihub/rtoken-relay-core/common/core"
 	stafihubClient "github.com/stafihub/stafi-hub-relay-sdk/client"
 	stafiHubXRValidatorTypes "github.com/stafihub/stafihub/x/rvalidator/types"
+	"github.com/stafihub/staking-election/dao/election"
 	"github.com/stafihub/staking-election/utils"
 )
 
@@ -51,29 +52,21 @@ func (task *Task) CycleCheckValidatorHandler(cosmosClient *cosmosSdkClient.Clien
 }
 
 func (task *Task) CheckValidator(cosmosClient *cosmosSdkClient.Client, denom, poolAddrStr string) error {
-	cycleSecondsRes, err := task.stafihubClient.QueryCycleSeconds(denom)
-	if err != nil {
-		return err
-	}
-	shuffleSecondsRes, err := task.stafihubClient.QueryShuffleSeconds(denom)
-	if err != nil {
-		return err
-	}
-
-	cycleInfoOnChain := cycleSecondsRes.CycleSeconds
-	_, curTimestamp, err := cosmosClient.GetCurrentBLockAndTimestamp()
-	if err != nil {
-		return err
+	if task.isPoolPaused(denom, poolAddrStr) {
+		logrus.WithFields(logrus.Fields{
+			"denom":    denom,
+			"poolAddr": poolAddrStr,
+		}).Debug("election of pool is paused")
+		return nil
 	}
-	useSeconds := cycleInfoOnChain.Seconds
 
-	// cal current cycle/targetHeight/slashFromHeight
-	currentCycleNumber := uint64(curTimestamp) / useSeconds
-	targetHeight, err := cosmosClient.GetHeightByEra(uint32(currentCycleNumber), int64(useSeconds), 0)
+	cycle, err := task.currentCycle(cosmosClient, denom)
 	if err != nil {
 		return err
 	}
-	slashFromHeight := targetHeight - utils.SlashDuBlock
+	cycleInfoOnChain := cycle.cycleSeconds
+	currentCycleNumber := cycle.number
+	targetHeight := cycle.targetHeight
 
 	// get local checked cycle
 	localCheckedCycleVersion, localCheckedCycleNumber, found := task.getLocalCheckedCycle(denom, poolAddrStr)
@@ -91,256 +84,40 @@ func (task *Task) CheckValidator(cosmosClient *cosmosSdkClient.Client, denom, po
 		return nil
 	}
 
-	// return if latestVotedCycle hasn't been reported
-	latestVotedCycle, err := task.stafihubClient.QueryLatestVotedCycle(denom, poolAddrStr)
-	if err != nil {
-		return err
-	}
-	latestDealedCycle, err := task.stafihubClient.QueryLatestDealedCycle(denom, poolAddrStr)
-	if err != nil {
-		return err
-	}
-	if !(latestVotedCycle.LatestVotedCycle.Version == latestDealedCycle.LatestDealedCycle.Version &&
-		latestVotedCycle.LatestVotedCycle.Number == latestDealedCycle.LatestDealedCycle.Number) {
-		return nil
-	}
-	needShuffle := uint64(curTimestamp)-latestDealedCycle.LatestDealedCycle.Number*cycleInfoOnChain.Seconds > shuffleSecondsRes.ShuffleSeconds.Seconds
-	if shuffleSecondsRes.ShuffleSeconds.Seconds == 0 {
-		needShuffle = false
-	}
-
-	// return if rvalidators not equal to validators which were delegated on chain
-	rValidatorList, err := task.stafihubClient.QueryRValidatorList(denom, poolAddrStr)
-	if err != nil {
-		return err
-	}
-	if len(rValidatorList.RValidatorList) == 0 {
-		return fmt.Errorf("rValidatorList on chain is empty")
-	}
-	rValidatorMap := make(map[string]bool)
-	for _, rval := range rValidatorList.RValidatorList {
-		rValidatorMap[rval] = true
-	}
-
-	done := core.UseSdkConfigContext(cosmosClient.GetAccountPrefix())
-	poolAddr, err := sdk.AccAddressFromBech32(poolAddrStr)
-	if err != nil {
-		done()
-		return err
-	}
-	done()
-
-	delegationsRes, err := cosmosClient.QueryDelegations(poolAddr, targetHeight)
+	pool, err := task.poolState(cosmosClient, denom, poolAddrStr, cycle)
 	if err != nil {
 		return err
 	}
-	if len(rValidatorMap) != len(delegationsRes.DelegationResponses) {
+	if len(pool.waitReason) != 0 {
+		logrus.WithFields(logrus.Fields{
+			"denom":    denom,
+			"poolAddr": poolAddrStr,
+			"reason":   pool.waitReason,
+		}).Debug("checkValidator wait")
 		return nil
 	}
-	for _, delegation := range delegationsRes.DelegationResponses {
-		if !rValidatorMap[delegation.Delegation.ValidatorAddress] {
-			return nil
-		}
-	}
+	needShuffle := pool.needShuffle
+	rValidatorList := pool.rValidatorList
 
 	// ---------------- check rvalidator ------------
-	// 1. collect all rValidators need rm
-	needRmValidators := make([]string, 0)
-	for _, validatorStr := range rValidatorList.RValidatorList {
-		done := core.UseSdkConfigContext(cosmosClient.GetAccountPrefix())
-		validatorAddr, err := sdk.ValAddressFromBech32(validatorStr)
-		if err != nil {
-			done()
-			return err
-		}
-		done()
-
-		// (0). rm if it has slash
-		slashRes, err := cosmosClient.QueryValidatorSlashes(validatorAddr, slashFromHeight, targetHeight)
-		if err != nil {
-			return err
-		}
-		logrus.WithFields(logrus.Fields{
-			"currentCycleNumber": currentCycleNumber,
-			"valAddr":            validatorStr,
-			"slashAmount":        slashRes.Pagination.Total,
-			"fromHeight":         slashFromHeight,
-			"targetHeight":       targetHeight,
-		}).Debug("validatorSlashInfo")
-
-		if slashRes.Pagination.Total > utils.MaxSlashAmount {
-			needRmValidators = append(needRmValidators, validatorStr)
-			continue
-		}
-
-		// (1). rm if it's commssion is too big
-		validatorRes, err := cosmosClient.QueryValidator(validatorStr, targetHeight)
-		if err != nil {
-			return err
-		}
-		rtokenInfo, exist := task.rTokenInfoMap[denom]
-		if !exist {
-			return fmt.Errorf("rtoken info of denom %s not exist", denom)
-		}
-		if validatorRes.Validator.Commission.Rate.GT(rtokenInfo.MaxCommission.Dec) {
-			needRmValidators = append(needRmValidators, validatorStr)
-			continue
-		}
-
-		// (2). rm if it missed blocks excessively
-		done = core.UseSdkConfigContext(cosmosClient.GetAccountPrefix())
-		consPubkeyJson, err := cosmosClient.Ctx().Codec.MarshalJSON(validatorRes.Validator.ConsensusPubkey)
-		if err != nil {
-			done()
-			return err
-		}
-		var pk cryptotypes.PubKey
-		if err := cosmosClient.Ctx().Codec.UnmarshalInterfaceJSON(consPubkeyJson, &pk); err != nil {
-			done()
-			return err
-		}
-		consAddr := sdk.ConsAddress(pk.Address())
-		consAddrStr := consAddr.String()
-		done()
-
-		signInfo, err := cosmosClient.QuerySigningInfo(consAddrStr, targetHeight)
-		if err != nil {
-			return err
-		}
-
-		if signInfo.ValSigningInfo.MissedBlocksCounter > rtokenInfo.MaxMissedBlocks {
-			needRmValidators = append(needRmValidators, validatorStr)
-			continue
-		}
-
-	}
-	// 2. check if it is removeable(transitive redelegate is not permitted ( a -> b, b -> c ))
-	redelegations, err := cosmosClient.QueryAllRedelegations(poolAddrStr, targetHeight)
+	election, err := task.Elect(cosmosClient, denom, poolAddrStr, targetHeight, needShuffle, rValidatorList)
 	if err != nil {
 		return err
 	}
-	hasToRedelegation := make(map[string]bool)
-	for _, redelegation := range redelegations.RedelegationResponses {
-		hasToRedelegation[redelegation.Redelegation.ValidatorDstAddress] = true
-	}
-
-	filteredNeedRmVal := make([]string, 0)
-	for _, needRmVal := range needRmValidators {
-		if !hasToRedelegation[needRmVal] {
-			filteredNeedRmVal = append(filteredNeedRmVal, needRmVal)
-		}
-	}
-	logrus.WithFields(logrus.Fields{
-		"cycleVersion:":      cycleInfoOnChain.Version,
-		"currentCycleNumber": currentCycleNumber,
-		"denom":              denom,
-		"poolAddr":           poolAddrStr,
-		"filteredNeedRmVal":  filteredNeedRmVal,
-	}).Debug("filteredNeedRmVal")
+	task.saveElection(denom, poolAddrStr, cycleInfoOnChain.Version, currentCycleNumber, election)
 
-	filteredCanShuffleVal := make([]string, 0)
-	if needShuffle {
-		for _, mayBeCanShuffleVal := range rValidatorList.RValidatorList {
-			if !hasToRedelegation[mayBeCanShuffleVal] {
-				filteredCanShuffleVal = append(filteredCanShuffleVal, mayBeCanShuffleVal)
-			}
-		}
-	}
-	logrus.WithFields(logrus.Fields{
-		"cycleVersion:":         cycleInfoOnChain.Version,
-		"currentCycleNumber":    currentCycleNumber,
-		"denom":                 denom,
-		"poolAddr":              poolAddrStr,
-		"filteredCanShuffleVal": filteredCanShuffleVal,
-	}).Debug("filteredCanShuffleVal")
-
-	needRmOrShuffleValidators := filteredNeedRmVal
-	if len(needRmOrShuffleValidators) == 0 {
-		needRmOrShuffleValidators = filteredCanShuffleVal
-	}
-	if len(needRmOrShuffleValidators) == 0 {
+	switch election.Decision {
+	case dao_election.DecisionKeep:
 		logrus.Debug("needRmOrShuffleValidators is empty, no need redelegate")
 		task.setLocalCheckedCycle(denom, poolAddrStr, cycleInfoOnChain.Version, currentCycleNumber)
 		return nil
-	}
-
-	// 3. select highquality validators from original chain, number = 2 * len(rValidatorList)
-	selectedValidator, err := utils.GetSelectedValidator(cosmosClient, targetHeight, int64(len(rValidatorList.RValidatorList)*3), nil)
-	if err != nil {
-		return err
-	}
-
-	willUseValidator := make([]string, 0)
-	for _, val := range selectedValidator {
-
-		done := core.UseSdkConfigContext(cosmosClient.GetAccountPrefix())
-		valAddress, err := sdk.ValAddressFromBech32(val.OperatorAddress)
-		if err != nil {
-			return err
-		}
-		done()
-
-		// (0). should skip slashed validators
-		slashRes, err := cosmosClient.QueryValidatorSlashes(valAddress, slashFromHeight, targetHeight)
-		if err != nil {
-			return err
-		}
-		if slashRes.Pagination.Total > utils.MaxSlashAmount {
-			continue
-		}
-
-		// (1). should skip existed validator
-		if rValidatorMap[val.OperatorAddress] {
-			continue
-		}
-
-		// (2). should skip missed blocks excessively validator
-		validatorRes, err := cosmosClient.QueryValidator(val.OperatorAddress, targetHeight)
-		if err != nil {
-			return err
-		}
-		rtokenInfo, exist := task.rTokenInfoMap[denom]
-		if !exist {
-			return fmt.Errorf("rtoken info of denom %s not exist", denom)
-		}
-
-		done = core.UseSdkConfigContext(cosmosClient.GetAccountPrefix())
-		consPubkeyJson, err := cosmosClient.Ctx().Codec.MarshalJSON(validatorRes.Validator.ConsensusPubkey)
-		if err != nil {
-			done()
-			return err
-		}
-		var pk cryptotypes.PubKey
-		if err := cosmosClient.Ctx().Codec.UnmarshalInterfaceJSON(consPubkeyJson, &pk); err != nil {
-			done()
-			return err
-		}
-		consAddr := sdk.ConsAddress(pk.Address())
-		consAddrStr := consAddr.String()
-		done()
-
-		signInfo, err := cosmosClient.QuerySigningInfo(consAddrStr, targetHeight)
-		if err != nil {
-			return err
-		}
-		if validatorRes.Validator.Jailed || signInfo.ValSigningInfo.Tombstoned || signInfo.ValSigningInfo.MissedBlocksCounter > rtokenInfo.MaxMissedBlocks {
-			continue
-		}
-
-		// append passed validator
-		willUseValidator = append(willUseValidator, val.OperatorAddress)
-		if len(willUseValidator) == len(needRmValidators) {
-			break
-		}
-	}
-
-	if len(needRmValidators) != len(willUseValidator) {
+	case dao_election.DecisionNotEnoughCandidate:
 		return fmt.Errorf("selected validator not enough to redelegate")
 	}
 
 	logrus.WithFields(logrus.Fields{
-		"oldVal":             needRmValidators[0],
-		"newVal":             willUseValidator[0],
+		"oldVal":             election.OldValidator,
+		"newVal":             election.NewValidator,
 		"cycleVersion:":      cycleInfoOnChain.Version,
 		"currentCycleNumber": currentCycleNumber,
 		"denom":              denom,
@@ -348,8 +125,8 @@ func (task *Task) CheckValidator(cosmosClient *cosmosSdkClient.Client, denom, po
 		"needShuffle":        needShuffle,
 	}).Info("will redelegate info")
 
-	// 3. we update one validator every cycle
-	done = core.UseSdkConfigContext(stafihubClient.GetAccountPrefix())
+	// submit proposal to update rvalidator
+	done := core.UseSdkConfigContext(stafihubClient.GetAccountPrefix())
 	fromAddress := task.stafihubClient.GetFromAddress().String()
 	done()
 
@@ -357,8 +134,8 @@ func (task *Task) CheckValidator(cosmosClient *cosmosSdkClient.Client, denom, po
 		fromAddress,
 		denom,
 		poolAddrStr,
-		needRmValidators[0],
-		willUseValidator[0],
+		election.OldValidator,
+		election.NewValidator,
 		&stafiHubXRValidatorTypes.Cycle{
 			Denom:   denom,
 			Version: cycleInfoOnChain.Version,
@@ -373,3 +150,123 @@ func (task *Task) CheckValidator(cosmosClient *cosmosSdkClient.Client, denom, po
 
 	return nil
 }
+
+const (
+	waitReasonNotDealed          = "latest voted cycle is not dealed yet"
+	waitReasonDelegationMismatch = "rValidator list differs from delegations of pool"
+)
+
+// cycleState is the cycle of denom at current block time of its chain
+type cycleState struct {
+	cycleSeconds   *stafiHubXRValidatorTypes.CycleSeconds
+	shuffleSeconds uint64 // 0 means no shuffle
+	timestamp      int64  // current block time
+	number         uint64
+	targetHeight   int64 // height of the cycle start, which election is based on
+}
+
+func (task *Task) currentCycle(cosmosClient *cosmosSdkClient.Client, denom string) (*cycleState, error) {
+	cycleSecondsRes, err := task.stafihubClient.QueryCycleSeconds(denom)
+	if err != nil {
+		return nil, err
+	}
+	shuffleSecondsRes, err := task.stafihubClient.QueryShuffleSeconds(denom)
+	if err != nil {
+		return nil, err
+	}
+
+	_, curTimestamp, err := cosmosClient.GetCurrentBLockAndTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	useSeconds := cycleSecondsRes.CycleSeconds.Seconds
+
+	// cal current cycle/targetHeight
+	currentCycleNumber := uint64(curTimestamp) / useSeconds
+	targetHeight, err := cosmosClient.GetHeightByEra(uint32(currentCycleNumber), int64(useSeconds), 0)
+	if err != nil {
+		return nil, err
+	}
+	return &cycleState{
+		cycleSeconds:   cycleSecondsRes.CycleSeconds,
+		shuffleSeconds: shuffleSecondsRes.ShuffleSeconds.Seconds,
+		timestamp:      curTimestamp,
+		number:         currentCycleNumber,
+		targetHeight:   targetHeight,
+	}, nil
+}
+
+// poolStateInfo is what decides whether a pool can be elected in a cycle
+type poolStateInfo struct {
+	latestVotedCycle  stafiHubXRValidatorTypes.Cycle
+	latestDealedCycle stafiHubXRValidatorTypes.Cycle
+	needShuffle       bool
+	rValidatorList    []string
+	delegations       stakingTypes.DelegationResponses // on target height
+	waitReason        string                           // pool can't be elected in this cycle if it's set
+}
+
+func (task *Task) poolState(cosmosClient *cosmosSdkClient.Client, denom, poolAddrStr string, cycle *cycleState) (*poolStateInfo, error) {
+	state := &poolStateInfo{}
+
+	// wait if latestVotedCycle hasn't been dealed
+	latestVotedCycle, err := task.stafihubClient.QueryLatestVotedCycle(denom, poolAddrStr)
+	if err != nil {
+		return nil, err
+	}
+	latestDealedCycle, err := task.stafihubClient.QueryLatestDealedCycle(denom, poolAddrStr)
+	if err != nil {
+		return nil, err
+	}
+	state.latestVotedCycle = *latestVotedCycle.LatestVotedCycle
+	state.latestDealedCycle = *latestDealedCycle.LatestDealedCycle
+	if !(state.latestVotedCycle.Version == state.latestDealedCycle.Version &&
+		state.latestVotedCycle.Number == state.latestDealedCycle.Number) {
+		state.waitReason = waitReasonNotDealed
+	}
+	state.needShuffle = cycle.shuffleSeconds != 0 &&
+		uint64(cycle.timestamp)-state.latestDealedCycle.Number*cycle.cycleSeconds.Seconds > cycle.shuffleSeconds
+
+	// wait if rvalidators not equal to validators which were delegated on chain
+	rValidatorList, err := task.stafihubClient.QueryRValidatorList(denom, poolAddrStr)
+	if err != nil {
+		return nil, err
+	}
+	// it's checked only when the pool would be elected, as CheckValidator should wait instead of retry
+	if len(rValidatorList.RValidatorList) == 0 && len(state.waitReason) == 0 {
+		return nil, fmt.Errorf("rValidatorList on chain is empty")
+	}
+	state.rValidatorList = rValidatorList.RValidatorList
+	rValidatorMap := make(map[string]bool)
+	for _, rval := range rValidatorList.RValidatorList {
+		rValidatorMap[rval] = true
+	}
+
+	done := core.UseSdkConfigContext(cosmosClient.GetAccountPrefix())
+	poolAddr, err := sdk.AccAddressFromBech32(poolAddrStr)
+	if err != nil {
+		done()
+		return nil, err
+	}
+	done()
+
+	delegationsRes, err := cosmosClient.QueryDelegations(poolAddr, cycle.targetHeight)
+	if err != nil {
+		return nil, err
+	}
+	state.delegations = delegationsRes.DelegationResponses
+	if len(state.waitReason) != 0 {
+		return state, nil
+	}
+	if len(rValidatorMap) != len(delegationsRes.DelegationResponses) {
+		state.waitReason = waitReasonDelegationMismatch
+		return state, nil
+	}
+	for _, delegation := range delegationsRes.DelegationResponses {
+		if !rValidatorMap[delegation.Delegation.ValidatorAddress] {
+			state.waitReason = waitReasonDelegationMismatch
+			return state, nil
+		}
+	}
+	return state, nil
+}
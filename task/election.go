@@ -0,0 +1,425 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/sirupsen/logrus"
+	cosmosSdkClient "github.com/stafihub/cosmos-relay-sdk/client"
+	"github.com/stafihub/rtoken-relay-core/common/core"
+	"github.com/stafihub/staking-election/config"
+	"github.com/stafihub/staking-election/dao/election"
+	"github.com/stafihub/staking-election/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	ruleMaxSlashAmount         = "maxSlashAmount"
+	ruleMaxCommission          = "maxCommission"
+	ruleMaxMissedBlocks        = "maxMissedBlocks"
+	ruleNotJailed              = "notJailed"
+	ruleNotTombstoned          = "notTombstoned"
+	ruleNotRValidator          = "notRValidator"
+	ruleNoIncomingRedelegation = "noIncomingRedelegation"
+	ruleNotDenied              = "notDenied"
+	ruleAllowed                = "allowed"
+)
+
+// electionChain is the chain state Elect reads, it can be replaced in tests
+type electionChain interface {
+	// incomingRedelegations returns validators with redelegation to them from pool
+	incomingRedelegations(poolAddrStr string, height int64) (map[string]bool, error)
+	evaluateValidator(validatorStr string, slashFromHeight, targetHeight int64) (dao_election.ValidatorEvaluation, error)
+	// selectedValidators returns number candidates ordered by annual rate
+	selectedValidators(height, number int64, sampling utils.Sampling) ([]*utils.Validator, error)
+}
+
+// cosmosChain reads election state of a rToken chain
+type cosmosChain struct {
+	client *cosmosSdkClient.Client
+}
+
+// Elect evaluates rValidators of pool on targetHeight and decides which one should be
+// replaced by which candidate, it only reads chain state and the allow/deny lists in db,
+// so it is safe to call at any time.
+func (task *Task) Elect(cosmosClient *cosmosSdkClient.Client, denom, poolAddrStr string, targetHeight int64,
+	needShuffle bool, rValidatorList []string) (*dao_election.ElectionDetail, error) {
+	return task.elect(cosmosChain{client: cosmosClient}, denom, poolAddrStr, targetHeight, needShuffle, rValidatorList)
+}
+
+func (task *Task) elect(chain electionChain, denom, poolAddrStr string, targetHeight int64,
+	needShuffle bool, rValidatorList []string) (*dao_election.ElectionDetail, error) {
+
+	rtokenInfo, exist := task.rTokenInfoMap[denom]
+	if !exist {
+		return nil, fmt.Errorf("rtoken info of denom %s not exist", denom)
+	}
+	slashFromHeight := targetHeight - *rtokenInfo.SlashDuBlock
+
+	detail := &dao_election.ElectionDetail{
+		NeedShuffle:     needShuffle,
+		TargetHeight:    targetHeight,
+		SlashFromHeight: slashFromHeight,
+		RValidators:     make([]dao_election.ValidatorEvaluation, 0),
+		Candidates:      make([]dao_election.ValidatorEvaluation, 0),
+	}
+
+	rValidatorMap := make(map[string]bool)
+	for _, rval := range rValidatorList {
+		rValidatorMap[rval] = true
+	}
+
+	// check if it is removeable(transitive redelegate is not permitted ( a -> b, b -> c ))
+	hasToRedelegation, err := chain.incomingRedelegations(poolAddrStr, targetHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	validatorList, err := task.getValidatorList(denom)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. collect all rValidators need rm: slashed, commission too big or missed blocks excessively,
+	// the ones with incoming redelegation are filtered out only when deciding whether to keep
+	needRmValidators := make([]string, 0)
+	filteredNeedRmValidators := make([]string, 0)
+	canShuffleValidators := make([]string, 0)
+	for _, validatorStr := range rValidatorList {
+		evaluation, err := chain.evaluateValidator(validatorStr, slashFromHeight, targetHeight)
+		if err != nil {
+			return nil, err
+		}
+		// rules deciding whether to rm, the incoming redelegation one is informational
+		rmRules := []dao_election.RuleOutcome{
+			slashRule(evaluation, rtokenInfo),
+			commissionRule(evaluation, rtokenInfo),
+			missedBlocksRule(evaluation, rtokenInfo),
+			validatorList.deniedRule(validatorStr),
+		}
+		evaluation.Passed = allPassed(rmRules)
+		evaluation.Rules = append(rmRules, dao_election.RuleOutcome{
+			Rule:   ruleNoIncomingRedelegation,
+			Passed: !hasToRedelegation[validatorStr],
+			Detail: "rm or shuffle is not permitted while it has incoming redelegation",
+		})
+		detail.RValidators = append(detail.RValidators, evaluation)
+
+		if !evaluation.Passed {
+			needRmValidators = append(needRmValidators, validatorStr)
+		}
+		if hasToRedelegation[validatorStr] {
+			continue
+		}
+		if !evaluation.Passed {
+			filteredNeedRmValidators = append(filteredNeedRmValidators, validatorStr)
+		}
+		if needShuffle {
+			canShuffleValidators = append(canShuffleValidators, validatorStr)
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"denom":                denom,
+		"poolAddr":             poolAddrStr,
+		"needRmValidators":     needRmValidators,
+		"filteredNeedRmVal":    filteredNeedRmValidators,
+		"canShuffleValidators": canShuffleValidators,
+	}).Debug("rValidators evaluated")
+
+	needRmOrShuffleValidators := filteredNeedRmValidators
+	if len(needRmOrShuffleValidators) == 0 {
+		needRmOrShuffleValidators = canShuffleValidators
+	}
+	if len(needRmOrShuffleValidators) == 0 {
+		detail.Decision = dao_election.DecisionKeep
+		return detail, nil
+	}
+
+	// 2. select highquality validators from original chain, number = 3 * len(rValidatorList)
+	selectedValidator, err := chain.selectedValidators(targetHeight, int64(len(rValidatorList)*3), rtokenInfo.Sampling())
+	if err != nil {
+		return nil, err
+	}
+
+	willUseValidator := make([]string, 0)
+	for i, val := range selectedValidator {
+		// (0). should skip existed validator
+		if rValidatorMap[val.OperatorAddress] {
+			detail.Candidates = append(detail.Candidates, dao_election.ValidatorEvaluation{
+				ValidatorAddress: val.OperatorAddress,
+				Rank:             i + 1,
+				AnnualRate:       val.AnnualRate.String(),
+				TokenAmount:      val.TokenAmount.String(),
+				Commission:       val.Commission.String(),
+				Rules: []dao_election.RuleOutcome{{
+					Rule:   ruleNotRValidator,
+					Passed: false,
+					Detail: "already a rValidator of this pool",
+				}},
+			})
+			continue
+		}
+
+		// (1). should skip slashed, jailed, tombstoned or missed blocks excessively validator
+		evaluation, err := chain.evaluateValidator(val.OperatorAddress, slashFromHeight, targetHeight)
+		if err != nil {
+			return nil, err
+		}
+		evaluation.Rank = i + 1
+		evaluation.AnnualRate = val.AnnualRate.String()
+		evaluation.TokenAmount = val.TokenAmount.String()
+		evaluation.Rules = append(EligibilityRules(evaluation, rtokenInfo),
+			validatorList.deniedRule(val.OperatorAddress), validatorList.allowedRule(val.OperatorAddress))
+		evaluation.Passed = allPassed(evaluation.Rules)
+
+		// append passed validator
+		if evaluation.Passed {
+			evaluation.Selected = true
+			willUseValidator = append(willUseValidator, val.OperatorAddress)
+		}
+		detail.Candidates = append(detail.Candidates, evaluation)
+		// candidates are counted against rm validators only, so shuffle alone never redelegates
+		if len(needRmValidators) != 0 && len(willUseValidator) == len(needRmValidators) {
+			break
+		}
+	}
+
+	if len(needRmValidators) == 0 || len(needRmValidators) != len(willUseValidator) {
+		detail.Decision = dao_election.DecisionNotEnoughCandidate
+		return detail, nil
+	}
+
+	// 3. we update one validator every cycle
+	detail.Decision = dao_election.DecisionRedelegate
+	detail.OldValidator = needRmValidators[0]
+	detail.NewValidator = willUseValidator[0]
+	for i := range detail.RValidators {
+		if detail.RValidators[i].ValidatorAddress == detail.OldValidator {
+			detail.RValidators[i].Selected = true
+		}
+	}
+	return detail, nil
+}
+
+// EligibilityRules are the rules a validator must pass to be elected as a rValidator
+func EligibilityRules(evaluation dao_election.ValidatorEvaluation, rtokenInfo config.RTokenInfo) []dao_election.RuleOutcome {
+	return []dao_election.RuleOutcome{
+		slashRule(evaluation, rtokenInfo),
+		{
+			Rule:   ruleNotJailed,
+			Passed: !evaluation.Jailed,
+			Detail: fmt.Sprintf("jailed: %t", evaluation.Jailed),
+		},
+		{
+			Rule:   ruleNotTombstoned,
+			Passed: !evaluation.Tombstoned,
+			Detail: fmt.Sprintf("tombstoned: %t", evaluation.Tombstoned),
+		},
+		missedBlocksRule(evaluation, rtokenInfo),
+	}
+}
+
+// ReportRules are EligibilityRules plus the commission rule, which removes rValidators, so
+// a validator passing them is elected and then kept, allow/deny lists aside
+func ReportRules(evaluation dao_election.ValidatorEvaluation, rtokenInfo config.RTokenInfo) []dao_election.RuleOutcome {
+	return append(EligibilityRules(evaluation, rtokenInfo), commissionRule(evaluation, rtokenInfo))
+}
+
+func slashRule(evaluation dao_election.ValidatorEvaluation, rtokenInfo config.RTokenInfo) dao_election.RuleOutcome {
+	return dao_election.RuleOutcome{
+		Rule:   ruleMaxSlashAmount,
+		Passed: evaluation.SlashAmount <= *rtokenInfo.MaxSlashAmount,
+		Detail: fmt.Sprintf("slash amount %d, max %d", evaluation.SlashAmount, *rtokenInfo.MaxSlashAmount),
+	}
+}
+
+func commissionRule(evaluation dao_election.ValidatorEvaluation, rtokenInfo config.RTokenInfo) dao_election.RuleOutcome {
+	commission, err := sdk.NewDecFromStr(evaluation.Commission)
+	return dao_election.RuleOutcome{
+		Rule:   ruleMaxCommission,
+		Passed: err == nil && !commission.GT(rtokenInfo.MaxCommission.Dec),
+		Detail: fmt.Sprintf("commission %s, max %s", evaluation.Commission, rtokenInfo.MaxCommission.Dec),
+	}
+}
+
+func missedBlocksRule(evaluation dao_election.ValidatorEvaluation, rtokenInfo config.RTokenInfo) dao_election.RuleOutcome {
+	return dao_election.RuleOutcome{
+		Rule:   ruleMaxMissedBlocks,
+		Passed: evaluation.MissedBlocks <= *rtokenInfo.MaxMissedBlocks,
+		Detail: fmt.Sprintf("missed blocks %d, max %d", evaluation.MissedBlocks, *rtokenInfo.MaxMissedBlocks),
+	}
+}
+
+// validatorList is the allow/deny list of a denom maintained through the admin api
+type validatorList struct {
+	allow map[string]bool
+	deny  map[string]bool
+}
+
+func (l validatorList) deniedRule(validatorStr string) dao_election.RuleOutcome {
+	return dao_election.RuleOutcome{
+		Rule:   ruleNotDenied,
+		Passed: !l.deny[validatorStr],
+		Detail: fmt.Sprintf("in deny list: %t", l.deny[validatorStr]),
+	}
+}
+
+func (l validatorList) allowedRule(validatorStr string) dao_election.RuleOutcome {
+	return dao_election.RuleOutcome{
+		Rule:   ruleAllowed,
+		Passed: len(l.allow) == 0 || l.allow[validatorStr],
+		Detail: fmt.Sprintf("allow list size %d, in allow list: %t", len(l.allow), l.allow[validatorStr]),
+	}
+}
+
+// getValidatorList loads allow/deny list of denom, lists are empty if db is not configured
+func (task *Task) getValidatorList(denom string) (validatorList, error) {
+	list := validatorList{
+		allow: make(map[string]bool),
+		deny:  make(map[string]bool),
+	}
+	if task.db == nil {
+		return list, nil
+	}
+	entries, err := dao_election.GetValidatorListEntriesByDenom(task.db, denom)
+	if err != nil {
+		return list, err
+	}
+	for _, entry := range entries {
+		switch entry.ListType {
+		case dao_election.ListTypeAllow:
+			list.allow[entry.ValidatorAddress] = true
+		case dao_election.ListTypeDeny:
+			list.deny[entry.ValidatorAddress] = true
+		}
+	}
+	return list, nil
+}
+
+// isPoolPaused reports whether election of pool was paused through the admin api, db errors
+// are only logged and the pool is taken as not paused, so a flaky db never stops the election
+func (task *Task) isPoolPaused(denom, poolAddrStr string) bool {
+	if task.db == nil {
+		return false
+	}
+	_, err := dao_election.GetPausedPool(task.db, denom, poolAddrStr)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			logrus.WithFields(logrus.Fields{
+				"denom":    denom,
+				"poolAddr": poolAddrStr,
+			}).Warnf("dao_election.GetPausedPool err: %s", err)
+		}
+		return false
+	}
+	return true
+}
+
+func allPassed(rules []dao_election.RuleOutcome) bool {
+	for _, rule := range rules {
+		if !rule.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+func (c cosmosChain) incomingRedelegations(poolAddrStr string, height int64) (map[string]bool, error) {
+	redelegations, err := c.client.QueryAllRedelegations(poolAddrStr, height)
+	if err != nil {
+		return nil, err
+	}
+	hasToRedelegation := make(map[string]bool)
+	for _, redelegation := range redelegations.RedelegationResponses {
+		hasToRedelegation[redelegation.Redelegation.ValidatorDstAddress] = true
+	}
+	return hasToRedelegation, nil
+}
+
+func (c cosmosChain) selectedValidators(height, number int64, sampling utils.Sampling) ([]*utils.Validator, error) {
+	valMap, err := utils.GetValidatorAnnualRate(c.client, height, sampling)
+	if err != nil {
+		return nil, err
+	}
+	return utils.GetSelectedValidator(c.client, height, number, valMap)
+}
+
+// evaluateValidator collects the metrics of validator which rules are applied to
+func (c cosmosChain) evaluateValidator(validatorStr string, slashFromHeight, targetHeight int64) (dao_election.ValidatorEvaluation, error) {
+
+	done := core.UseSdkConfigContext(c.client.GetAccountPrefix())
+	validatorAddr, err := sdk.ValAddressFromBech32(validatorStr)
+	if err != nil {
+		done()
+		return dao_election.ValidatorEvaluation{}, err
+	}
+	done()
+
+	slashRes, err := c.client.QueryValidatorSlashes(validatorAddr, slashFromHeight, targetHeight)
+	if err != nil {
+		return dao_election.ValidatorEvaluation{}, err
+	}
+	validatorRes, err := c.client.QueryValidator(validatorStr, targetHeight)
+	if err != nil {
+		return dao_election.ValidatorEvaluation{}, err
+	}
+	consAddrStr, err := utils.GetValidatorConsAddress(c.client, validatorRes.Validator)
+	if err != nil {
+		return dao_election.ValidatorEvaluation{}, err
+	}
+	signInfo, err := c.client.QuerySigningInfo(consAddrStr, targetHeight)
+	if err != nil {
+		return dao_election.ValidatorEvaluation{}, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"valAddr":      validatorStr,
+		"slashAmount":  slashRes.Pagination.Total,
+		"fromHeight":   slashFromHeight,
+		"targetHeight": targetHeight,
+		"missedBlocks": signInfo.ValSigningInfo.MissedBlocksCounter,
+	}).Debug("validatorInfo")
+
+	return dao_election.ValidatorEvaluation{
+		ValidatorAddress: validatorStr,
+		Commission:       validatorRes.Validator.Commission.Rate.String(),
+		SlashAmount:      slashRes.Pagination.Total,
+		MissedBlocks:     signInfo.ValSigningInfo.MissedBlocksCounter,
+		Jailed:           validatorRes.Validator.Jailed,
+		Tombstoned:       signInfo.ValSigningInfo.Tombstoned,
+	}, nil
+}
+
+// saveElection records election detail of cycle if db is configured, failure is only logged
+// as it shouldn't block the election
+func (task *Task) saveElection(denom, poolAddrStr string, cycleVersion, cycleNumber uint64, detail *dao_election.ElectionDetail) {
+	if task.db == nil {
+		return
+	}
+
+	detailBts, err := json.Marshal(detail)
+	if err != nil {
+		logrus.Warnf("marshal election detail err: %s", err)
+		return
+	}
+	record, err := dao_election.GetElectionRecord(task.db, denom, poolAddrStr, cycleVersion, cycleNumber)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		logrus.Warnf("dao_election.GetElectionRecord err: %s", err)
+		return
+	}
+	record.RTokenDenom = denom
+	record.PoolAddress = poolAddrStr
+	record.CycleVersion = cycleVersion
+	record.CycleNumber = cycleNumber
+	record.Decision = detail.Decision
+	record.OldValidator = detail.OldValidator
+	record.NewValidator = detail.NewValidator
+	record.Detail = string(detailBts)
+
+	err = dao_election.UpOrInElectionRecord(task.db, record)
+	if err != nil {
+		logrus.Warnf("dao_election.UpOrInElectionRecord err: %s", err)
+	}
+}
@@ -0,0 +1,262 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stafihub/staking-election/config"
+	"github.com/stafihub/staking-election/dao/election"
+	"github.com/stafihub/staking-election/dao/migrate"
+	"github.com/stafihub/staking-election/db"
+	"github.com/stafihub/staking-election/utils"
+)
+
+// fakeChain serves evaluations of validators, all rules pass for validators not in evaluations
+type fakeChain struct {
+	evaluations map[string]dao_election.ValidatorEvaluation
+	incoming    map[string]bool
+	candidates  []string // ordered by annual rate
+}
+
+func (f *fakeChain) incomingRedelegations(poolAddrStr string, height int64) (map[string]bool, error) {
+	return f.incoming, nil
+}
+
+func (f *fakeChain) evaluateValidator(validatorStr string, slashFromHeight, targetHeight int64) (dao_election.ValidatorEvaluation, error) {
+	if slashFromHeight != 900 || targetHeight != 1000 {
+		return dao_election.ValidatorEvaluation{}, fmt.Errorf("evaluated from %d to %d", slashFromHeight, targetHeight)
+	}
+	evaluation, exist := f.evaluations[validatorStr]
+	if !exist {
+		evaluation.Commission = "0.05"
+	}
+	evaluation.ValidatorAddress = validatorStr
+	return evaluation, nil
+}
+
+func (f *fakeChain) selectedValidators(height, number int64, sampling utils.Sampling) ([]*utils.Validator, error) {
+	if sampling.StepNumber != 2 || sampling.StepSize != 50 {
+		return nil, fmt.Errorf("sampling %+v", sampling)
+	}
+	selected := make([]*utils.Validator, 0)
+	for i, candidate := range f.candidates {
+		if int64(i) == number {
+			break
+		}
+		selected = append(selected, &utils.Validator{
+			OperatorAddress: candidate,
+			TokenAmount:     sdk.NewInt(100),
+			Commission:      sdk.MustNewDecFromStr("0.05"),
+			AnnualRate:      sdk.NewDec(int64(20 - i)),
+		})
+	}
+	return selected, nil
+}
+
+func newElectionTask(t *testing.T, listEntries ...dao_election.ValidatorListEntry) *Task {
+	t.Helper()
+	slashDuBlock, maxSlashAmount, maxMissedBlocks := int64(100), uint64(0), int64(10)
+	stepNumber, stepSize := int64(2), int64(50)
+	task := &Task{rTokenInfoMap: map[string]config.RTokenInfo{
+		"uratom": {Denom: "uratom", RTokenParams: config.RTokenParams{
+			MaxCommission:    &config.Dec{Dec: sdk.MustNewDecFromStr("0.1")},
+			MaxMissedBlocks:  &maxMissedBlocks,
+			SlashDuBlock:     &slashDuBlock,
+			MaxSlashAmount:   &maxSlashAmount,
+			SampleStepNumber: &stepNumber,
+			SampleStepSize:   &stepSize,
+		}},
+	}}
+	if len(listEntries) == 0 {
+		return task
+	}
+
+	task.db = newMemoryDb(t)
+	for i := range listEntries {
+		if err := dao_election.UpOrInValidatorListEntry(task.db, &listEntries[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return task
+}
+
+func newMemoryDb(t *testing.T) *db.WrapDb {
+	t.Helper()
+	wrapDb, err := db.NewDB(&db.Config{Driver: db.DriverSqlite, DBName: db.SqliteMemory})
+	if err != nil {
+		t.Fatalf("NewDB err: %s", err)
+	}
+	t.Cleanup(func() {
+		if sqlDb, err := wrapDb.DB.DB(); err == nil {
+			sqlDb.Close()
+		}
+	})
+	if err := migrate.Up(wrapDb); err != nil {
+		t.Fatalf("migrate err: %s", err)
+	}
+	return wrapDb
+}
+
+func TestElect(t *testing.T) {
+	tests := []struct {
+		name        string
+		evaluations map[string]dao_election.ValidatorEvaluation
+		incoming    map[string]bool
+		needShuffle bool
+		listEntries []dao_election.ValidatorListEntry
+		want        string // decision old->new
+		wantFailed  string // rules failed by each evaluated validator
+	}{
+		{
+			name: "keep when all rValidators pass",
+			want: "keep ->",
+		},
+		{
+			name:        "commission too high",
+			evaluations: map[string]dao_election.ValidatorEvaluation{"val2": {Commission: "0.2"}},
+			want:        "redelegate val2->cand1",
+			wantFailed:  "val2:maxCommission val1:notRValidator",
+		},
+		{
+			name: "unqualified candidates are skipped",
+			evaluations: map[string]dao_election.ValidatorEvaluation{
+				"val1":  {Commission: "0.05", SlashAmount: 1},
+				"cand1": {Commission: "0.05", Jailed: true},
+				"cand2": {Commission: "0.05", Tombstoned: true},
+			},
+			want:       "redelegate val1->cand3",
+			wantFailed: "val1:maxSlashAmount val1:notRValidator cand1:notJailed cand2:notTombstoned",
+		},
+		{
+			name:        "rValidator with incoming redelegation is kept",
+			evaluations: map[string]dao_election.ValidatorEvaluation{"val2": {Commission: "0.05", MissedBlocks: 11}},
+			incoming:    map[string]bool{"val2": true},
+			want:        "keep ->",
+			wantFailed:  "val2:maxMissedBlocks,noIncomingRedelegation",
+		},
+		{
+			name: "not enough candidates",
+			evaluations: map[string]dao_election.ValidatorEvaluation{
+				"val1":  {Commission: "0.2"},
+				"val2":  {Commission: "0.2"},
+				"cand2": {Commission: "0.05", MissedBlocks: 11},
+				"cand3": {Commission: "0.05", Jailed: true},
+			},
+			want:       "notEnoughCandidate ->",
+			wantFailed: "val1:maxCommission val2:maxCommission val1:notRValidator cand2:maxMissedBlocks cand3:notJailed",
+		},
+		{
+			name:        "shuffle alone never redelegates",
+			needShuffle: true,
+			want:        "notEnoughCandidate ->",
+			wantFailed:  "val1:notRValidator",
+		},
+		{
+			name: "allow and deny lists",
+			listEntries: []dao_election.ValidatorListEntry{
+				{RTokenDenom: "uratom", ValidatorAddress: "val1", ListType: dao_election.ListTypeDeny},
+				{RTokenDenom: "uratom", ValidatorAddress: "cand2", ListType: dao_election.ListTypeAllow},
+				{RTokenDenom: "uriris", ValidatorAddress: "cand1", ListType: dao_election.ListTypeAllow},
+			},
+			want:       "redelegate val1->cand2",
+			wantFailed: "val1:notDenied val1:notRValidator cand1:allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := newElectionTask(t, tt.listEntries...)
+			chain := &fakeChain{
+				evaluations: tt.evaluations,
+				incoming:    tt.incoming,
+				candidates:  []string{"val1", "cand1", "cand2", "cand3"},
+			}
+			detail, err := task.elect(chain, "uratom", "pool", 1000, tt.needShuffle, []string{"val1", "val2"})
+			if err != nil {
+				t.Fatalf("elect err: %s", err)
+			}
+			if got := fmt.Sprintf("%s %s->%s", detail.Decision, detail.OldValidator, detail.NewValidator); got != tt.want {
+				t.Errorf("election %s, want %s", got, tt.want)
+			}
+			if detail.TargetHeight != 1000 || detail.SlashFromHeight != 900 || detail.NeedShuffle != tt.needShuffle {
+				t.Errorf("detail %+v", detail)
+			}
+
+			failed := make([]string, 0)
+			for _, evaluation := range detail.RValidators {
+				rules := failedRules(evaluation)
+				failed = appendFailed(failed, evaluation, rules)
+				// noIncomingRedelegation doesn't decide whether a rValidator should be removed
+				removable := len(rules) != 0 && !(len(rules) == 1 && rules[0] == ruleNoIncomingRedelegation)
+				if evaluation.Passed == removable {
+					t.Errorf("%s passed %t with failed rules %v", evaluation.ValidatorAddress, evaluation.Passed, rules)
+				}
+				if evaluation.Selected != (evaluation.ValidatorAddress == detail.OldValidator) {
+					t.Errorf("rValidator %s selected %t", evaluation.ValidatorAddress, evaluation.Selected)
+				}
+			}
+			for _, evaluation := range detail.Candidates {
+				rules := failedRules(evaluation)
+				failed = appendFailed(failed, evaluation, rules)
+				if evaluation.Passed != (len(rules) == 0) || evaluation.Selected != evaluation.Passed {
+					t.Errorf("candidate %s passed %t selected %t with failed rules %v", evaluation.ValidatorAddress,
+						evaluation.Passed, evaluation.Selected, rules)
+				}
+			}
+			if got := strings.Join(failed, " "); got != tt.wantFailed {
+				t.Errorf("failed rules %s, want %s", got, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func failedRules(evaluation dao_election.ValidatorEvaluation) []string {
+	rules := make([]string, 0)
+	for _, rule := range evaluation.Rules {
+		if !rule.Passed {
+			rules = append(rules, rule.Rule)
+		}
+	}
+	return rules
+}
+
+func appendFailed(failed []string, evaluation dao_election.ValidatorEvaluation, rules []string) []string {
+	if len(rules) == 0 {
+		return failed
+	}
+	return append(failed, evaluation.ValidatorAddress+":"+strings.Join(rules, ","))
+}
+
+func TestElectUnknownDenom(t *testing.T) {
+	task := newElectionTask(t)
+	if _, err := task.elect(&fakeChain{}, "uriris", "pool", 1000, false, []string{"val1"}); err == nil {
+		t.Fatal("elect of unknown denom want err")
+	}
+}
+
+func TestIsPoolPaused(t *testing.T) {
+	task := &Task{}
+	if task.isPoolPaused("uratom", "poolA") {
+		t.Error("paused without db")
+	}
+
+	task.db = newMemoryDb(t)
+	if err := dao_election.UpOrInPausedPool(task.db, &dao_election.PausedPool{RTokenDenom: "uratom", PoolAddress: "poolA"}); err != nil {
+		t.Fatal(err)
+	}
+	if !task.isPoolPaused("uratom", "poolA") || task.isPoolPaused("uratom", "poolB") {
+		t.Error("paused pools mismatch")
+	}
+
+	// a failed lookup doesn't stop the election
+	sqlDb, err := task.db.DB.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDb.Close()
+	if task.isPoolPaused("uratom", "poolA") {
+		t.Error("paused on db error")
+	}
+}
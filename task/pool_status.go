@@ -0,0 +1,142 @@
+package task
+
+import (
+	"sort"
+	"time"
+
+	cosmosSdkClient "github.com/stafihub/cosmos-relay-sdk/client"
+	"github.com/stafihub/staking-election/dao/election"
+)
+
+const (
+	PoolDecisionPaused = "paused"
+	PoolDecisionWait   = "wait"
+)
+
+// PoolStatus is what CheckValidator sees of a pool now and what it would decide,
+// regardless of whether the current cycle was already checked
+type PoolStatus struct {
+	Denom                string                       `json:"denom"`
+	PoolAddress          string                       `json:"poolAddress"`
+	CycleVersion         uint64                       `json:"cycleVersion"`
+	CycleSeconds         uint64                       `json:"cycleSeconds"`
+	CurrentCycleNumber   uint64                       `json:"currentCycleNumber"`
+	TargetHeight         int64                        `json:"targetHeight"`
+	BlockTime            int64                        `json:"blockTime"` // unix seconds of current block
+	LatestVotedCycle     CycleInfo                    `json:"latestVotedCycle"`
+	LatestDealedCycle    CycleInfo                    `json:"latestDealedCycle"`
+	ShuffleSeconds       uint64                       `json:"shuffleSeconds"` // 0 means no shuffle
+	ShuffleAfter         int64                        `json:"shuffleAfter"`   // unix seconds after which rValidators are shuffled, 0 if no shuffle
+	NeedShuffle          bool                         `json:"needShuffle"`
+	Validators           []PoolValidator              `json:"validators"` // rValidators and delegated validators on target height
+	PendingRedelegations []PendingRedelegation        `json:"pendingRedelegations"`
+	Decision             string                       `json:"decision"` // paused, wait or a decision of election
+	WaitReason           string                       `json:"waitReason,omitempty"`
+	Election             *dao_election.ElectionDetail `json:"election,omitempty"`
+}
+
+type CycleInfo struct {
+	Version uint64 `json:"version"`
+	Number  uint64 `json:"number"`
+}
+
+type PoolValidator struct {
+	Address          string `json:"address"`
+	InRValidatorList bool   `json:"inRValidatorList"`
+	Delegated        bool   `json:"delegated"`
+	Amount           string `json:"amount"` // delegated amount on target height
+}
+
+type PendingRedelegation struct {
+	SrcValidator   string    `json:"srcValidator"`
+	DstValidator   string    `json:"dstValidator"`
+	Amount         string    `json:"amount"`
+	CompletionTime time.Time `json:"completionTime"`
+}
+
+// PoolStatus collects status of pool with the same queries as CheckValidator, and runs
+// the election if the pool is ready, nothing is submitted or saved
+func (task *Task) PoolStatus(cosmosClient *cosmosSdkClient.Client, denom, poolAddrStr string) (*PoolStatus, error) {
+	status := &PoolStatus{
+		Denom:                denom,
+		PoolAddress:          poolAddrStr,
+		Validators:           make([]PoolValidator, 0),
+		PendingRedelegations: make([]PendingRedelegation, 0),
+	}
+
+	cycle, err := task.currentCycle(cosmosClient, denom)
+	if err != nil {
+		return nil, err
+	}
+	status.CycleVersion = cycle.cycleSeconds.Version
+	status.CycleSeconds = cycle.cycleSeconds.Seconds
+	status.CurrentCycleNumber = cycle.number
+	status.TargetHeight = cycle.targetHeight
+	status.BlockTime = cycle.timestamp
+	status.ShuffleSeconds = cycle.shuffleSeconds
+
+	pool, err := task.poolState(cosmosClient, denom, poolAddrStr, cycle)
+	if err != nil {
+		return nil, err
+	}
+	status.LatestVotedCycle = CycleInfo{Version: pool.latestVotedCycle.Version, Number: pool.latestVotedCycle.Number}
+	status.LatestDealedCycle = CycleInfo{Version: pool.latestDealedCycle.Version, Number: pool.latestDealedCycle.Number}
+	status.NeedShuffle = pool.needShuffle
+	if cycle.shuffleSeconds != 0 {
+		status.ShuffleAfter = int64(pool.latestDealedCycle.Number*cycle.cycleSeconds.Seconds + cycle.shuffleSeconds)
+	}
+
+	validatorMap := make(map[string]*PoolValidator)
+	for _, rval := range pool.rValidatorList {
+		validatorMap[rval] = &PoolValidator{Address: rval, InRValidatorList: true}
+	}
+	for _, delegation := range pool.delegations {
+		val, exist := validatorMap[delegation.Delegation.ValidatorAddress]
+		if !exist {
+			val = &PoolValidator{Address: delegation.Delegation.ValidatorAddress}
+			validatorMap[val.Address] = val
+		}
+		val.Delegated = true
+		val.Amount = delegation.Balance.Amount.String()
+	}
+	for _, val := range validatorMap {
+		status.Validators = append(status.Validators, *val)
+	}
+	sort.Slice(status.Validators, func(i, j int) bool {
+		return status.Validators[i].Address < status.Validators[j].Address
+	})
+
+	curHeight, err := cosmosClient.GetCurrentBlockHeight()
+	if err != nil {
+		return nil, err
+	}
+	redelegations, err := cosmosClient.QueryAllRedelegations(poolAddrStr, curHeight)
+	if err != nil {
+		return nil, err
+	}
+	for _, redelegation := range redelegations.RedelegationResponses {
+		for _, entry := range redelegation.Entries {
+			status.PendingRedelegations = append(status.PendingRedelegations, PendingRedelegation{
+				SrcValidator:   redelegation.Redelegation.ValidatorSrcAddress,
+				DstValidator:   redelegation.Redelegation.ValidatorDstAddress,
+				Amount:         entry.Balance.String(),
+				CompletionTime: entry.RedelegationEntry.CompletionTime,
+			})
+		}
+	}
+
+	switch {
+	case task.isPoolPaused(denom, poolAddrStr):
+		status.Decision = PoolDecisionPaused
+	case len(pool.waitReason) != 0:
+		status.Decision = PoolDecisionWait
+		status.WaitReason = pool.waitReason
+	default:
+		status.Election, err = task.Elect(cosmosClient, denom, poolAddrStr, cycle.targetHeight, pool.needShuffle, pool.rValidatorList)
+		if err != nil {
+			return nil, err
+		}
+		status.Decision = status.Election.Decision
+	}
+	return status, nil
+}
@@ -14,6 +14,7 @@ import (
 	stafiHubXRelayersTypes "github.com/stafihub/stafihub/x/relayers/types"
 	stafiHubXRVoteTypes "github.com/stafihub/stafihub/x/rvote/types"
 	"github.com/stafihub/staking-election/config"
+	"github.com/stafihub/staking-election/db"
 	"github.com/stafihub/staking-election/utils"
 )
 
@@ -26,11 +27,12 @@ type Task struct {
 	electorAccount       string
 	stafihubEndpointList []string
 	rTokenInfoMap        map[string]config.RTokenInfo
-	localCheckedCycle    sync.Map // avoid repeated check
+	localCheckedCycle    sync.Map   // avoid repeated check
+	db                   *db.WrapDb // optional, election records are saved if it's not nil
 	stop                 chan struct{}
 }
 
-func NewTask(cfg *config.Config, stafihubClient *stafihubClient.Client) *Task {
+func NewTask(cfg *config.Config, stafihubClient *stafihubClient.Client, db *db.WrapDb) *Task {
 	rTokenInfoMap := make(map[string]config.RTokenInfo)
 	for _, rtokenInfo := range cfg.RTokenInfo {
 		rTokenInfoMap[rtokenInfo.Denom] = rtokenInfo
@@ -38,9 +40,10 @@ func NewTask(cfg *config.Config, stafihubClient *stafihubClient.Client) *Task {
 
 	s := &Task{
 		stafihubClient:       stafihubClient,
-		electorAccount:       cfg.ElectorAccount,
+		electorAccount:       cfg.Election.ElectorAccount,
 		stafihubEndpointList: cfg.StafiHubEndpointList,
 		rTokenInfoMap:        rTokenInfoMap,
+		db:                   db,
 		stop:                 make(chan struct{}),
 	}
 	return s
@@ -8,8 +8,10 @@ import (
 	"time"
 )
 
+var priceHttpClient = &http.Client{Timeout: 10 * time.Second}
+
 func GetPriceFromCoinGecko(url string) (map[string]float64, error) {
-	rsp, err := http.Get(url)
+	rsp, err := priceHttpClient.Get(url)
 	if err != nil {
 		return nil, err
 	}
@@ -38,7 +40,7 @@ func GetPriceFromCoinGecko(url string) (map[string]float64, error) {
 }
 
 func GetPriceFromCoinMarket(url string) (map[string]float64, error) {
-	rsp, err := http.Get(url)
+	rsp, err := priceHttpClient.Get(url)
 	if err != nil {
 		return nil, err
 	}
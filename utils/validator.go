@@ -5,22 +5,65 @@ import (
 	"sort"
 	"sync"
 
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	stakingTypes "github.com/cosmos/cosmos-sdk/x/staking/types"
 	"github.com/sirupsen/logrus"
 	cosmosClient "github.com/stafihub/cosmos-relay-sdk/client"
+	"github.com/stafihub/rtoken-relay-core/common/core"
 )
 
 var (
-	stepNumber, stepSize = 8, 10000
-	MaxSlashAmount       = uint64(0)
-	SlashDuBlock         = int64(10000)
-	averageBlockTime     = sdk.ZeroDec()
+	MaxSlashAmount  = uint64(0)
+	SlashDuBlock    = int64(10000)
+	DefaultSampling = Sampling{StepNumber: 8, StepSize: 10000}
 )
 
+// averageBlockTimes caches the average block time of each chain and span, it's measured
+// once as block time barely changes
+var averageBlockTimes = blockTimeCache{times: make(map[blockTimeKey]sdk.Dec)}
+
+type blockTimeKey struct {
+	chain string // account prefix, which tells chains apart as in GetSelectedValidator
+	span  int64
+}
+
+type blockTimeCache struct {
+	mutex sync.Mutex
+	times map[blockTimeKey]sdk.Dec
+}
+
+// get returns the cached time of key, it's measured if not cached, errors are not cached
+func (b *blockTimeCache) get(key blockTimeKey, measure func() (sdk.Dec, error)) (sdk.Dec, error) {
+	b.mutex.Lock()
+	blockTime, exist := b.times[key]
+	b.mutex.Unlock()
+	if exist {
+		return blockTime, nil
+	}
+
+	blockTime, err := measure()
+	if err != nil {
+		return sdk.ZeroDec(), err
+	}
+	b.mutex.Lock()
+	b.times[key] = blockTime
+	b.mutex.Unlock()
+	return blockTime, nil
+}
+
+// Sampling decides on which heights annual rates are sampled, rates of StepNumber heights
+// StepSize blocks apart are averaged
+type Sampling struct {
+	StepNumber int64
+	StepSize   int64
+}
+
+// GetAverageAnnualRate averages rates of valMap, which is sampled with DefaultSampling if it's nil
 func GetAverageAnnualRate(c *cosmosClient.Client, height int64, valMap map[string]*Validator) (sdk.Dec, error) {
 	var err error
 	if valMap == nil {
-		valMap, err = GetValidatorAnnualRate(c, height)
+		valMap, err = GetValidatorAnnualRate(c, height, DefaultSampling)
 		if err != nil {
 			return sdk.ZeroDec(), err
 		}
@@ -36,10 +79,11 @@ func GetAverageAnnualRate(c *cosmosClient.Client, height int64, valMap map[strin
 	return totalAnuualRate.Quo(sdk.NewDec(int64(initialLen))), nil
 }
 
+// GetSelectedValidator selects number validators of valMap, which is sampled with DefaultSampling if it's nil
 func GetSelectedValidator(c *cosmosClient.Client, height, number int64, valMap map[string]*Validator) ([]*Validator, error) {
 	var err error
 	if valMap == nil {
-		valMap, err = GetValidatorAnnualRate(c, height)
+		valMap, err = GetValidatorAnnualRate(c, height, DefaultSampling)
 		if err != nil {
 			return nil, err
 		}
@@ -88,11 +132,11 @@ func GetSelectedValidator(c *cosmosClient.Client, height, number int64, valMap m
 	return valSlice, nil
 }
 
-func GetValidatorAnnualRate(c *cosmosClient.Client, height int64) (map[string]*Validator, error) {
+func GetValidatorAnnualRate(c *cosmosClient.Client, height int64, sampling Sampling) (map[string]*Validator, error) {
 	rates := make([]map[string]*Validator, 0)
 
-	for i := 0; i < stepNumber; i++ {
-		valRates, err := GetValidatorAnnualRateOnHeight(c, height-int64(i*stepSize))
+	for i := int64(0); i < sampling.StepNumber; i++ {
+		valRates, err := GetValidatorAnnualRateOnHeight(c, height-i*sampling.StepSize, sampling.StepSize)
 		if err != nil {
 			return nil, err
 		}
@@ -112,13 +156,15 @@ func GetValidatorAnnualRate(c *cosmosClient.Client, height int64) (map[string]*V
 				total = total.Add(valRate.AnnualRate)
 			}
 		}
-		val.AnnualRate = total.Quo(sdk.NewDec(int64(stepNumber)))
+		val.AnnualRate = total.Quo(sdk.NewDec(sampling.StepNumber))
 		retValRates[valAddr] = val
 	}
 	return retValRates, nil
 }
 
-func GetValidatorAnnualRateOnHeight(c *cosmosClient.Client, height int64) (map[string]*Validator, error) {
+// GetValidatorAnnualRateOnHeight calculates annual rates with the average block time of
+// blockTimeSpan blocks before height
+func GetValidatorAnnualRateOnHeight(c *cosmosClient.Client, height, blockTimeSpan int64) (map[string]*Validator, error) {
 	blockResults, err := c.GetBlockResults(height)
 	if err != nil {
 		return nil, err
@@ -154,6 +200,8 @@ func GetValidatorAnnualRateOnHeight(c *cosmosClient.Client, height int64) (map[s
 		willUseVal := Validator{
 			Height:          height,
 			OperatorAddress: val.OperatorAddress,
+			Moniker:         val.GetMoniker(),
+			Identity:        val.Description.Identity,
 			TokenAmount:     val.Tokens,
 			ShareAmount:     val.DelegatorShares,
 			Commission:      val.GetCommission(),
@@ -164,12 +212,13 @@ func GetValidatorAnnualRateOnHeight(c *cosmosClient.Client, height int64) (map[s
 			commission := rewardTokenAmount.Mul(val.GetCommission())
 			sharedToken := rewardTokenAmount.Sub(commission)
 			rewardPerShare := sharedToken.Quo(willUseVal.ShareAmount)
-			if averageBlockTime.IsZero() {
-				averageBlockTime, err = GetAverageBlockTime(c, height)
-				if err != nil {
-					return nil, err
-				}
-				logrus.Debug("average block time", averageBlockTime)
+			averageBlockTime, err := averageBlockTimes.get(blockTimeKey{chain: c.GetAccountPrefix(), span: blockTimeSpan}, func() (sdk.Dec, error) {
+				blockTime, err := GetAverageBlockTime(c, height, blockTimeSpan)
+				logrus.Debug("average block time ", blockTime, " of span ", blockTimeSpan)
+				return blockTime, err
+			})
+			if err != nil {
+				return nil, err
 			}
 			annualRate := rewardPerShare.Mul(sdk.NewDec(365 * 24 * 60 * 60)).Quo(averageBlockTime)
 
@@ -182,7 +231,7 @@ func GetValidatorAnnualRateOnHeight(c *cosmosClient.Client, height int64) (map[s
 	return vals, nil
 }
 
-func GetAverageBlockTime(c *cosmosClient.Client, height int64) (sdk.Dec, error) {
+func GetAverageBlockTime(c *cosmosClient.Client, height, span int64) (sdk.Dec, error) {
 	if height <= 1 {
 		return sdk.ZeroDec(), fmt.Errorf("height must bigger than 1")
 	}
@@ -191,7 +240,7 @@ func GetAverageBlockTime(c *cosmosClient.Client, height int64) (sdk.Dec, error)
 		return sdk.ZeroDec(), err
 	}
 
-	preHeight := height - int64(stepSize)
+	preHeight := height - span
 	if preHeight <= 0 {
 		preHeight = 1
 	}
@@ -204,9 +253,27 @@ func GetAverageBlockTime(c *cosmosClient.Client, height int64) (sdk.Dec, error)
 	return sdk.NewDec(startBlock.Block.Time.Unix()).Sub(sdk.NewDec(preBlock.Block.Time.Unix())).Quo(sdk.NewDec(height - preHeight)), nil
 }
 
+// GetValidatorConsAddress returns consensus address of validator, which is used to query signing info
+func GetValidatorConsAddress(c *cosmosClient.Client, validator stakingTypes.Validator) (string, error) {
+	done := core.UseSdkConfigContext(c.GetAccountPrefix())
+	defer done()
+
+	consPubkeyJson, err := c.Ctx().Codec.MarshalJSON(validator.ConsensusPubkey)
+	if err != nil {
+		return "", err
+	}
+	var pk cryptotypes.PubKey
+	if err := c.Ctx().Codec.UnmarshalInterfaceJSON(consPubkeyJson, &pk); err != nil {
+		return "", err
+	}
+	return sdk.ConsAddress(pk.Address()).String(), nil
+}
+
 type Validator struct {
 	Height          int64
 	OperatorAddress string
+	Moniker         string
+	Identity        string
 	TokenAmount     sdk.Int
 	RewardAmount    sdk.Dec
 	ShareAmount     sdk.Dec
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestBlockTimeCache(t *testing.T) {
+	cache := blockTimeCache{times: make(map[blockTimeKey]sdk.Dec)}
+	measured := 0
+	measure := func(seconds int64) func() (sdk.Dec, error) {
+		return func() (sdk.Dec, error) {
+			measured++
+			if seconds == 0 {
+				return sdk.ZeroDec(), fmt.Errorf("query block err")
+			}
+			return sdk.NewDec(seconds), nil
+		}
+	}
+
+	tests := []struct {
+		name         string
+		key          blockTimeKey
+		seconds      int64 // measured result, 0 is an error
+		want         int64 // 0 if an error is wanted
+		wantMeasured int
+	}{
+		{"first of chain", blockTimeKey{"cosmos", 100}, 6, 6, 1},
+		{"cached", blockTimeKey{"cosmos", 100}, 7, 6, 1},
+		{"another span", blockTimeKey{"cosmos", 50}, 5, 5, 2},
+		{"another chain", blockTimeKey{"iaa", 100}, 2, 2, 3},
+		{"error", blockTimeKey{"iaa", 10}, 0, 0, 4},
+		{"error is not cached", blockTimeKey{"iaa", 10}, 3, 3, 5},
+	}
+
+	for _, tt := range tests {
+		blockTime, err := cache.get(tt.key, measure(tt.seconds))
+		if tt.want == 0 {
+			if err == nil {
+				t.Errorf("%s: want err", tt.name)
+			}
+		} else if err != nil || !blockTime.Equal(sdk.NewDec(tt.want)) {
+			t.Errorf("%s: block time %s err %v, want %d", tt.name, blockTime, err, tt.want)
+		}
+		if measured != tt.wantMeasured {
+			t.Errorf("%s: measured %d times, want %d", tt.name, measured, tt.wantMeasured)
+		}
+	}
+}